@@ -51,8 +51,13 @@ and answers. It lets the user choose one answer and evaluates the answer while s
 	for {
 		fmt.Println("\nAvailable commands:")
 		fmt.Println("1. start - Start the quiz")
-		fmt.Println("2. score - View your score and stats")
-		fmt.Println("3. exit - Quit the quiz app")
+		fmt.Println("2. resume - Log in and resume a paused quiz")
+		fmt.Println("3. daily - Log in and play today's daily challenge")
+		fmt.Println("4. study - Log in and study the questions due for review")
+		fmt.Println("5. tournament - Log in, show a tournament bracket and play your match")
+		fmt.Println("6. score - View your score and stats")
+		fmt.Println("7. report - Log in as an admin and show how the questions perform")
+		fmt.Println("8. exit - Quit the quiz app")
 		fmt.Print("\nEnter your command: ")
 		scanner.Scan()
 		input := scanner.Text()
@@ -60,8 +65,18 @@ and answers. It lets the user choose one answer and evaluates the answer while s
 		switch input {
 		case "start":
 			startQuizCLI()
+		case "resume":
+			resumeQuizCLI()
+		case "daily":
+			dailyCLI()
+		case "study":
+			studyCLI()
+		case "tournament":
+			tournamentCLI()
 		case "score":
 			viewStatsCLI()
+		case "report":
+			questionReportCLI()
 		case "exit":
 			fmt.Println("Exiting the Quiz App. Goodbye!")
 			os.Exit(0)
@@ -85,8 +100,14 @@ func startQuizCLI() {
 		return
 	}
 
+	quizID := chooseQuizCLI()
+	if !startQuizRequest(quizID) {
+		fmt.Println("Failed to start the quiz. Please try again.")
+		return
+	}
+
 	fmt.Println("Quiz started! Answer the questions as they appear.")
-	quizLoop()
+	quizLoop(nil)
 }
 
 func viewStatsCLI() {
@@ -172,13 +193,61 @@ func loginUser(username, password string) bool {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Println("Login failed.")
+		return false
+	}
+	defer resp.Body.Close()
+
+	// Accounts with two-factor authentication on answer with a challenge
+	// that a code from the authenticator app finishes
+	if resp.StatusCode == http.StatusAccepted {
+		var challenge map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&challenge); err != nil {
+			fmt.Println("Login failed.")
+			return false
+		}
+		token, _ := challenge["challenge_token"].(string)
+		return completeTwoFactorLogin(client, token)
+	}
+	if resp.StatusCode != http.StatusOK {
+		fmt.Println("Login failed.")
+		return false
+	}
+	return keepSessionCookie(resp)
+}
+
+// completeTwoFactorLogin asks for a two-factor code and sends it with the
+// login challenge.
+func completeTwoFactorLogin(client *http.Client, challengeToken string) bool {
+	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Print("Enter the code from your authenticator app or a recovery code: ")
+	scanner.Scan()
+	code := scanner.Text()
+
+	jsonData, _ := json.Marshal(map[string]string{
+		"challenge_token": challengeToken,
+		"code":            code,
+	})
+	req, err := http.NewRequest("POST", "http://localhost:8080/login/2fa", bytes.NewBuffer(jsonData))
+	if err != nil {
+		fmt.Printf("Error creating login request: %v\n", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	resp, err := client.Do(req)
 	if err != nil || resp.StatusCode != http.StatusOK {
 		fmt.Println("Login failed.")
 		return false
 	}
 	defer resp.Body.Close()
+	return keepSessionCookie(resp)
+}
 
+// keepSessionCookie stores the session cookie of a successful login.
+func keepSessionCookie(resp *http.Response) bool {
 	for _, cookie := range resp.Cookies() {
 		if cookie.Name == "quiz-session" {
 			sessionCookie = fmt.Sprintf("%s=%s", cookie.Name, cookie.Value)
@@ -195,7 +264,7 @@ func loginUser(username, password string) bool {
 	return true
 }
 
-func quizLoop() {
+func quizLoop(question map[string]interface{}) {
 	if sessionCookie == "" {
 		fmt.Println("You must log in before starting the quiz.")
 		return
@@ -203,31 +272,11 @@ func quizLoop() {
 
 	client := &http.Client{}
 	for {
-		req, err := http.NewRequest("GET", "http://localhost:8080/quiz/next", nil)
-		if err != nil {
-			fmt.Printf("Error creating next question request: %v\n", err)
-			return
-		}
-		req.Header.Set("Cookie", sessionCookie)
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			fmt.Printf("Error retrieving next question: %v\n", err)
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusGone {
-			fmt.Println("Quiz complete! View your results by using the score command.")
-			return
-		} else if resp.StatusCode != http.StatusOK {
-			fmt.Println("Error fetching question.")
-			return
-		}
-
-		var question map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&question); err != nil {
-			fmt.Printf("Error decoding question: %v\n", err)
-			return
+		if question == nil {
+			question = fetchQuestionCLI("GET", "http://localhost:8080/quiz/next")
+			if question == nil {
+				return
+			}
 		}
 
 		fmt.Printf("\nQuestion: %s\n", question["question"])
@@ -236,25 +285,45 @@ func quizLoop() {
 			fmt.Printf("%d. %s\n", i+1, option)
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
-		fmt.Print("Enter your answer: ")
-		scanner.Scan()
-		answer := scanner.Text()
+		questionID := int(question["question_id"].(float64))
 
-		answerInt, err := strconv.Atoi(answer)
-		if err != nil || answerInt < 0 || answerInt > len(options)+1 {
-			fmt.Println("Invalid answer. Please enter a valid option number.")
-			continue
+		scanner := bufio.NewScanner(os.Stdin)
+		var answerInt int
+		for {
+			fmt.Print("Enter your answer (h for a hint, f for 50/50, p to pause): ")
+			scanner.Scan()
+			answer := scanner.Text()
+
+			if answer == "p" {
+				pauseQuizCLI()
+				return
+			}
+
+			if answer == "h" || answer == "f" {
+				lifeline := "hint"
+				if answer == "f" {
+					lifeline = "fifty_fifty"
+				}
+				useLifelineCLI(questionID-1, lifeline)
+				continue
+			}
+
+			parsed, err := strconv.Atoi(answer)
+			if err != nil || parsed < 0 || parsed > len(options)+1 {
+				fmt.Println("Invalid answer. Please enter a valid option number.")
+				continue
+			}
+			answerInt = parsed
+			break
 		}
 
-		questionID := int(question["question_id"].(float64))
 		answerData := map[string]interface{}{
 			"question_index": questionID - 1,
 			"answer":         answerInt,
 		}
 		jsonData, _ := json.Marshal(answerData)
 
-		req, err = http.NewRequest("POST", "http://localhost:8080/quiz/submit", bytes.NewBuffer(jsonData))
+		req, err := http.NewRequest("POST", "http://localhost:8080/quiz/submit", bytes.NewBuffer(jsonData))
 		if err != nil {
 			fmt.Printf("Error creating submit answer request: %v\n", err)
 			return
@@ -262,18 +331,556 @@ func quizLoop() {
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Cookie", sessionCookie)
 
-		resp, err = client.Do(req)
-		if err != nil || resp.StatusCode != http.StatusOK {
+		resp, err := client.Do(req)
+		if err != nil {
 			fmt.Println("Error submitting answer.")
 			return
 		}
+		defer resp.Body.Close()
 
-		var response map[string]string
+		var response map[string]interface{}
 		err = json.NewDecoder(resp.Body).Decode(&response)
 		if err != nil {
 			fmt.Printf("Error decoding answer response: %v\n", err)
 			return
 		}
+		if resp.StatusCode != http.StatusOK {
+			fmt.Printf("Error submitting answer: %v\n", response["message"])
+			return
+		}
 		fmt.Println(response["message"])
+		if explanation, ok := response["explanation"].(string); ok {
+			fmt.Println(explanation)
+		}
+
+		// Practice quizzes let the player try the same question again
+		if response["can_retry"] == true {
+			fmt.Print("Try again? (y/n): ")
+			scanner.Scan()
+			if scanner.Text() == "y" {
+				continue
+			}
+		}
+		question = nil
+	}
+}
+
+// chooseQuizCLI lists the available quizzes and lets the player pick one.
+func chooseQuizCLI() string {
+	resp, err := http.Get("http://localhost:8080/quizzes")
+	if err != nil {
+		fmt.Printf("Error fetching quizzes: %v\n", err)
+		return ""
+	}
+	defer resp.Body.Close()
+
+	var quizzes []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&quizzes); err != nil || len(quizzes) == 0 {
+		return ""
+	}
+
+	fmt.Println("\nAvailable quizzes:")
+	for _, quiz := range quizzes {
+		fmt.Printf("- %v: %v (%v mode)\n", quiz["quiz_id"], quiz["title"], quiz["mode"])
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Print("Enter the quiz to play (leave empty for the general quiz): ")
+	scanner.Scan()
+	return scanner.Text()
+}
+
+func startQuizRequest(quizID string) bool {
+	jsonData, _ := json.Marshal(map[string]string{"quiz_id": quizID})
+	req, err := http.NewRequest("POST", "http://localhost:8080/quiz/start", bytes.NewBuffer(jsonData))
+	if err != nil {
+		fmt.Printf("Error creating start request: %v\n", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error starting quiz: %v\n", err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var response map[string]string
+		if err := json.NewDecoder(resp.Body).Decode(&response); err == nil && response["message"] != "" {
+			fmt.Println(response["message"])
+		}
+		return false
+	}
+	return true
+}
+
+// showResultsCLI prints the score of the last quiz and, for exams, the review of every answer.
+func showResultsCLI() {
+	req, err := http.NewRequest("GET", "http://localhost:8080/quiz/results", nil)
+	if err != nil {
+		fmt.Printf("Error creating results request: %v\n", err)
+		return
+	}
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error fetching results: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var results map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Println("Failed to fetch results.")
+		return
+	}
+
+	fmt.Printf("Your score: %v\n", results["score"])
+	if review, ok := results["review"].([]interface{}); ok {
+		for _, item := range review {
+			answer, _ := item.(map[string]interface{})
+			verdict := "wrong"
+			if answer["skipped"] == true {
+				verdict = "skipped"
+			} else if answer["correct"] == true {
+				verdict = "correct"
+			}
+			fmt.Printf("Question %v: %s\n", answer["question_id"], verdict)
+		}
+	}
+}
+
+// fetchQuestionCLI requests a question from the server. When none is returned
+// it prints why, together with the results once the quiz is complete.
+func fetchQuestionCLI(method, url string) map[string]interface{} {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		fmt.Printf("Error creating question request: %v\n", err)
+		return nil
+	}
+	req.Header.Set("Cookie", sessionCookie)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error retrieving question: %v\n", err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		fmt.Println("Quiz complete!")
+		showResultsCLI()
+		fmt.Println("View your stats by using the score command.")
+		return nil
+	} else if resp.StatusCode != http.StatusOK {
+		var response map[string]string
+		if err := json.NewDecoder(resp.Body).Decode(&response); err == nil && response["message"] != "" {
+			fmt.Printf("Error fetching question: %s\n", response["message"])
+		} else {
+			fmt.Println("Error fetching question.")
+		}
+		return nil
+	}
+
+	var question map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&question); err != nil {
+		fmt.Printf("Error decoding question: %v\n", err)
+		return nil
+	}
+	return question
+}
+
+// dailyCLI logs the player in and plays the daily challenge, which can be
+// played once a day.
+func dailyCLI() {
+	fmt.Println("Logging in to play the daily challenge...")
+	username, password := getUserCredentials()
+	if !loginUser(username, password) {
+		fmt.Println("Login failed. Please try again.")
+		return
+	}
+
+	req, err := http.NewRequest("GET", "http://localhost:8080/daily", nil)
+	if err != nil {
+		fmt.Printf("Error creating daily challenge request: %v\n", err)
+		return
+	}
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error fetching daily challenge: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var challenge map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&challenge); err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Println("Failed to fetch the daily challenge.")
+		return
+	}
+
+	quizID, _ := challenge["quiz_id"].(string)
+	questionIDs, _ := challenge["question_ids"].([]interface{})
+	fmt.Printf("Daily challenge of %v: %d question(s), open until %v.\n", challenge["date"], len(questionIDs), challenge["closes_at"])
+	if !startQuizRequest(quizID) {
+		fmt.Println("The daily challenge cannot be started.")
+		return
+	}
+
+	fmt.Println("Daily challenge started! Answer the questions as they appear.")
+	quizLoop(nil)
+}
+
+// tournamentCLI logs the player in, draws the bracket of a tournament as text
+// and starts the player's open match, registering them while registration is open.
+func tournamentCLI() {
+	fmt.Println("Logging in to see the tournaments...")
+	username, password := getUserCredentials()
+	if !loginUser(username, password) {
+		fmt.Println("Login failed. Please try again.")
+		return
+	}
+
+	var tournaments []map[string]interface{}
+	if !getJSONCLI("http://localhost:8080/tournaments", &tournaments) {
+		fmt.Println("Failed to fetch the tournaments.")
+		return
+	}
+	if len(tournaments) == 0 {
+		fmt.Println("There are no tournaments.")
+		return
+	}
+	fmt.Println("\nTournaments:")
+	for i, tournament := range tournaments {
+		fmt.Printf("%d. %v (%v, %v)\n", i+1, tournament["name"], tournament["format"], tournament["status"])
+	}
+	fmt.Print("Choose a tournament: ")
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	choice, err := strconv.Atoi(scanner.Text())
+	if err != nil || choice < 1 || choice > len(tournaments) {
+		fmt.Println("Invalid choice.")
+		return
+	}
+	tournament := tournaments[choice-1]
+	tournamentID, _ := tournament["tournament_id"].(string)
+
+	if tournament["status"] == "registration" {
+		req, err := http.NewRequest("POST", "http://localhost:8080/tournaments/"+tournamentID+"/register", nil)
+		if err != nil {
+			fmt.Printf("Error creating registration request: %v\n", err)
+			return
+		}
+		req.Header.Set("Cookie", sessionCookie)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fmt.Printf("Error registering: %v\n", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			fmt.Println("You are registered. The bracket is drawn when the tournament starts.")
+		} else {
+			fmt.Println("You are already registered.")
+		}
+		return
+	}
+
+	req, err := http.NewRequest("GET", "http://localhost:8080/tournaments/"+tournamentID+"/bracket", nil)
+	if err != nil {
+		fmt.Printf("Error creating bracket request: %v\n", err)
+		return
+	}
+	req.Header.Set("Cookie", sessionCookie)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error fetching bracket: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+	var bracket bytes.Buffer
+	if _, err := bracket.ReadFrom(resp.Body); err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Println("Failed to fetch the bracket.")
+		return
+	}
+	fmt.Println()
+	fmt.Print(bracket.String())
+
+	rounds, _ := tournament["bracket"].([]interface{})
+	if tournament["status"] != "running" || len(rounds) == 0 {
+		return
+	}
+	round, _ := rounds[len(rounds)-1].(map[string]interface{})
+	matches, _ := round["matches"].([]interface{})
+	for _, m := range matches {
+		match, _ := m.(map[string]interface{})
+		players, _ := match["players"].([]interface{})
+		if match["status"] != "active" || (players[0] != username && players[1] != username) {
+			continue
+		}
+		fmt.Print("\nPlay your match now? (y/n): ")
+		scanner.Scan()
+		if scanner.Text() != "y" {
+			return
+		}
+		quizID, _ := match["quiz_id"].(string)
+		if !startQuizRequest(quizID) {
+			fmt.Println("The match cannot be started, you may have played it already.")
+			return
+		}
+		fmt.Println("Match started! Answer the questions as they appear.")
+		quizLoop(nil)
+		return
+	}
+}
+
+// getJSONCLI fetches a JSON resource with the session cookie.
+func getJSONCLI(url string, target interface{}) bool {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		fmt.Printf("Error creating request: %v\n", err)
+		return false
+	}
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error sending request: %v\n", err)
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(target) == nil
+}
+
+func pauseQuizCLI() {
+	req, err := http.NewRequest("POST", "http://localhost:8080/quiz/pause", nil)
+	if err != nil {
+		fmt.Printf("Error creating pause request: %v\n", err)
+		return
+	}
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Println("Failed to pause the quiz.")
+		return
+	}
+	defer resp.Body.Close()
+
+	fmt.Println("Quiz paused. Use the resume command to continue, from here or after logging in elsewhere.")
+}
+
+func resumeQuizCLI() {
+	fmt.Println("Logging in to resume your quiz...")
+	username, password := getUserCredentials()
+	if !loginUser(username, password) {
+		fmt.Println("Login failed. Please try again.")
+		return
+	}
+
+	question := fetchQuestionCLI("POST", "http://localhost:8080/quiz/resume")
+	if question == nil {
+		return
+	}
+	fmt.Println("Quiz resumed!")
+	quizLoop(question)
+}
+
+func useLifelineCLI(questionIndex int, lifeline string) {
+	data := map[string]interface{}{
+		"question_index": questionIndex,
+		"type":           lifeline,
+	}
+	jsonData, _ := json.Marshal(data)
+
+	req, err := http.NewRequest("POST", "http://localhost:8080/quiz/lifeline", bytes.NewBuffer(jsonData))
+	if err != nil {
+		fmt.Printf("Error creating lifeline request: %v\n", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error using lifeline: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		fmt.Println("Lifeline could not be used.")
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		fmt.Printf("Lifeline could not be used: %v\n", result["message"])
+		return
+	}
+
+	if hint, ok := result["hint"].(string); ok && hint != "" {
+		fmt.Printf("Hint: %s\n", hint)
+	}
+	if removed, ok := result["removed_options"].([]interface{}); ok {
+		for _, option := range removed {
+			fmt.Printf("Option %v is wrong.\n", option)
+		}
+	}
+	fmt.Printf("Penalty: %v point(s). Lifelines of this type left: %v\n", result["penalty"], result["remaining"])
+}
+
+// studyCLI logs the player in and serves the questions due for review until
+// nothing is due or the player stops.
+func studyCLI() {
+	fmt.Println("Logging in to study...")
+	username, password := getUserCredentials()
+	if !loginUser(username, password) {
+		fmt.Println("Login failed. Please try again.")
+		return
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		req, err := http.NewRequest("GET", "http://localhost:8080/study/next", nil)
+		if err != nil {
+			fmt.Printf("Error creating study request: %v\n", err)
+			return
+		}
+		req.Header.Set("Cookie", sessionCookie)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fmt.Printf("Error fetching study question: %v\n", err)
+			return
+		}
+		var item map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&item)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			fmt.Printf("Nothing to study right now. Next review at %v.\n", item["next_due_at"])
+			return
+		}
+		if err != nil || resp.StatusCode != http.StatusOK {
+			fmt.Println("Failed to fetch the next study question.")
+			return
+		}
+
+		question, _ := item["question"].(map[string]interface{})
+		card, _ := item["card"].(map[string]interface{})
+		fmt.Printf("\nQuestion: %s\n", question["question"])
+		options, _ := question["options"].([]interface{})
+		for i, option := range options {
+			fmt.Printf("%d. %s\n", i+1, option)
+		}
+
+		var answer int
+		for {
+			fmt.Print("Enter your answer (q to stop studying): ")
+			scanner.Scan()
+			input := scanner.Text()
+			if input == "q" {
+				return
+			}
+			parsed, err := strconv.Atoi(input)
+			if err != nil || parsed < 1 || parsed > len(options) {
+				fmt.Println("Invalid answer. Please enter a valid option number.")
+				continue
+			}
+			answer = parsed
+			break
+		}
+
+		if !reviewStudyCLI(int(card["question_index"].(float64)), answer) {
+			return
+		}
+	}
+}
+
+func reviewStudyCLI(questionIndex, answer int) bool {
+	data := map[string]interface{}{
+		"question_index": questionIndex,
+		"answer":         answer,
+	}
+	jsonData, _ := json.Marshal(data)
+
+	req, err := http.NewRequest("POST", "http://localhost:8080/study/review", bytes.NewBuffer(jsonData))
+	if err != nil {
+		fmt.Printf("Error creating review request: %v\n", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error submitting study answer: %v\n", err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Println("Failed to submit the study answer.")
+		return false
+	}
+
+	if result["correct"] == true {
+		fmt.Println("Correct!")
+	} else {
+		fmt.Printf("Wrong! The correct answer was %v.\n", result["correct_answer"])
+	}
+	if explanation, ok := result["explanation"].(string); ok && explanation != "" {
+		fmt.Println(explanation)
+	}
+	if card, ok := result["card"].(map[string]interface{}); ok {
+		fmt.Printf("Next review in %v day(s).\n", card["interval_days"])
+	}
+	return true
+}
+
+// questionReportCLI prints the item analysis of every question. Only admins may see it.
+func questionReportCLI() {
+	fmt.Println("Logging in as an admin...")
+	username, password := getUserCredentials()
+	if !loginUser(username, password) {
+		fmt.Println("Login failed. Please try again.")
+		return
+	}
+
+	req, err := http.NewRequest("GET", "http://localhost:8080/admin/analytics/questions", nil)
+	if err != nil {
+		fmt.Printf("Error creating report request: %v\n", err)
+		return
+	}
+	req.Header.Set("Cookie", sessionCookie)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error fetching report: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		fmt.Println("Only admins can see the question report.")
+		return
+	}
+	var report []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Println("Failed to fetch the question report.")
+		return
+	}
+
+	fmt.Printf("\n%-4s %-9s %-7s %-7s %-14s %-8s %s\n", "ID", "Answers", "P", "Disc.", "Options", "Avg (s)", "Question")
+	for _, item := range report {
+		fmt.Printf("%-4v %-9v %-7.2f %-7.2f %-14v %-8.1f %v\n",
+			item["question_id"], item["responses"], item["p_value"], item["discrimination"],
+			item["option_counts"], item["average_answer_seconds"], item["question"])
+		if item["flagged"] == true {
+			fmt.Printf("     FLAGGED: %v\n", item["flag_reason"])
+		}
 	}
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 )
 
 type Config struct {
@@ -11,18 +12,163 @@ type Config struct {
 	ServerPort        string
 	SessionSecret     string
 	SessionKey        string
-	QuestionsFilePath string
+	SessionTTLMinutes int
+	// SessionBackend is where the sessions are kept: memory or database
+	SessionBackend        string
+	SessionCleanupMinutes int
+	// JWTKeys are the access token keys as kid:secret pairs, the first signs
+	JWTKeys              string
+	JWTAccessTTLMinutes  int
+	JWTRefreshTTLHours   int
+	QuestionsFilePath    string
+	QuizzesFilePath      string
+	AchievementsFilePath string
+
+	// MailBackend is how emails go out: file or smtp
+	MailBackend          string
+	MailFilePath         string
+	MailFrom             string
+	SMTPHost             string
+	SMTPPort             int
+	SMTPUsername         string
+	SMTPPassword         string
+	PasswordResetMinutes int
+	EmailVerifyHours     int
+	// Password reset requests per account, a zero turns the limit off
+	PasswordResetRequests      int
+	PasswordResetWindowMinutes int
+
+	// Login limits, a zero turns the limit off
+	LoginIPAttempts        int
+	LoginIPWindowSeconds   int
+	LoginUserAttempts      int
+	LoginUserWindowSeconds int
+	LoginDelayAfter        int
+	LoginDelayBaseMillis   int
+	LoginDelayMaxSeconds   int
+	LoginLockoutThreshold  int
+	LoginLockoutMinutes    int
+
+	// TOTPIssuer names the accounts in authenticator apps
+	TOTPIssuer            string
+	TOTPRequiredForAdmins bool
+
+	HintLifelines       int
+	FiftyFiftyLifelines int
+	HintPenalty         int
+	FiftyFiftyPenalty   int
+
+	QuizTimeLimitSeconds int
+	QuizPauseTimer       bool
+	QuizResumePolicy     string
+
+	LeaderboardTimezone string
+
+	XPPerAttempt       int
+	XPPerCorrectAnswer int
+	LevelBaseXP        int
+	LevelGrowth        float64
+	FreezeTokenEvery   int
+	MaxFreezeTokens    int
+
+	DailyChallengeTime      string
+	DailyChallengeQuestions int
+
+	RoomIdleMinutes int
+
+	DuelInviteHours   int
+	DuelSyncMinutes   int
+	DuelAsyncHours    int
+	DuelRatingKFactor float64
+
+	TeamTopN int
+
+	TournamentRoundHours int
+	TournamentQuestions  int
+
+	AdminUsername string
+	AdminPassword string
 }
 
 func LoadConfig() Config {
 	return Config{
-		Environment:       getEnv("ENV", "development"),
-		LogFilePath:       getEnv("LOG_FILE_PATH", "logs/app.log"),
-		APIBaseURL:        getEnv("API_BASE_URL", "http://localhost:8080"),
-		ServerPort:        getEnv("SERVER_PORT", ":8080"),
-		SessionSecret:     getEnv("SESSION_SECRET", "quiz-secret"),
-		SessionKey:        getEnv("SESSION_KEY", "quiz-session"),
-		QuestionsFilePath: getEnv("QUESTIONS_FILE_PATH", "questions.csv"),
+		Environment:           getEnv("ENV", "development"),
+		LogFilePath:           getEnv("LOG_FILE_PATH", "logs/app.log"),
+		APIBaseURL:            getEnv("API_BASE_URL", "http://localhost:8080"),
+		ServerPort:            getEnv("SERVER_PORT", ":8080"),
+		SessionSecret:         getEnv("SESSION_SECRET", "quiz-secret"),
+		SessionKey:            getEnv("SESSION_KEY", "quiz-session"),
+		SessionTTLMinutes:     getEnvInt("SESSION_TTL_MINUTES", 60),
+		SessionBackend:        getEnv("SESSION_BACKEND", "memory"),
+		SessionCleanupMinutes: getEnvInt("SESSION_CLEANUP_MINUTES", 10),
+		JWTKeys:               getEnv("JWT_KEYS", ""),
+		JWTAccessTTLMinutes:   getEnvInt("JWT_ACCESS_TTL_MINUTES", 15),
+		JWTRefreshTTLHours:    getEnvInt("JWT_REFRESH_TTL_HOURS", 720),
+		QuestionsFilePath:     getEnv("QUESTIONS_FILE_PATH", "questions.csv"),
+		QuizzesFilePath:       getEnv("QUIZZES_FILE_PATH", "quizzes.json"),
+		AchievementsFilePath:  getEnv("ACHIEVEMENTS_FILE_PATH", "achievements.json"),
+
+		MailBackend:          getEnv("MAIL_BACKEND", "file"),
+		MailFilePath:         getEnv("MAIL_FILE_PATH", "logs/mail.log"),
+		MailFrom:             getEnv("MAIL_FROM", "quiz@localhost"),
+		SMTPHost:             getEnv("SMTP_HOST", ""),
+		SMTPPort:             getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:         getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:         getEnv("SMTP_PASSWORD", ""),
+		PasswordResetMinutes: getEnvInt("PASSWORD_RESET_MINUTES", 30),
+		EmailVerifyHours:     getEnvInt("EMAIL_VERIFY_HOURS", 48),
+
+		PasswordResetRequests:      getEnvInt("PASSWORD_RESET_REQUESTS", 3),
+		PasswordResetWindowMinutes: getEnvInt("PASSWORD_RESET_WINDOW_MINUTES", 60),
+
+		LoginIPAttempts:        getEnvInt("LOGIN_IP_ATTEMPTS", 20),
+		LoginIPWindowSeconds:   getEnvInt("LOGIN_IP_WINDOW_SECONDS", 60),
+		LoginUserAttempts:      getEnvInt("LOGIN_USER_ATTEMPTS", 10),
+		LoginUserWindowSeconds: getEnvInt("LOGIN_USER_WINDOW_SECONDS", 60),
+		LoginDelayAfter:        getEnvInt("LOGIN_DELAY_AFTER", 3),
+		LoginDelayBaseMillis:   getEnvInt("LOGIN_DELAY_BASE_MS", 1000),
+		LoginDelayMaxSeconds:   getEnvInt("LOGIN_DELAY_MAX_SECONDS", 30),
+		LoginLockoutThreshold:  getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		LoginLockoutMinutes:    getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+
+		TOTPIssuer:            getEnv("TOTP_ISSUER", "Quiz App"),
+		TOTPRequiredForAdmins: getEnvBool("TOTP_REQUIRED_FOR_ADMINS", false),
+
+		HintLifelines:       getEnvInt("LIFELINE_HINTS", 1),
+		FiftyFiftyLifelines: getEnvInt("LIFELINE_FIFTY_FIFTY", 1),
+		HintPenalty:         getEnvInt("HINT_PENALTY", 5),
+		FiftyFiftyPenalty:   getEnvInt("FIFTY_FIFTY_PENALTY", 5),
+
+		QuizTimeLimitSeconds: getEnvInt("QUIZ_TIME_LIMIT_SECONDS", 600),
+		QuizPauseTimer:       getEnvBool("QUIZ_PAUSE_TIMER", true),
+		QuizResumePolicy:     getEnv("QUIZ_RESUME_POLICY", "repeat"),
+
+		LeaderboardTimezone: getEnv("LEADERBOARD_TIMEZONE", "UTC"),
+
+		XPPerAttempt:       getEnvInt("XP_PER_ATTEMPT", 20),
+		XPPerCorrectAnswer: getEnvInt("XP_PER_CORRECT_ANSWER", 10),
+		LevelBaseXP:        getEnvInt("LEVEL_BASE_XP", 100),
+		LevelGrowth:        getEnvFloat("LEVEL_GROWTH", 1.5),
+		FreezeTokenEvery:   getEnvInt("FREEZE_TOKEN_EVERY", 7),
+		MaxFreezeTokens:    getEnvInt("MAX_FREEZE_TOKENS", 2),
+
+		DailyChallengeTime:      getEnv("DAILY_CHALLENGE_TIME", "00:00"),
+		DailyChallengeQuestions: getEnvInt("DAILY_CHALLENGE_QUESTIONS", 5),
+
+		RoomIdleMinutes: getEnvInt("ROOM_IDLE_MINUTES", 60),
+
+		DuelInviteHours:   getEnvInt("DUEL_INVITE_HOURS", 24),
+		DuelSyncMinutes:   getEnvInt("DUEL_SYNC_MINUTES", 15),
+		DuelAsyncHours:    getEnvInt("DUEL_ASYNC_HOURS", 48),
+		DuelRatingKFactor: getEnvFloat("DUEL_RATING_K_FACTOR", 32),
+
+		TeamTopN: getEnvInt("TEAM_TOP_N", 3),
+
+		TournamentRoundHours: getEnvInt("TOURNAMENT_ROUND_HOURS", 24),
+		TournamentQuestions:  getEnvInt("TOURNAMENT_QUESTIONS", 5),
+
+		AdminUsername: getEnv("ADMIN_USERNAME", ""),
+		AdminPassword: getEnv("ADMIN_PASSWORD", ""),
 	}
 }
 
@@ -32,3 +178,30 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
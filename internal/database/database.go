@@ -6,6 +6,19 @@ import (
 
 type User models.User
 type Question models.Question
+type Attempt models.Attempt
+type Quiz models.Quiz
+type StudyCard models.StudyCard
+type AchievementUnlock models.AchievementUnlock
+type DailyChallenge models.DailyChallenge
+type Duel models.Duel
+type Team models.Team
+type Tournament models.Tournament
+type Session models.Session
+type RefreshToken models.RefreshToken
+type APIKey models.APIKey
+type AccountToken models.AccountToken
+type AuditEvent models.AuditEvent
 
 type QuizDatabase interface {
 	AddUser(user User) error
@@ -14,4 +27,67 @@ type QuizDatabase interface {
 
 	GetQuestion(id string) (Question, error)
 	ListQuestions() ([]Question, error)
+
+	AddAttempt(attempt Attempt) error
+	GetAttempt(attemptID string) (Attempt, error)
+	UpdateAttempt(attempt Attempt) error
+	CompleteAttempt(attempt Attempt, user User) error
+	ListAttempts(username string) []Attempt
+	ListAllAttempts() []Attempt
+
+	AddQuiz(quiz Quiz) error
+	GetQuiz(quizID string) (Quiz, error)
+	ListQuizzes() []Quiz
+
+	SaveStudyCard(card StudyCard) error
+	ListStudyCards(username string) []StudyCard
+
+	SaveAchievementUnlock(unlock AchievementUnlock) error
+	ListAchievementUnlocks(username string) []AchievementUnlock
+
+	AddDailyChallenge(challenge DailyChallenge) error
+	GetDailyChallenge(date string) (DailyChallenge, error)
+	ListDailyChallenges() []DailyChallenge
+
+	AddDuel(duel Duel) error
+	GetDuel(duelID string) (Duel, error)
+	UpdateDuel(duel Duel) error
+	ListDuels(username string) []Duel
+
+	AddTeam(team Team) error
+	GetTeam(teamID string) (Team, error)
+	UpdateTeam(team Team) error
+	DeleteTeam(teamID string) error
+	ListTeams() []Team
+
+	AddTournament(tournament Tournament) error
+	GetTournament(tournamentID string) (Tournament, error)
+	UpdateTournament(tournament Tournament) error
+	ListTournaments() []Tournament
+
+	SaveSession(session Session) error
+	GetSession(token string) (Session, error)
+	UpdateSession(session Session) error
+	DeleteSession(token string) error
+	ListSessions() []Session
+
+	AddRefreshToken(token RefreshToken) error
+	GetRefreshToken(tokenHash string) (RefreshToken, error)
+	UpdateRefreshToken(token RefreshToken) error
+	ListRefreshTokens(username string) []RefreshToken
+
+	AddAPIKey(key APIKey) error
+	GetAPIKey(keyID string) (APIKey, error)
+	FindAPIKey(keyHash string) (APIKey, error)
+	UpdateAPIKey(key APIKey) error
+	DeleteAPIKey(keyID string) error
+	ListAPIKeys(username string) []APIKey
+
+	AddAccountToken(token AccountToken) error
+	GetAccountToken(tokenHash string) (AccountToken, error)
+	UpdateAccountToken(token AccountToken) error
+	ListAccountTokens(username string) []AccountToken
+
+	AddAuditEvent(event AuditEvent) error
+	ListAuditEvents(username string) []AuditEvent
 }
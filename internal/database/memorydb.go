@@ -2,19 +2,70 @@ package database
 
 import (
 	"errors"
+	"sort"
 	"sync"
 )
 
 type MemoryDB struct {
 	questions map[string]Question
 	users     map[string]User
-	mu        sync.RWMutex
+	attempts  map[string]Attempt
+	quizzes   map[string]Quiz
+	// studyCards holds the study cards of every user keyed by question ID
+	studyCards map[string]map[int]StudyCard
+	// achievements holds the unlocks of every user keyed by achievement ID
+	achievements map[string]map[string]AchievementUnlock
+	// dailyChallenges holds the daily challenges keyed by date
+	dailyChallenges map[string]DailyChallenge
+	duels           map[string]Duel
+	teams           map[string]Team
+	tournaments     map[string]Tournament
+	// sessions holds the logins keyed by session token
+	sessions map[string]Session
+	// refreshTokens holds the refresh tokens keyed by token hash
+	refreshTokens map[string]RefreshToken
+	apiKeys       map[string]APIKey
+	// accountTokens holds the password reset and email tokens keyed by hash
+	accountTokens map[string]AccountToken
+	// auditEvents holds the audit log, the oldest first
+	auditEvents []AuditEvent
+	mu          sync.RWMutex
 }
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrAttemptNotFound = errors.New("attempt not found")
+	ErrQuizNotFound    = errors.New("quiz not found")
+
+	ErrDailyChallengeNotFound = errors.New("daily challenge not found")
+	ErrDuelNotFound           = errors.New("duel not found")
+	ErrTeamNotFound           = errors.New("team not found")
+	ErrTeamExists             = errors.New("team already exists")
+	ErrTournamentNotFound     = errors.New("tournament not found")
+	ErrSessionNotFound        = errors.New("session not found")
+	ErrRefreshTokenNotFound   = errors.New("refresh token not found")
+	ErrAPIKeyNotFound         = errors.New("API key not found")
+	ErrAccountTokenNotFound   = errors.New("account token not found")
+)
 
 func NewMemoryDB() *MemoryDB {
-	return &MemoryDB{questions: make(map[string]Question), users: make(map[string]User)}
+	return &MemoryDB{
+		questions:    make(map[string]Question),
+		users:        make(map[string]User),
+		attempts:     make(map[string]Attempt),
+		quizzes:      make(map[string]Quiz),
+		studyCards:   make(map[string]map[int]StudyCard),
+		achievements: make(map[string]map[string]AchievementUnlock),
+
+		dailyChallenges: make(map[string]DailyChallenge),
+		duels:           make(map[string]Duel),
+		teams:           make(map[string]Team),
+		tournaments:     make(map[string]Tournament),
+		sessions:        make(map[string]Session),
+		refreshTokens:   make(map[string]RefreshToken),
+		apiKeys:         make(map[string]APIKey),
+		accountTokens:   make(map[string]AccountToken),
+	}
 }
 
 func (db *MemoryDB) AddUser(user User) error {
@@ -85,6 +136,664 @@ func (db *MemoryDB) ListQuestions() ([]Question, error) {
 	return questions, nil
 }
 
+func (db *MemoryDB) AddAttempt(attempt Attempt) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.attempts[attempt.AttemptID]; exists {
+		return errors.New("attempt already exists")
+	}
+	db.attempts[attempt.AttemptID] = attempt
+	return nil
+}
+
+func (db *MemoryDB) GetAttempt(attemptID string) (Attempt, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	attempt, exists := db.attempts[attemptID]
+	if !exists {
+		return Attempt{}, ErrAttemptNotFound
+	}
+	return attempt, nil
+}
+
+func (db *MemoryDB) UpdateAttempt(attempt Attempt) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.attempts[attempt.AttemptID]; !exists {
+		return ErrAttemptNotFound
+	}
+	db.attempts[attempt.AttemptID] = attempt
+	return nil
+}
+
+// CompleteAttempt stores a completed attempt together with the user it
+// updated, so that neither is seen without the other
+func (db *MemoryDB) CompleteAttempt(attempt Attempt, user User) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.attempts[attempt.AttemptID]; !exists {
+		return ErrAttemptNotFound
+	}
+	if _, exists := db.users[user.Username]; !exists {
+		return ErrUserNotFound
+	}
+	db.attempts[attempt.AttemptID] = attempt
+	db.users[user.Username] = user
+	return nil
+}
+
+// ListAttempts returns the attempts of a user ordered by start time
+func (db *MemoryDB) ListAttempts(username string) []Attempt {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var attempts []Attempt
+	for _, attempt := range db.attempts {
+		if attempt.Username == username {
+			attempts = append(attempts, attempt)
+		}
+	}
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].StartedAt.Before(attempts[j].StartedAt)
+	})
+	return attempts
+}
+
+// ListAllAttempts returns the attempts of every user ordered by start time
+func (db *MemoryDB) ListAllAttempts() []Attempt {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	attempts := make([]Attempt, 0, len(db.attempts))
+	for _, attempt := range db.attempts {
+		attempts = append(attempts, attempt)
+	}
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].StartedAt.Before(attempts[j].StartedAt)
+	})
+	return attempts
+}
+
+// AddQuiz stores a quiz definition, replacing an existing one with the same ID
+func (db *MemoryDB) AddQuiz(quiz Quiz) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if quiz.QuizID == "" {
+		return errors.New("quiz ID cannot be empty")
+	}
+	db.quizzes[quiz.QuizID] = quiz
+	return nil
+}
+
+func (db *MemoryDB) GetQuiz(quizID string) (Quiz, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	quiz, exists := db.quizzes[quizID]
+	if !exists {
+		return Quiz{}, ErrQuizNotFound
+	}
+	return quiz, nil
+}
+
+func (db *MemoryDB) ListQuizzes() []Quiz {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var quizzes []Quiz
+	for _, quiz := range db.quizzes {
+		quizzes = append(quizzes, quiz)
+	}
+	sort.Slice(quizzes, func(i, j int) bool {
+		return quizzes[i].QuizID < quizzes[j].QuizID
+	})
+	return quizzes
+}
+
+// SaveStudyCard stores the study card of a user, replacing the previous one for the question
+func (db *MemoryDB) SaveStudyCard(card StudyCard) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if card.Username == "" {
+		return errors.New("study card has no user")
+	}
+	if db.studyCards[card.Username] == nil {
+		db.studyCards[card.Username] = make(map[int]StudyCard)
+	}
+	db.studyCards[card.Username][card.QuestionID] = card
+	return nil
+}
+
+// ListStudyCards returns the study cards of a user ordered by question
+func (db *MemoryDB) ListStudyCards(username string) []StudyCard {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var cards []StudyCard
+	for _, card := range db.studyCards[username] {
+		cards = append(cards, card)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].QuestionIndex < cards[j].QuestionIndex
+	})
+	return cards
+}
+
+// SaveAchievementUnlock stores an achievement unlock, replacing the previous record of it
+func (db *MemoryDB) SaveAchievementUnlock(unlock AchievementUnlock) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if unlock.Username == "" || unlock.AchievementID == "" {
+		return errors.New("achievement unlock needs a user and an achievement")
+	}
+	if db.achievements[unlock.Username] == nil {
+		db.achievements[unlock.Username] = make(map[string]AchievementUnlock)
+	}
+	db.achievements[unlock.Username][unlock.AchievementID] = unlock
+	return nil
+}
+
+// ListAchievementUnlocks returns the unlocks of a user ordered by unlock time
+func (db *MemoryDB) ListAchievementUnlocks(username string) []AchievementUnlock {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var unlocks []AchievementUnlock
+	for _, unlock := range db.achievements[username] {
+		unlocks = append(unlocks, unlock)
+	}
+	sort.Slice(unlocks, func(i, j int) bool {
+		if !unlocks[i].UnlockedAt.Equal(unlocks[j].UnlockedAt) {
+			return unlocks[i].UnlockedAt.Before(unlocks[j].UnlockedAt)
+		}
+		return unlocks[i].AchievementID < unlocks[j].AchievementID
+	})
+	return unlocks
+}
+
+// AddDailyChallenge stores the daily challenge of a date, which cannot change once stored
+func (db *MemoryDB) AddDailyChallenge(challenge DailyChallenge) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if challenge.Date == "" {
+		return errors.New("daily challenge has no date")
+	}
+	if _, exists := db.dailyChallenges[challenge.Date]; exists {
+		return errors.New("daily challenge already exists")
+	}
+	db.dailyChallenges[challenge.Date] = challenge
+	return nil
+}
+
+func (db *MemoryDB) GetDailyChallenge(date string) (DailyChallenge, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	challenge, exists := db.dailyChallenges[date]
+	if !exists {
+		return DailyChallenge{}, ErrDailyChallengeNotFound
+	}
+	return challenge, nil
+}
+
+// ListDailyChallenges returns the daily challenges, the latest first
+func (db *MemoryDB) ListDailyChallenges() []DailyChallenge {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	challenges := make([]DailyChallenge, 0, len(db.dailyChallenges))
+	for _, challenge := range db.dailyChallenges {
+		challenges = append(challenges, challenge)
+	}
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].Date > challenges[j].Date
+	})
+	return challenges
+}
+
+func (db *MemoryDB) AddDuel(duel Duel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.duels[duel.DuelID]; exists {
+		return errors.New("duel already exists")
+	}
+	db.duels[duel.DuelID] = duel
+	return nil
+}
+
+func (db *MemoryDB) GetDuel(duelID string) (Duel, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	duel, exists := db.duels[duelID]
+	if !exists {
+		return Duel{}, ErrDuelNotFound
+	}
+	return duel, nil
+}
+
+func (db *MemoryDB) UpdateDuel(duel Duel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.duels[duel.DuelID]; !exists {
+		return ErrDuelNotFound
+	}
+	db.duels[duel.DuelID] = duel
+	return nil
+}
+
+// ListDuels returns the duels a user challenged or was challenged to, the latest first
+func (db *MemoryDB) ListDuels(username string) []Duel {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var duels []Duel
+	for _, duel := range db.duels {
+		if duel.Challenger == username || duel.Opponent == username {
+			duels = append(duels, duel)
+		}
+	}
+	sort.Slice(duels, func(i, j int) bool {
+		return duels[i].CreatedAt.After(duels[j].CreatedAt)
+	})
+	return duels
+}
+
+func (db *MemoryDB) AddTeam(team Team) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if team.TeamID == "" {
+		return errors.New("team ID cannot be empty")
+	}
+	if _, exists := db.teams[team.TeamID]; exists {
+		return ErrTeamExists
+	}
+	db.teams[team.TeamID] = team
+	return nil
+}
+
+func (db *MemoryDB) GetTeam(teamID string) (Team, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	team, exists := db.teams[teamID]
+	if !exists {
+		return Team{}, ErrTeamNotFound
+	}
+	return team, nil
+}
+
+func (db *MemoryDB) UpdateTeam(team Team) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.teams[team.TeamID]; !exists {
+		return ErrTeamNotFound
+	}
+	db.teams[team.TeamID] = team
+	return nil
+}
+
+func (db *MemoryDB) DeleteTeam(teamID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.teams[teamID]; !exists {
+		return ErrTeamNotFound
+	}
+	delete(db.teams, teamID)
+	return nil
+}
+
+// ListTeams returns the teams ordered by ID
+func (db *MemoryDB) ListTeams() []Team {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	teams := make([]Team, 0, len(db.teams))
+	for _, team := range db.teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].TeamID < teams[j].TeamID
+	})
+	return teams
+}
+
+func (db *MemoryDB) AddTournament(tournament Tournament) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.tournaments[tournament.TournamentID]; exists {
+		return errors.New("tournament already exists")
+	}
+	db.tournaments[tournament.TournamentID] = tournament
+	return nil
+}
+
+func (db *MemoryDB) GetTournament(tournamentID string) (Tournament, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	tournament, exists := db.tournaments[tournamentID]
+	if !exists {
+		return Tournament{}, ErrTournamentNotFound
+	}
+	return tournament, nil
+}
+
+func (db *MemoryDB) UpdateTournament(tournament Tournament) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.tournaments[tournament.TournamentID]; !exists {
+		return ErrTournamentNotFound
+	}
+	db.tournaments[tournament.TournamentID] = tournament
+	return nil
+}
+
+// ListTournaments returns the tournaments, the latest first
+func (db *MemoryDB) ListTournaments() []Tournament {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	tournaments := make([]Tournament, 0, len(db.tournaments))
+	for _, tournament := range db.tournaments {
+		tournaments = append(tournaments, tournament)
+	}
+	sort.Slice(tournaments, func(i, j int) bool {
+		return tournaments[i].CreatedAt.After(tournaments[j].CreatedAt)
+	})
+	return tournaments
+}
+
+// SaveSession stores a session, replacing the previous record of its token
+func (db *MemoryDB) SaveSession(session Session) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if session.Token == "" {
+		return errors.New("session token cannot be empty")
+	}
+	db.sessions[session.Token] = session
+	return nil
+}
+
+func (db *MemoryDB) GetSession(token string) (Session, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	session, exists := db.sessions[token]
+	if !exists {
+		return Session{}, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (db *MemoryDB) UpdateSession(session Session) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.sessions[session.Token]; !exists {
+		return ErrSessionNotFound
+	}
+	db.sessions[session.Token] = session
+	return nil
+}
+
+func (db *MemoryDB) DeleteSession(token string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.sessions[token]; !exists {
+		return ErrSessionNotFound
+	}
+	delete(db.sessions, token)
+	return nil
+}
+
+// ListSessions returns every session ordered by creation time
+func (db *MemoryDB) ListSessions() []Session {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	sessions := make([]Session, 0, len(db.sessions))
+	for _, session := range db.sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions
+}
+
+func (db *MemoryDB) AddRefreshToken(token RefreshToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if token.TokenHash == "" {
+		return errors.New("refresh token hash cannot be empty")
+	}
+	if _, exists := db.refreshTokens[token.TokenHash]; exists {
+		return errors.New("refresh token already exists")
+	}
+	db.refreshTokens[token.TokenHash] = token
+	return nil
+}
+
+func (db *MemoryDB) GetRefreshToken(tokenHash string) (RefreshToken, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	token, exists := db.refreshTokens[tokenHash]
+	if !exists {
+		return RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	return token, nil
+}
+
+func (db *MemoryDB) UpdateRefreshToken(token RefreshToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.refreshTokens[token.TokenHash]; !exists {
+		return ErrRefreshTokenNotFound
+	}
+	db.refreshTokens[token.TokenHash] = token
+	return nil
+}
+
+// ListRefreshTokens returns the refresh tokens of a user, the oldest first
+func (db *MemoryDB) ListRefreshTokens(username string) []RefreshToken {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var tokens []RefreshToken
+	for _, token := range db.refreshTokens {
+		if token.Username == username {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].IssuedAt.Before(tokens[j].IssuedAt)
+	})
+	return tokens
+}
+
+func (db *MemoryDB) AddAPIKey(key APIKey) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if key.KeyID == "" || key.KeyHash == "" {
+		return errors.New("API key ID and hash cannot be empty")
+	}
+	if _, exists := db.apiKeys[key.KeyID]; exists {
+		return errors.New("API key already exists")
+	}
+	db.apiKeys[key.KeyID] = key
+	return nil
+}
+
+func (db *MemoryDB) GetAPIKey(keyID string) (APIKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	key, exists := db.apiKeys[keyID]
+	if !exists {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// FindAPIKey returns the API key with the given hash
+func (db *MemoryDB) FindAPIKey(keyHash string) (APIKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, key := range db.apiKeys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return APIKey{}, ErrAPIKeyNotFound
+}
+
+func (db *MemoryDB) UpdateAPIKey(key APIKey) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.apiKeys[key.KeyID]; !exists {
+		return ErrAPIKeyNotFound
+	}
+	db.apiKeys[key.KeyID] = key
+	return nil
+}
+
+func (db *MemoryDB) DeleteAPIKey(keyID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.apiKeys[keyID]; !exists {
+		return ErrAPIKeyNotFound
+	}
+	delete(db.apiKeys, keyID)
+	return nil
+}
+
+// ListAPIKeys returns the API keys of a user, the oldest first
+func (db *MemoryDB) ListAPIKeys(username string) []APIKey {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var keys []APIKey
+	for _, key := range db.apiKeys {
+		if key.Username == username {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+func (db *MemoryDB) AddAccountToken(token AccountToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if token.TokenHash == "" {
+		return errors.New("account token hash cannot be empty")
+	}
+	if _, exists := db.accountTokens[token.TokenHash]; exists {
+		return errors.New("account token already exists")
+	}
+	db.accountTokens[token.TokenHash] = token
+	return nil
+}
+
+func (db *MemoryDB) GetAccountToken(tokenHash string) (AccountToken, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	token, exists := db.accountTokens[tokenHash]
+	if !exists {
+		return AccountToken{}, ErrAccountTokenNotFound
+	}
+	return token, nil
+}
+
+func (db *MemoryDB) UpdateAccountToken(token AccountToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.accountTokens[token.TokenHash]; !exists {
+		return ErrAccountTokenNotFound
+	}
+	db.accountTokens[token.TokenHash] = token
+	return nil
+}
+
+// ListAccountTokens returns the account tokens of a user, the oldest first
+func (db *MemoryDB) ListAccountTokens(username string) []AccountToken {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var tokens []AccountToken
+	for _, token := range db.accountTokens {
+		if token.Username == username {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens
+}
+
+// maxAuditEvents bounds the audit log; the oldest events are dropped first
+const maxAuditEvents = 10000
+
+func (db *MemoryDB) AddAuditEvent(event AuditEvent) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if event.Type == "" {
+		return errors.New("audit event type cannot be empty")
+	}
+	db.auditEvents = append(db.auditEvents, event)
+	if len(db.auditEvents) > maxAuditEvents {
+		db.auditEvents = append([]AuditEvent(nil), db.auditEvents[len(db.auditEvents)-maxAuditEvents:]...)
+	}
+	return nil
+}
+
+// ListAuditEvents returns the audit events about a user, or every event when
+// username is empty, the oldest first
+func (db *MemoryDB) ListAuditEvents(username string) []AuditEvent {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var events []AuditEvent
+	for _, event := range db.auditEvents {
+		if username == "" || event.Username == username {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
 // Clear clears all data from the in-memory database
 func (db *MemoryDB) Clear() {
 	db.mu.Lock()
@@ -96,4 +805,41 @@ func (db *MemoryDB) Clear() {
 	for k := range db.users {
 		delete(db.users, k)
 	}
+	for k := range db.attempts {
+		delete(db.attempts, k)
+	}
+	for k := range db.quizzes {
+		delete(db.quizzes, k)
+	}
+	for k := range db.studyCards {
+		delete(db.studyCards, k)
+	}
+	for k := range db.achievements {
+		delete(db.achievements, k)
+	}
+	for k := range db.dailyChallenges {
+		delete(db.dailyChallenges, k)
+	}
+	for k := range db.duels {
+		delete(db.duels, k)
+	}
+	for k := range db.teams {
+		delete(db.teams, k)
+	}
+	for k := range db.tournaments {
+		delete(db.tournaments, k)
+	}
+	for k := range db.sessions {
+		delete(db.sessions, k)
+	}
+	for k := range db.refreshTokens {
+		delete(db.refreshTokens, k)
+	}
+	for k := range db.apiKeys {
+		delete(db.apiKeys, k)
+	}
+	for k := range db.accountTokens {
+		delete(db.accountTokens, k)
+	}
+	db.auditEvents = nil
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type AccountHandler struct {
+	AccountService services.IAccountService
+}
+
+func NewAccountHandler(accountService services.IAccountService) *AccountHandler {
+	return &AccountHandler{AccountService: accountService}
+}
+
+// SetEmail sets the email address of the user
+// @Summary Set my email address
+// @Description Checks the current password, sets the address password resets go to and mails a token to verify it. The address is unverified until the token is sent to /email/verify. The previous address is told about the change.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param email body models.EmailPayload true "Current password and email address"
+// @Success 202 {object} map[string]string "Verification sent"
+// @Failure 400 {object} map[string]string "Invalid email address"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {object} map[string]string "Current password is incorrect"
+// @Failure 409 {object} map[string]string "Email address is used by another account"
+// @Router /me/email [put]
+func (h *AccountHandler) SetEmail(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	var payload models.EmailPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.CurrentPassword == "" || payload.Email == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := h.AccountService.SetEmail(username, payload.CurrentPassword, payload.Email); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusAccepted, "Verification email sent")
+}
+
+// VerifyEmail verifies an email address
+// @Summary Verify an email address
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param token body models.AccountTokenPayload true "Token from the verification email"
+// @Success 200 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Invalid, used or expired token"
+// @Router /email/verify [post]
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var payload models.AccountTokenPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Token == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := h.AccountService.VerifyEmail(payload.Token); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusOK, "Email verified")
+}
+
+// ForgotPassword mails a password reset token
+// @Summary Request a password reset
+// @Description Mails a reset token to the verified email address of the account named by username or email. The answer is the same whether or not such an account exists. Requests are rate limited per IP address and per account.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param account body models.ForgotPasswordPayload true "Username or email"
+// @Success 202 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 429 {object} map[string]string "Too many requests"
+// @Router /password/forgot [post]
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var payload models.ForgotPasswordPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || (payload.Username == "" && payload.Email == "") {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := h.AccountService.RequestPasswordReset(payload, utils.ClientIP(r)); err != nil {
+		var limitErr *services.LoginLimitError
+		if errors.As(err, &limitErr) {
+			writeLoginLimitError(w, limitErr)
+			return
+		}
+		writeAccountError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusAccepted, "If the account has a verified email address, a reset token is on its way")
+}
+
+// ResetPassword sets a new password with a reset token
+// @Summary Reset a forgotten password
+// @Description Sets a new password with the token from the reset email. The token works once, and every session and bearer token of the user is revoked.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param reset body models.ResetPasswordPayload true "Token and new password"
+// @Success 200 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Invalid token or password too weak"
+// @Router /password/reset [post]
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var payload models.ResetPasswordPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Token == "" || payload.NewPassword == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	if err := h.AccountService.ResetPassword(payload.Token, payload.NewPassword); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusOK, "Password reset, log in with the new password")
+}
+
+// writeAccountError maps account errors to HTTP status codes.
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEmail),
+		errors.Is(err, services.ErrInvalidAccountToken),
+		errors.Is(err, services.ErrInvalidPassword):
+		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrWrongPassword):
+		writeJSONMessage(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrEmailTaken):
+		writeJSONMessage(w, http.StatusConflict, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("Account request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountService is a mock implementation of the IAccountService interface.
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) SetEmail(username, currentPassword, email string) error {
+	args := m.Called(username, currentPassword, email)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) VerifyEmail(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) RequestPasswordReset(payload models.ForgotPasswordPayload, ip string) error {
+	args := m.Called(payload, ip)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) ResetPassword(token, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+
+func TestSetEmail(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+	mockService.On("SetEmail", "alice", "Password123!", "alice@example.com").Return(nil)
+	mockService.On("SetEmail", "alice", "Password123!", "bob@example.com").Return(services.ErrEmailTaken)
+	mockService.On("SetEmail", "alice", "Wrong123!", "alice@example.com").Return(services.ErrWrongPassword)
+
+	for body, status := range map[string]int{
+		`{"current_password":"Password123!","email":"alice@example.com"}`: http.StatusAccepted,
+		`{"current_password":"Password123!","email":"bob@example.com"}`:   http.StatusConflict,
+		`{"current_password":"Wrong123!","email":"alice@example.com"}`:    http.StatusForbidden,
+		`{"email":"alice@example.com"}`:                                   http.StatusBadRequest,
+	} {
+		req := newSessionRequest(t, http.MethodPut, "/me/email", bytes.NewBufferString(body), "alice")
+		rr := httptest.NewRecorder()
+		handler.SetEmail(rr, req)
+		assert.Equal(t, status, rr.Code, body)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	mockService := new(MockAccountService)
+	handler := NewAccountHandler(mockService)
+	mockService.On("RequestPasswordReset", models.ForgotPasswordPayload{Username: "nobody"}, "192.0.2.1").Return(nil)
+	mockService.On("RequestPasswordReset", models.ForgotPasswordPayload{Username: "flood"}, "192.0.2.1").Return(&services.LoginLimitError{Rule: services.ErrLoginRateLimited, RetryAt: time.Now().Add(time.Minute)})
+	mockService.On("ResetPassword", "used", "Changed@456").Return(services.ErrInvalidAccountToken)
+	mockService.On("ResetPassword", "fresh", "Changed@456").Return(nil)
+
+	rr := httptest.NewRecorder()
+	handler.ForgotPassword(rr, httptest.NewRequest(http.MethodPost, "/password/forgot", bytes.NewBufferString(`{"username":"nobody"}`)))
+	assert.Equal(t, http.StatusAccepted, rr.Code, "expected unknown accounts to look like known ones")
+
+	rr = httptest.NewRecorder()
+	handler.ForgotPassword(rr, httptest.NewRequest(http.MethodPost, "/password/forgot", bytes.NewBufferString(`{"username":"flood"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	rr = httptest.NewRecorder()
+	handler.ForgotPassword(rr, httptest.NewRequest(http.MethodPost, "/password/forgot", bytes.NewBufferString(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	for token, status := range map[string]int{"used": http.StatusBadRequest, "fresh": http.StatusOK} {
+		rr = httptest.NewRecorder()
+		handler.ResetPassword(rr, httptest.NewRequest(http.MethodPost, "/password/reset", bytes.NewBufferString(`{"token":"`+token+`","new_password":"Changed@456"}`)))
+		assert.Equal(t, status, rr.Code)
+	}
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type AchievementHandler struct {
+	AchievementService services.IAchievementService
+}
+
+func NewAchievementHandler(achievementService services.IAchievementService) *AchievementHandler {
+	return &AchievementHandler{AchievementService: achievementService}
+}
+
+// GetAchievements lists the achievements of the user
+// @Summary Get my achievements
+// @Description Lists every achievement with whether and when the logged-in user unlocked it
+// @Tags Me
+// @Produce json
+// @Success 200 {array} models.AchievementStatus "Achievements of the user"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /me/achievements [get]
+func (h *AchievementHandler) GetAchievements(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	achievements, err := h.AchievementService.GetAchievements(username)
+	if err != nil {
+		logger.Error("Failed to retrieve achievements", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(achievements); err != nil {
+		logger.Warn("Failed to encode achievements response", zap.Error(err))
+	}
+}
+
+// announceUnlocks adds the achievements the user unlocked since the last
+// announcement to a response.
+func announceUnlocks(achievements services.IAchievementService, username string, response map[string]interface{}) {
+	if achievements == nil {
+		return
+	}
+	if unlocks := achievements.TakeNewUnlocks(username); len(unlocks) > 0 {
+		response["achievements_unlocked"] = unlocks
+	}
+}
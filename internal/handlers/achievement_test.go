@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAchievementService is a mock implementation of the IAchievementService interface.
+type MockAchievementService struct {
+	mock.Mock
+}
+
+func (m *MockAchievementService) GetAchievements(username string) ([]models.AchievementStatus, error) {
+	args := m.Called(username)
+	statuses, _ := args.Get(0).([]models.AchievementStatus)
+	return statuses, args.Error(1)
+}
+
+func (m *MockAchievementService) TakeNewUnlocks(username string) []models.AchievementUnlock {
+	args := m.Called(username)
+	unlocks, _ := args.Get(0).([]models.AchievementUnlock)
+	return unlocks
+}
+
+func TestGetAchievements(t *testing.T) {
+	mockService := new(MockAchievementService)
+	handler := NewAchievementHandler(mockService)
+	mockService.On("GetAchievements", "testuser").Return([]models.AchievementStatus{
+		{Achievement: models.Achievement{AchievementID: "perfect", Rule: models.RulePerfectScore}, Unlocked: true},
+	}, nil)
+	mockService.On("GetAchievements", "nobody").Return(nil, errors.New("user not found"))
+
+	req := newSessionRequest(t, http.MethodGet, "/me/achievements", nil, "testuser")
+	rr := httptest.NewRecorder()
+	handler.GetAchievements(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"unlocked":true`)
+
+	req = newSessionRequest(t, http.MethodGet, "/me/achievements", nil, "nobody")
+	rr = httptest.NewRecorder()
+	handler.GetAchievements(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestSubmitAnswerAnnouncesUnlocks(t *testing.T) {
+	mockService := new(MockQuizService)
+	mockAchievements := new(MockAchievementService)
+	handler := NewQuizHandler(mockService)
+	handler.Achievements = mockAchievements
+	mockService.On("GetQuestions").Return([]models.Question{{QuestionID: 1, Question: "What is Go?", Options: []string{"A", "B", "C"}, Answer: 1}}, nil)
+	mockService.On("SubmitAnswer", "testuser", 0, 1).Return(&models.AnswerResult{Mode: models.QuizModeStandard, Correct: true}, nil)
+	unlockedAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	mockAchievements.On("TakeNewUnlocks", "testuser").Return([]models.AchievementUnlock{{AchievementID: "perfect", Title: "Flawless", Username: "testuser", UnlockedAt: unlockedAt}})
+
+	body, _ := json.Marshal(models.AnswerPayload{QuestionIndex: 0, Answer: 1})
+	req := newSessionRequest(t, http.MethodPost, "/quiz/submit", bytes.NewBuffer(body), "testuser")
+	rr := httptest.NewRecorder()
+	handler.SubmitAnswer(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"message":"Correct answer","achievements_unlocked":[{"achievement_id":"perfect","title":"Flawless","username":"testuser","unlocked_at":"2025-01-10T12:00:00Z"}]}`, rr.Body.String())
+	mockAchievements.AssertExpectations(t)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type AnalyticsHandler struct {
+	AnalyticsService services.IAnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService services.IAnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{AnalyticsService: analyticsService}
+}
+
+// QuestionAnalytics returns the item analysis of every question
+// @Summary Question item analysis
+// @Description Reports per question the p-value, the point-biserial discrimination, how often each option is picked and the average answer time. Questions where a wrong option beats the keyed answer are flagged. Admins only.
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} models.QuestionAnalytics "Item analysis per question"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/analytics/questions [get]
+func (h *AnalyticsHandler) QuestionAnalytics(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	report, err := h.AnalyticsService.QuestionAnalytics()
+	if err != nil {
+		logger.Error("Failed to compute question analytics", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Warn("Failed to encode question analytics response", zap.Error(err))
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAnalyticsService is a mock implementation of the IAnalyticsService interface.
+type MockAnalyticsService struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsService) QuestionAnalytics() ([]models.QuestionAnalytics, error) {
+	args := m.Called()
+	report, _ := args.Get(0).([]models.QuestionAnalytics)
+	return report, args.Error(1)
+}
+
+func TestQuestionAnalytics(t *testing.T) {
+	t.Run("Report", func(t *testing.T) {
+		mockService := new(MockAnalyticsService)
+		handler := NewAnalyticsHandler(mockService)
+		mockService.On("QuestionAnalytics").Return([]models.QuestionAnalytics{{QuestionID: 1, Flagged: true, FlagReason: "option 3 is picked more often than the keyed answer 1"}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics/questions", nil)
+		rr := httptest.NewRecorder()
+		handler.QuestionAnalytics(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"flagged":true`)
+	})
+
+	t.Run("No questions", func(t *testing.T) {
+		mockService := new(MockAnalyticsService)
+		handler := NewAnalyticsHandler(mockService)
+		mockService.On("QuestionAnalytics").Return(nil, errors.New("no questions available"))
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics/questions", nil)
+		rr := httptest.NewRecorder()
+		handler.QuestionAnalytics(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
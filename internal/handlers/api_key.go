@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type APIKeyHandler struct {
+	APIKeyService services.IAPIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.IAPIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{APIKeyService: apiKeyService}
+}
+
+// CreateAPIKey creates a personal API key
+// @Summary Create an API key
+// @Description Creates a key to send in the X-API-Key header. The key is only in this response, the server keeps a hash. Scopes are read, play, analytics and admin; admin endpoints also need an admin owner. Keys without expires_in_days never expire. API keys cannot manage API keys.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param key body models.APIKeyPayload true "Name, scopes and expiry"
+// @Success 201 {object} models.NewAPIKey "Key"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {string} string "Invalid session"
+// @Router /me/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	var payload models.APIKeyPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logger.Warn("Invalid API key payload", zap.Error(err))
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	key, err := h.APIKeyService.CreateKey(username, payload)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		logger.Warn("Failed to encode API key response", zap.Error(err))
+	}
+}
+
+// ListAPIKeys lists the API keys of the user
+// @Summary List my API keys
+// @Description Lists the keys with their prefix, scopes, expiry and when they were last used. The keys themselves are never shown again.
+// @Tags User
+// @Produce json
+// @Success 200 {array} models.APIKey "Keys, the oldest first"
+// @Failure 401 {string} string "Invalid session"
+// @Router /me/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	keys, err := h.APIKeyService.ListKeys(username)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		logger.Warn("Failed to encode API keys response", zap.Error(err))
+	}
+}
+
+// RevokeAPIKey deletes an API key of the user
+// @Summary Revoke an API key
+// @Tags User
+// @Produce json
+// @Param id path string true "Key ID"
+// @Success 200 {object} map[string]string "message"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "API key not found"
+// @Router /me/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	if err := h.APIKeyService.RevokeKey(username, mux.Vars(r)["id"]); err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusOK, "API key revoked")
+}
+
+// writeAPIKeyError maps API key errors to HTTP status codes.
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAPIKeyName),
+		errors.Is(err, services.ErrInvalidAPIKeyScope),
+		errors.Is(err, services.ErrInvalidAPIKeyExpiry):
+		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrAPIKeyNotFound), errors.Is(err, database.ErrUserNotFound):
+		writeJSONMessage(w, http.StatusNotFound, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("API key request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
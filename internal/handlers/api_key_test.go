@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyService is a mock implementation of the IAPIKeyService interface.
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateKey(username string, payload models.APIKeyPayload) (*models.NewAPIKey, error) {
+	args := m.Called(username, payload)
+	key, _ := args.Get(0).(*models.NewAPIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyService) ListKeys(username string) ([]models.APIKey, error) {
+	args := m.Called(username)
+	keys, _ := args.Get(0).([]models.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeKey(username, keyID string) error {
+	args := m.Called(username, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	args := m.Called(key)
+	apiKey, _ := args.Get(0).(*models.APIKey)
+	return apiKey, args.Error(1)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"Created", nil, http.StatusCreated},
+		{"Unknown scope", services.ErrInvalidAPIKeyScope, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			handler := NewAPIKeyHandler(mockService)
+			payload := models.APIKeyPayload{Name: "ci", Scopes: []string{models.ScopeRead}}
+			var key *models.NewAPIKey
+			if tt.err == nil {
+				key = &models.NewAPIKey{APIKey: models.APIKey{KeyID: "k1", Name: "ci", KeyHash: "hash"}, Key: "qk_secret"}
+			}
+			mockService.On("CreateKey", "alice", payload).Return(key, tt.err)
+
+			body, _ := json.Marshal(payload)
+			req := newSessionRequest(t, http.MethodPost, "/me/api-keys", bytes.NewReader(body), "alice")
+			rr := httptest.NewRecorder()
+			handler.CreateAPIKey(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.err == nil {
+				assert.Contains(t, rr.Body.String(), `"key":"qk_secret"`)
+				assert.NotContains(t, rr.Body.String(), "hash")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	mockService := new(MockAPIKeyService)
+	handler := NewAPIKeyHandler(mockService)
+	mockService.On("RevokeKey", "alice", "k1").Return(nil)
+	mockService.On("RevokeKey", "alice", "k2").Return(database.ErrAPIKeyNotFound)
+
+	for id, status := range map[string]int{"k1": http.StatusOK, "k2": http.StatusNotFound} {
+		req := newSessionRequest(t, http.MethodDelete, "/me/api-keys/"+id, nil, "alice")
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		handler.RevokeAPIKey(rr, req)
+		assert.Equal(t, status, rr.Code)
+	}
+	mockService.AssertExpectations(t)
+}
@@ -2,18 +2,24 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Dzsodie/quiz_app/internal/database"
 	"github.com/Dzsodie/quiz_app/internal/models"
 	"github.com/Dzsodie/quiz_app/internal/services"
 	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
 type AuthHandler struct {
 	AuthService services.IAuthService
 	Database    *database.MemoryDB
+	// TwoFactor adds the second login step of the users who have it on
+	TwoFactor services.ITwoFactorService
 }
 
 func NewAuthHandler(authService services.IAuthService) *AuthHandler {
@@ -68,14 +74,16 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Login a user
-// @Description Login with a username and password
+// @Description Login with a username and password. Users with two-factor authentication on get a challenge to finish on /login/2fa instead of a session.
 // @Tags User
 // @Accept json
 // @Produce json
 // @Param user body models.User true "User details"
 // @Success 200 {object} map[string]string "message"
+// @Success 202 {object} models.LoginChallenge "Two-factor code required"
 // @Failure 400 {object} map[string]string "Invalid input"
 // @Failure 401 {object} map[string]string "Invalid credentials"
+// @Failure 429 {object} map[string]interface{} "Too many attempts or account locked, see Retry-After"
 // @Router /login [post]
 func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	logger := utils.GetLogger().Sugar()
@@ -87,12 +95,67 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.AuthService.AuthenticateUser(user.Username, user.Password); err != nil {
+	if err := h.AuthService.Login(user.Username, user.Password, utils.ClientIP(r)); err != nil {
+		var limitErr *services.LoginLimitError
+		if errors.As(err, &limitErr) {
+			writeLoginLimitError(w, limitErr)
+			return
+		}
 		logger.Warn("Authentication failed", zap.String("username", user.Username), zap.Error(err))
 		http.Error(w, `{"message":"Invalid username or password"}`, http.StatusUnauthorized)
 		return
 	}
 
+	if h.TwoFactor != nil {
+		challenge, err := h.TwoFactor.StartLogin(user.Username)
+		if err != nil {
+			logger.Error("Failed to start two-factor login", zap.String("username", user.Username), zap.Error(err))
+			http.Error(w, `{"message":"Internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if challenge != nil {
+			writeLoginChallenge(w, challenge)
+			return
+		}
+	}
+
+	h.startSession(w, r, user.Username)
+}
+
+// @Summary Finish a two-factor login
+// @Description Finishes a login of a user with two-factor authentication on, with the challenge token from /login and a code from the authenticator app or a recovery code. Each recovery code works once.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorLoginPayload true "Challenge token and code"
+// @Success 200 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {object} map[string]string "Invalid code or challenge"
+// @Failure 429 {object} map[string]interface{} "Too many attempts or account locked, see Retry-After"
+// @Router /login/2fa [post]
+func (h *AuthHandler) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	var payload models.TwoFactorLoginPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.ChallengeToken == "" || payload.Code == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+	if h.TwoFactor == nil {
+		writeTwoFactorLoginError(w, services.ErrInvalidLoginChallenge)
+		return
+	}
+
+	username, err := h.TwoFactor.CompleteLogin(payload.ChallengeToken, payload.Code, utils.ClientIP(r))
+	if err != nil {
+		writeTwoFactorLoginError(w, err)
+		return
+	}
+	h.startSession(w, r, username)
+}
+
+// startSession logs the user in with a new session and its cookie.
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, username string) {
+	logger := utils.GetLogger().Sugar()
+
 	sessionToken, err := utils.GenerateSessionToken()
 	if err != nil {
 		logger.Error("Failed to generate session token", zap.Error(err))
@@ -100,14 +163,18 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := h.AuthService.GetUserID(user.Username)
+	userID, err := h.AuthService.GetUserID(username)
 	if err != nil {
 		logger.Error("Failed to retrieve user ID", zap.Error(err))
 		http.Error(w, `{"message":"Internal server error"}`, http.StatusInternalServerError)
 		return
 	}
 
-	utils.SessionDB[sessionToken] = user.Username
+	if err := utils.SaveSession(sessionToken, username, utils.ClientIP(r), r.UserAgent()); err != nil {
+		logger.Error("Failed to store session", zap.Error(err))
+		http.Error(w, `{"message":"Internal server error"}`, http.StatusInternalServerError)
+		return
+	}
 
 	session, err := utils.SessionStore.Get(r, "quiz-session")
 	if err != nil {
@@ -116,7 +183,7 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session.Values["username"] = user.Username
+	session.Values["username"] = username
 	session.Values["session_token"] = sessionToken
 	session.Values["userID"] = userID
 
@@ -129,7 +196,7 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	response := map[string]string{
 		"message":       "Login successful",
 		"session_token": sessionToken,
-		"username":      user.Username,
+		"username":      username,
 		"userID":        userID,
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -139,3 +206,262 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 }
+
+// @Summary Log out
+// @Description Revokes the session token of this login and clears the session cookie
+// @Tags User
+// @Produce json
+// @Success 200 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Not a cookie session, use /token/revoke"
+// @Failure 401 {string} string "Invalid session"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+	sessionToken, _ := session.Values["session_token"].(string)
+
+	// Bearer and API key requests carry no session to end; answering 200
+	// would leave the tokens valid
+	if sessionToken == "" {
+		logger.Warn("Logout without a cookie session", zap.String("username", username))
+		writeJSONMessage(w, http.StatusBadRequest, "Logout ends cookie sessions; revoke tokens with /token/revoke")
+		return
+	}
+
+	h.AuthService.Logout(sessionToken)
+	clearSessionCookie(w, r)
+
+	logger.Info("User logged out", zap.String("username", username))
+	writeJSONMessage(w, http.StatusOK, "Logged out")
+}
+
+// @Summary Log out everywhere
+// @Description Revokes every session of the logged-in user, on all devices, and clears the session cookie
+// @Tags User
+// @Produce json
+// @Success 200 {object} map[string]interface{} "message and number of revoked sessions"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /logout/all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	revoked, err := h.AuthService.RevokeSessions(username)
+	if err != nil {
+		logger.Error("Failed to revoke sessions", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	clearSessionCookie(w, r)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"message": "Logged out of all sessions", "revoked": revoked}); err != nil {
+		logger.Warn("Failed to encode logout response", zap.Error(err))
+	}
+}
+
+// @Summary List my sessions
+// @Description Lists the sessions of the logged-in user that are still valid with when and where they were used. The session of this request is marked current.
+// @Tags User
+// @Produce json
+// @Success 200 {array} models.Session "Sessions, the oldest first"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /me/sessions [get]
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+	sessionToken, _ := session.Values["session_token"].(string)
+
+	sessions, err := h.AuthService.ListSessions(username)
+	if err != nil {
+		logger.Error("Failed to list sessions", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Token == sessionToken
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sessions); err != nil {
+		logger.Warn("Failed to encode sessions response", zap.Error(err))
+	}
+}
+
+// @Summary Change my password
+// @Description Checks the current password and sets a new one. The new password needs 8 characters with an uppercase letter, a number and a special character, and must differ from the current one by at least 2 characters. Every other session of the user and every bearer token are revoked; this session stays logged in.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param password body models.PasswordChangePayload true "Current and new password"
+// @Success 200 {object} map[string]interface{} "message and number of revoked sessions"
+// @Failure 400 {object} map[string]string "Invalid input or password too weak"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {object} map[string]string "Current password is incorrect"
+// @Failure 429 {object} map[string]interface{} "Too many attempts or account locked, see Retry-After"
+// @Router /me/password [post]
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+	sessionToken, _ := session.Values["session_token"].(string)
+
+	var payload models.PasswordChangePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.CurrentPassword == "" || payload.NewPassword == "" {
+		logger.Warn("Invalid password change payload", zap.Error(err))
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	revoked, err := h.AuthService.ChangePassword(username, payload.CurrentPassword, payload.NewPassword, sessionToken, utils.ClientIP(r))
+	if err != nil {
+		var limitErr *services.LoginLimitError
+		switch {
+		case errors.As(err, &limitErr):
+			writeLoginLimitError(w, limitErr)
+		case errors.Is(err, services.ErrWrongPassword):
+			writeJSONMessage(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrInvalidPassword):
+			writeJSONMessage(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.Error("Failed to change password", zap.String("username", username), zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"message": "Password changed", "revoked": revoked}); err != nil {
+		logger.Warn("Failed to encode password change response", zap.Error(err))
+	}
+}
+
+// @Summary Revoke the sessions of a user
+// @Description Logs the user out everywhere. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]int "Number of revoked sessions"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /admin/users/{username}/sessions [delete]
+func (h *AuthHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	revoked, err := h.AuthService.RevokeSessions(mux.Vars(r)["username"])
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			writeJSONMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("Failed to revoke sessions", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int{"revoked": revoked}); err != nil {
+		logger.Warn("Failed to encode revoke response", zap.Error(err))
+	}
+}
+
+// @Summary Unlock a user
+// @Description Lifts the lockout and the delays that failed logins put on the user. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]interface{} "Whether the user was locked"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /admin/users/{username}/lockout [delete]
+func (h *AuthHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	admin, _ := session.Values["username"].(string)
+
+	wasLocked, err := h.AuthService.UnlockUser(mux.Vars(r)["username"], admin)
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			writeJSONMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("Failed to unlock user", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"message": "User unlocked", "was_locked": wasLocked}); err != nil {
+		logger.Warn("Failed to encode unlock response", zap.Error(err))
+	}
+}
+
+// defaultAuditLimit is how many audit events are listed without a limit.
+const defaultAuditLimit = 100
+
+// @Summary List audit events
+// @Description Lists the latest login, throttling, lockout and unlock events, the newest first. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param username query string false "Only events about this user"
+// @Param limit query int false "Number of events, 100 by default"
+// @Success 200 {array} models.AuditEvent "Events"
+// @Failure 400 {object} map[string]string "Invalid limit"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/audit [get]
+func (h *AuthHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	values := r.URL.Query()
+
+	limit := defaultAuditLimit
+	if value := values.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeJSONMessage(w, http.StatusBadRequest, services.ErrInvalidLimit.Error())
+			return
+		}
+		limit = parsed
+	}
+
+	events := h.AuthService.ListAuditEvents(values.Get("username"), limit)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		logger.Warn("Failed to encode audit events", zap.Error(err))
+	}
+}
+
+// writeLoginLimitError tells a throttled or locked out client when to try again.
+func writeLoginLimitError(w http.ResponseWriter, limitErr *services.LoginLimitError) {
+	utils.GetLogger().Sugar().Warn("Login refused", zap.Error(limitErr))
+	w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(limitErr.RetryAt).Seconds())+1))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  limitErr.Rule.Error(),
+		"retry_at": limitErr.RetryAt,
+	})
+}
+
+// clearSessionCookie empties the session cookie and tells the browser to drop it.
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	session, err := utils.SessionStore.Get(r, "quiz-session")
+	if err != nil {
+		return
+	}
+	session.Values = make(map[interface{}]interface{})
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to clear session cookie", zap.Error(err))
+	}
+}
@@ -4,11 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/Dzsodie/quiz_app/config"
+	"github.com/Dzsodie/quiz_app/internal/database"
 	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -32,6 +40,42 @@ func (m *MockAuthService) AuthenticateUser(username, password string) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthService) Login(username, password, ip string) error {
+	args := m.Called(username, password, ip)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Logout(sessionToken string) {
+	m.Called(sessionToken)
+}
+
+func (m *MockAuthService) RevokeSessions(username string) (int, error) {
+	args := m.Called(username)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) ListSessions(username string) ([]models.Session, error) {
+	args := m.Called(username)
+	sessions, _ := args.Get(0).([]models.Session)
+	return sessions, args.Error(1)
+}
+
+func (m *MockAuthService) ChangePassword(username, currentPassword, newPassword, keepSessionToken, ip string) (int, error) {
+	args := m.Called(username, currentPassword, newPassword, keepSessionToken, ip)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) UnlockUser(username, admin string) (bool, error) {
+	args := m.Called(username, admin)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthService) ListAuditEvents(username string, limit int) []models.AuditEvent {
+	args := m.Called(username, limit)
+	events, _ := args.Get(0).([]models.AuditEvent)
+	return events
+}
+
 func TestRegisterUserHandler(t *testing.T) {
 	mockService := new(MockAuthService)
 	authHandler := NewAuthHandler(mockService)
@@ -91,3 +135,187 @@ func TestRegisterUserHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestLogoutHandler(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	mockService.On("Logout", "token-1").Return()
+
+	utils.InitializeSessionStore(config.LoadConfig())
+	login := httptest.NewRequest(http.MethodPost, "/login", nil)
+	loginRR := httptest.NewRecorder()
+	session, _ := utils.SessionStore.Get(login, "quiz-session")
+	session.Values["username"] = "testuser"
+	session.Values["session_token"] = "token-1"
+	assert.NoError(t, session.Save(login, loginRR))
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	for _, cookie := range loginRR.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	rr := httptest.NewRecorder()
+	authHandler.Logout(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+	cookies := rr.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "quiz-session", cookies[0].Name)
+		assert.Negative(t, cookies[0].MaxAge, "expected the session cookie to be deleted")
+	}
+
+	rr = httptest.NewRecorder()
+	authHandler.Logout(rr, newSessionRequest(t, http.MethodPost, "/logout", nil, "testuser"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "expected a logout without a cookie session to be refused")
+	assert.Contains(t, rr.Body.String(), "/token/revoke")
+	mockService.AssertNumberOfCalls(t, "Logout", 1)
+}
+
+func TestRevokeUserSessionsHandler(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	mockService.On("RevokeSessions", "testuser").Return(2, nil)
+	mockService.On("RevokeSessions", "nobody").Return(0, fmt.Errorf("user not found: %w", database.ErrUserNotFound))
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/users/testuser/sessions", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "testuser"})
+	rr := httptest.NewRecorder()
+	authHandler.RevokeUserSessions(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"revoked":2}`, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodDelete, "/admin/users/nobody/sessions", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "nobody"})
+	rr = httptest.NewRecorder()
+	authHandler.RevokeUserSessions(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestListSessionsHandler(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	mockService.On("ListSessions", "testuser").Return([]models.Session{
+		{SessionID: "s1", Token: "token-1", Username: "testuser", UserAgent: "Phone"},
+		{SessionID: "s2", Token: "token-2", Username: "testuser", UserAgent: "Laptop"},
+	}, nil)
+
+	utils.InitializeSessionStore(config.LoadConfig())
+	login := httptest.NewRequest(http.MethodPost, "/login", nil)
+	loginRR := httptest.NewRecorder()
+	session, _ := utils.SessionStore.Get(login, "quiz-session")
+	session.Values["username"] = "testuser"
+	session.Values["session_token"] = "token-2"
+	assert.NoError(t, session.Save(login, loginRR))
+
+	req := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+	for _, cookie := range loginRR.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	rr := httptest.NewRecorder()
+	authHandler.ListSessions(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "token-", "expected the session tokens to stay secret")
+	var sessions []models.Session
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&sessions))
+	if assert.Len(t, sessions, 2) {
+		assert.False(t, sessions[0].Current)
+		assert.True(t, sessions[1].Current, "expected the session of the request to be marked")
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{"Changed", `{"current_password":"Old@1234","new_password":"New@12345"}`, nil, http.StatusOK},
+		{"Wrong current password", `{"current_password":"Old@1234","new_password":"New@12345"}`, services.ErrWrongPassword, http.StatusForbidden},
+		{"Too weak", `{"current_password":"Old@1234","new_password":"New@12345"}`, fmt.Errorf("%w: password too short", services.ErrInvalidPassword), http.StatusBadRequest},
+		{"Missing new password", `{"current_password":"Old@1234"}`, nil, http.StatusBadRequest},
+		{"Throttled", `{"current_password":"Old@1234","new_password":"New@12345"}`, &services.LoginLimitError{Rule: services.ErrLoginRateLimited, RetryAt: time.Now().Add(time.Minute)}, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			authHandler := NewAuthHandler(mockService)
+			mockService.On("ChangePassword", "testuser", "Old@1234", "New@12345", "", "192.0.2.1").Return(2, tt.err)
+
+			req := newSessionRequest(t, http.MethodPost, "/me/password", bytes.NewBufferString(tt.body), "testuser")
+			rr := httptest.NewRecorder()
+			authHandler.ChangePassword(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, `{"message":"Password changed","revoked":2}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoginUserRefused(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	retryAt := time.Now().Add(90 * time.Second)
+	mockService.On("Login", "testuser", "wrong", "192.0.2.1").Return(errors.New("invalid username or password"))
+	mockService.On("Login", "locked", "wrong", "192.0.2.1").Return(&services.LoginLimitError{Rule: services.ErrAccountLocked, RetryAt: retryAt})
+
+	body, _ := json.Marshal(models.User{Username: "testuser", Password: "wrong"})
+	rr := httptest.NewRecorder()
+	authHandler.LoginUser(rr, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	body, _ = json.Marshal(models.User{Username: "locked", Password: "wrong"})
+	rr = httptest.NewRecorder()
+	authHandler.LoginUser(rr, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 90, retryAfter, 2)
+	assert.Contains(t, rr.Body.String(), services.ErrAccountLocked.Error())
+}
+
+func TestUnlockUserHandler(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	mockService.On("UnlockUser", "testuser", "admin").Return(true, nil)
+	mockService.On("UnlockUser", "nobody", "admin").Return(false, fmt.Errorf("user not found: %w", database.ErrUserNotFound))
+
+	req := newSessionRequest(t, http.MethodDelete, "/admin/users/testuser/lockout", nil, "admin")
+	req = mux.SetURLVars(req, map[string]string{"username": "testuser"})
+	rr := httptest.NewRecorder()
+	authHandler.UnlockUser(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"message":"User unlocked","was_locked":true}`, rr.Body.String())
+
+	req = newSessionRequest(t, http.MethodDelete, "/admin/users/nobody/lockout", nil, "admin")
+	req = mux.SetURLVars(req, map[string]string{"username": "nobody"})
+	rr = httptest.NewRecorder()
+	authHandler.UnlockUser(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestListAuditEventsHandler(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	events := []models.AuditEvent{{EventID: "e1", Type: models.AuditLoginFailed, Username: "testuser", IP: "192.0.2.1"}}
+	mockService.On("ListAuditEvents", "testuser", 5).Return(events)
+	mockService.On("ListAuditEvents", "", 100).Return([]models.AuditEvent{})
+
+	rr := httptest.NewRecorder()
+	authHandler.ListAuditEvents(rr, httptest.NewRequest(http.MethodGet, "/admin/audit?username=testuser&limit=5", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var got []models.AuditEvent
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, "e1", got[0].EventID)
+
+	rr = httptest.NewRecorder()
+	authHandler.ListAuditEvents(rr, httptest.NewRequest(http.MethodGet, "/admin/audit", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[]`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	authHandler.ListAuditEvents(rr, httptest.NewRequest(http.MethodGet, "/admin/audit?limit=0", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
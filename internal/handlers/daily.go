@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type DailyHandler struct {
+	DailyService services.IDailyService
+}
+
+func NewDailyHandler(dailyService services.IDailyService) *DailyHandler {
+	return &DailyHandler{DailyService: dailyService}
+}
+
+// GetDaily returns the daily challenge of today
+// @Summary Get the daily challenge
+// @Description Returns the quiz of the day, the same for every player. Start it through /quiz/start with its quiz_id, it can be played once and ranks on /leaderboard?quiz=<quiz_id>.
+// @Tags Daily
+// @Produce json
+// @Success 200 {object} models.DailyChallenge "Daily challenge"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 503 {object} map[string]string "No questions to generate the challenge from"
+// @Failure 500 {string} string "Internal server error"
+// @Router /daily [get]
+func (h *DailyHandler) GetDaily(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	challenge, err := h.DailyService.Today()
+	if err != nil {
+		if errors.Is(err, services.ErrNoQuestionsForDaily) {
+			writeJSONMessage(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		logger.Error("Failed to get daily challenge", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(challenge); err != nil {
+		logger.Warn("Failed to encode daily challenge response", zap.Error(err))
+	}
+}
+
+// GetDailyHistory lists the past daily challenges
+// @Summary Browse daily challenges
+// @Description Lists every daily challenge generated so far, the latest first
+// @Tags Daily
+// @Produce json
+// @Success 200 {array} models.DailyChallenge "Daily challenges"
+// @Failure 401 {string} string "Invalid session"
+// @Router /daily/history [get]
+func (h *DailyHandler) GetDailyHistory(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.DailyService.History()); err != nil {
+		logger.Warn("Failed to encode daily history response", zap.Error(err))
+	}
+}
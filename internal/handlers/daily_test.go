@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDailyService is a mock implementation of the IDailyService interface.
+type MockDailyService struct {
+	mock.Mock
+}
+
+func (m *MockDailyService) Today() (*models.DailyChallenge, error) {
+	args := m.Called()
+	challenge, _ := args.Get(0).(*models.DailyChallenge)
+	return challenge, args.Error(1)
+}
+
+func (m *MockDailyService) History() []models.DailyChallenge {
+	args := m.Called()
+	return args.Get(0).([]models.DailyChallenge)
+}
+
+func TestGetDaily(t *testing.T) {
+	mockService := new(MockDailyService)
+	handler := NewDailyHandler(mockService)
+	mockService.On("Today").Return(&models.DailyChallenge{Date: "2025-01-10", QuizID: "daily-2025-01-10", QuestionIDs: []int{3, 1}}, nil).Once()
+
+	req := newSessionRequest(t, http.MethodGet, "/daily", nil, "testuser")
+	rr := httptest.NewRecorder()
+	handler.GetDaily(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"quiz_id":"daily-2025-01-10"`)
+
+	mockService.On("Today").Return(nil, services.ErrNoQuestionsForDaily).Once()
+	rr = httptest.NewRecorder()
+	handler.GetDaily(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func TestGetDailyHistory(t *testing.T) {
+	mockService := new(MockDailyService)
+	handler := NewDailyHandler(mockService)
+	mockService.On("History").Return([]models.DailyChallenge{{Date: "2025-01-10"}, {Date: "2025-01-09"}})
+
+	req := newSessionRequest(t, http.MethodGet, "/daily/history", nil, "testuser")
+	rr := httptest.NewRecorder()
+	handler.GetDailyHistory(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"date":"2025-01-09"`)
+	mockService.AssertExpectations(t)
+}
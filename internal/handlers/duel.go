@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type DuelHandler struct {
+	DuelService services.IDuelService
+}
+
+func NewDuelHandler(duelService services.IDuelService) *DuelHandler {
+	return &DuelHandler{DuelService: duelService}
+}
+
+// Challenge invites another player to a duel
+// @Summary Challenge a player to a duel
+// @Description Sends a duel invitation on a random question set. Once accepted both players start it through /quiz/start with its quiz_id.
+// @Tags Duels
+// @Accept json
+// @Produce json
+// @Param challenge body models.DuelChallengePayload true "Opponent, sync or async mode and number of questions"
+// @Success 201 {object} models.Duel "Pending duel"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Opponent not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /duels [post]
+func (h *DuelHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	var payload models.DuelChallengePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Opponent == "" {
+		logger.Warn("Invalid duel challenge", zap.Error(err))
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	duel, err := h.DuelService.Challenge(username, payload)
+	if err != nil {
+		writeDuelError(w, err)
+		return
+	}
+	writeDuel(w, http.StatusCreated, duel)
+}
+
+// ListDuels lists the duels of the user
+// @Summary List my duels
+// @Description Lists the duels the logged-in user sent or received, the latest first
+// @Tags Duels
+// @Produce json
+// @Success 200 {array} models.Duel "Duels"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /duels [get]
+func (h *DuelHandler) ListDuels(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	duels, err := h.DuelService.ListDuels(username)
+	if err != nil {
+		logger.Error("Failed to list duels", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(duels); err != nil {
+		logger.Warn("Failed to encode duels response", zap.Error(err))
+	}
+}
+
+// GetDuel returns a duel of the user
+// @Summary Get a duel
+// @Description Returns the duel with the results and rating changes once it is settled
+// @Tags Duels
+// @Produce json
+// @Param id path string true "Duel ID"
+// @Success 200 {object} models.Duel "Duel"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Duel not found"
+// @Router /duels/{id} [get]
+func (h *DuelHandler) GetDuel(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	duel, err := h.DuelService.GetDuel(mux.Vars(r)["id"], username)
+	if err != nil {
+		writeDuelError(w, err)
+		return
+	}
+	writeDuel(w, http.StatusOK, duel)
+}
+
+// AcceptDuel accepts a duel invitation
+// @Summary Accept a duel
+// @Tags Duels
+// @Produce json
+// @Param id path string true "Duel ID"
+// @Success 200 {object} models.Duel "Active duel"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {object} map[string]string "Not the challenged player"
+// @Failure 404 {object} map[string]string "Duel not found"
+// @Failure 409 {object} map[string]string "Duel no longer pending"
+// @Router /duels/{id}/accept [post]
+func (h *DuelHandler) AcceptDuel(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, true)
+}
+
+// DeclineDuel declines a duel invitation
+// @Summary Decline a duel
+// @Tags Duels
+// @Produce json
+// @Param id path string true "Duel ID"
+// @Success 200 {object} models.Duel "Declined duel"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {object} map[string]string "Not the challenged player"
+// @Failure 404 {object} map[string]string "Duel not found"
+// @Failure 409 {object} map[string]string "Duel no longer pending"
+// @Router /duels/{id}/decline [post]
+func (h *DuelHandler) DeclineDuel(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, false)
+}
+
+func (h *DuelHandler) respond(w http.ResponseWriter, r *http.Request, accept bool) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	duel, err := h.DuelService.Respond(mux.Vars(r)["id"], username, accept)
+	if err != nil {
+		writeDuelError(w, err)
+		return
+	}
+	writeDuel(w, http.StatusOK, duel)
+}
+
+func writeDuel(w http.ResponseWriter, status int, duel *models.Duel) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(duel); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to encode duel response", zap.Error(err))
+	}
+}
+
+// writeDuelError maps duel errors to HTTP status codes.
+func writeDuelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrDuelSelf), errors.Is(err, services.ErrInvalidDuelMode):
+		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrDuelNotFound), errors.Is(err, database.ErrUserNotFound):
+		writeJSONMessage(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrNotDuelOpponent):
+		writeJSONMessage(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrDuelNotPending):
+		writeJSONMessage(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrNoQuestionsToDuel):
+		writeJSONMessage(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("Duel request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
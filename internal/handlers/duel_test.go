@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDuelService is a mock implementation of the IDuelService interface.
+type MockDuelService struct {
+	mock.Mock
+}
+
+func (m *MockDuelService) Challenge(challenger string, payload models.DuelChallengePayload) (*models.Duel, error) {
+	args := m.Called(challenger, payload)
+	duel, _ := args.Get(0).(*models.Duel)
+	return duel, args.Error(1)
+}
+
+func (m *MockDuelService) Respond(duelID, username string, accept bool) (*models.Duel, error) {
+	args := m.Called(duelID, username, accept)
+	duel, _ := args.Get(0).(*models.Duel)
+	return duel, args.Error(1)
+}
+
+func (m *MockDuelService) GetDuel(duelID, username string) (*models.Duel, error) {
+	args := m.Called(duelID, username)
+	duel, _ := args.Get(0).(*models.Duel)
+	return duel, args.Error(1)
+}
+
+func (m *MockDuelService) ListDuels(username string) ([]models.Duel, error) {
+	args := m.Called(username)
+	duels, _ := args.Get(0).([]models.Duel)
+	return duels, args.Error(1)
+}
+
+func TestChallengeDuel(t *testing.T) {
+	mockService := new(MockDuelService)
+	handler := NewDuelHandler(mockService)
+	mockService.On("Challenge", "alice", models.DuelChallengePayload{Opponent: "bob", Mode: models.DuelSync}).
+		Return(&models.Duel{DuelID: "d1", Challenger: "alice", Opponent: "bob", Status: models.DuelPending}, nil)
+	mockService.On("Challenge", "alice", models.DuelChallengePayload{Opponent: "alice"}).Return(nil, services.ErrDuelSelf)
+
+	req := newSessionRequest(t, http.MethodPost, "/duels", strings.NewReader(`{"opponent":"bob","mode":"sync"}`), "alice")
+	rr := httptest.NewRecorder()
+	handler.Challenge(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"pending"`)
+
+	req = newSessionRequest(t, http.MethodPost, "/duels", strings.NewReader(`{"opponent":"alice"}`), "alice")
+	rr = httptest.NewRecorder()
+	handler.Challenge(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = newSessionRequest(t, http.MethodPost, "/duels", strings.NewReader(`{}`), "alice")
+	rr = httptest.NewRecorder()
+	handler.Challenge(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestRespondDuel(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"Accepted", nil, http.StatusOK},
+		{"Not the opponent", services.ErrNotDuelOpponent, http.StatusForbidden},
+		{"No longer pending", services.ErrDuelNotPending, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockDuelService)
+			handler := NewDuelHandler(mockService)
+			var duel *models.Duel
+			if tt.err == nil {
+				duel = &models.Duel{DuelID: "d1", Status: models.DuelActive}
+			}
+			mockService.On("Respond", "d1", "bob", true).Return(duel, tt.err)
+
+			req := newSessionRequest(t, http.MethodPost, "/duels/d1/accept", nil, "bob")
+			req = mux.SetURLVars(req, map[string]string{"id": "d1"})
+			rr := httptest.NewRecorder()
+			handler.AcceptDuel(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type LeaderboardHandler struct {
+	LeaderboardService services.ILeaderboardService
+}
+
+func NewLeaderboardHandler(leaderboardService services.ILeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{LeaderboardService: leaderboardService}
+}
+
+// GetLeaderboard returns a page of the leaderboard
+// @Summary Get the leaderboard
+// @Description Ranks the players by their completed ranked attempts. Tied players share a rank. The caller's own rank and percentile are returned as "me".
+// @Tags Leaderboard
+// @Produce json
+// @Param quiz query string false "Quiz ID, all quizzes when empty"
+// @Param period query string false "all, today, week, month, 24h, 7d or 30d" default(all)
+// @Param limit query int false "Page size, at most 100" default(10)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} models.Leaderboard "Leaderboard page"
+// @Failure 400 {object} map[string]string "Invalid query"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Quiz not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /leaderboard [get]
+func (h *LeaderboardHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	values := r.URL.Query()
+	query := models.LeaderboardQuery{
+		QuizID: values.Get("quiz"),
+		Period: models.LeaderboardPeriod(values.Get("period")),
+		Cursor: values.Get("cursor"),
+	}
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			writeJSONMessage(w, http.StatusBadRequest, services.ErrInvalidLimit.Error())
+			return
+		}
+		query.Limit = parsed
+	}
+
+	leaderboard, err := h.LeaderboardService.GetLeaderboard(username, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPeriod),
+			errors.Is(err, services.ErrInvalidLimit),
+			errors.Is(err, services.ErrInvalidCursor):
+			logger.Warn("Invalid leaderboard query", zap.Error(err))
+			writeJSONMessage(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrQuizNotFound):
+			writeJSONMessage(w, http.StatusNotFound, err.Error())
+		default:
+			logger.Error("Failed to compute leaderboard", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(leaderboard); err != nil {
+		logger.Warn("Failed to encode leaderboard response", zap.Error(err))
+	}
+}
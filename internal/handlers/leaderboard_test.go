@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLeaderboardService is a mock implementation of the ILeaderboardService interface.
+type MockLeaderboardService struct {
+	mock.Mock
+}
+
+func (m *MockLeaderboardService) GetLeaderboard(username string, query models.LeaderboardQuery) (*models.Leaderboard, error) {
+	args := m.Called(username, query)
+	leaderboard, _ := args.Get(0).(*models.Leaderboard)
+	return leaderboard, args.Error(1)
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		query          models.LeaderboardQuery
+		leaderboard    *models.Leaderboard
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Page of the leaderboard",
+			target:         "/leaderboard?quiz=exam&period=7d&limit=2&cursor=2",
+			query:          models.LeaderboardQuery{QuizID: "exam", Period: models.PeriodLast7Days, Limit: 2, Cursor: "2"},
+			leaderboard:    &models.Leaderboard{QuizID: "exam", Period: models.PeriodLast7Days, Total: 1, Entries: []models.LeaderboardEntry{{Rank: 1, Username: "testuser", Score: 3}}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid period",
+			target:         "/leaderboard?period=forever",
+			query:          models.LeaderboardQuery{Period: "forever"},
+			err:            services.ErrInvalidPeriod,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown quiz",
+			target:         "/leaderboard?quiz=unknown",
+			query:          models.LeaderboardQuery{QuizID: "unknown"},
+			err:            database.ErrQuizNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockLeaderboardService)
+			handler := NewLeaderboardHandler(mockService)
+			mockService.On("GetLeaderboard", "testuser", tt.query).Return(tt.leaderboard, tt.err)
+
+			req := newSessionRequest(t, http.MethodGet, tt.target, nil, "testuser")
+			rr := httptest.NewRecorder()
+			handler.GetLeaderboard(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		handler := NewLeaderboardHandler(new(MockLeaderboardService))
+		req := newSessionRequest(t, http.MethodGet, "/leaderboard?limit=ten", nil, "testuser")
+		rr := httptest.NewRecorder()
+		handler.GetLeaderboard(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
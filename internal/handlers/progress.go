@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type ProgressHandler struct {
+	ProgressService services.IProgressService
+}
+
+func NewProgressHandler(progressService services.IProgressService) *ProgressHandler {
+	return &ProgressHandler{ProgressService: progressService}
+}
+
+// GetProgress returns the progress dashboard data of the user
+// @Summary Get my progress
+// @Description Returns the attempts over time, the accuracy per category and tag, the average answer time, the current streaks and the weakest topics of the logged-in user
+// @Tags Me
+// @Produce json
+// @Success 200 {object} models.Progress "Progress of the user"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /me/progress [get]
+func (h *ProgressHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	progress, err := h.ProgressService.GetProgress(username)
+	if err != nil {
+		logger.Error("Failed to retrieve progress", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(progress); err != nil {
+		logger.Warn("Failed to encode progress response", zap.Error(err))
+	}
+}
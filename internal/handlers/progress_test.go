@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProgressService is a mock implementation of the IProgressService interface.
+type MockProgressService struct {
+	mock.Mock
+}
+
+func (m *MockProgressService) GetProgress(username string) (*models.Progress, error) {
+	args := m.Called(username)
+	progress, _ := args.Get(0).(*models.Progress)
+	return progress, args.Error(1)
+}
+
+func TestGetProgress(t *testing.T) {
+	mockService := new(MockProgressService)
+	handler := NewProgressHandler(mockService)
+	mockService.On("GetProgress", "testuser").Return(&models.Progress{Username: "testuser", Streaks: models.Streaks{DaysPlayed: 3}}, nil)
+	mockService.On("GetProgress", "nobody").Return(nil, errors.New("user not found"))
+
+	req := newSessionRequest(t, http.MethodGet, "/me/progress", nil, "testuser")
+	rr := httptest.NewRecorder()
+	handler.GetProgress(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"days_played":3`)
+
+	req = newSessionRequest(t, http.MethodGet, "/me/progress", nil, "nobody")
+	rr = httptest.NewRecorder()
+	handler.GetProgress(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"errors"
 
+	"github.com/Dzsodie/quiz_app/internal/database"
 	"github.com/Dzsodie/quiz_app/internal/models"
 	"github.com/Dzsodie/quiz_app/internal/services"
 	"github.com/Dzsodie/quiz_app/internal/utils"
@@ -14,6 +18,8 @@ import (
 
 type QuizHandler struct {
 	QuizService services.IQuizService
+	// Achievements announces new unlocks in answer and results responses when set
+	Achievements services.IAchievementService
 }
 
 func NewQuizHandler(quizService services.IQuizService) *QuizHandler {
@@ -22,7 +28,7 @@ func NewQuizHandler(quizService services.IQuizService) *QuizHandler {
 
 // GetQuestions retrieves all available quiz questions
 // @Summary Get all quiz questions
-// @Description Fetches all quiz questions available in the system
+// @Description Fetches all quiz questions available in the system, without hints and explanations
 // @Tags Quiz
 // @Produce json
 // @Success 200 {array} models.Question "List of questions"
@@ -43,13 +49,34 @@ func (h *QuizHandler) GetQuestions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ListQuizzes lists the quizzes that can be started
+// @Summary List quizzes
+// @Description Lists the available quizzes with their mode and rules
+// @Tags Quiz
+// @Produce json
+// @Success 200 {array} models.Quiz "List of quizzes"
+// @Router /quizzes [get]
+func (h *QuizHandler) ListQuizzes(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.QuizService.ListQuizzes()); err != nil {
+		logger.Warn("Failed to encode quizzes response", zap.Error(err))
+	}
+}
+
 // StartQuiz starts a new quiz session for the user
 // @Summary Start a quiz
-// @Description Initiates a quiz session for the logged-in user
+// @Description Initiates a quiz session for the logged-in user. Without a quiz_id the general quiz is started.
 // @Tags Quiz
+// @Accept json
 // @Produce json
+// @Param payload body models.StartQuizPayload false "Quiz to start"
 // @Success 200 {object} map[string]string "Quiz started and next endpoint"
+// @Failure 400 {object} map[string]string "Invalid input"
 // @Failure 401 {string} string "Invalid session"
+// @Failure 403 {object} map[string]interface{} "Quiz closed, not open yet or out of attempts"
+// @Failure 404 {object} map[string]string "Quiz not found"
+// @Failure 429 {object} map[string]interface{} "Cooldown between attempts"
 // @Failure 500 {string} string "Internal server error"
 // @Router /quiz/start [post]
 func (h *QuizHandler) StartQuiz(w http.ResponseWriter, r *http.Request) {
@@ -61,30 +88,40 @@ func (h *QuizHandler) StartQuiz(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// AuthMiddleware has checked the session token or the bearer token
 	username, ok := session.Values["username"].(string)
-	sessionToken, tokenOk := session.Values["session_token"].(string)
-	if !ok || !tokenOk || username == "" || sessionToken == "" {
-		logger.Warn("Invalid session: missing username or token")
+	if !ok || username == "" {
+		logger.Warn("Invalid session: missing username")
 		http.Error(w, "Invalid session", http.StatusUnauthorized)
 		return
 	}
 
-	storedUsername, exists := utils.SessionDB[sessionToken]
-	if !exists || storedUsername != username {
-		logger.Warn("Session token not found or mismatched",
-			zap.String("session_token", sessionToken),
-			zap.String("stored_username", storedUsername))
-		http.Error(w, "Invalid session", http.StatusUnauthorized)
-		return
+	var payload models.StartQuizPayload
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			logger.Warn("Invalid input for starting a quiz", zap.Error(err))
+			http.Error(w, `{"message":"Invalid input"}`, http.StatusBadRequest)
+			return
+		}
 	}
 
-	if err := h.QuizService.StartQuiz(username); err != nil {
-		logger.Error("Failed to start quiz", zap.String("username", username), zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if err := h.QuizService.StartQuiz(username, payload.QuizID); err != nil {
+		var ruleErr *services.QuizRuleError
+		switch {
+		case errors.Is(err, database.ErrQuizNotFound):
+			logger.Warn("Quiz not found", zap.String("quizID", payload.QuizID))
+			writeJSONMessage(w, http.StatusNotFound, "Quiz not found")
+		case errors.As(err, &ruleErr):
+			logger.Warn("Quiz start blocked", zap.String("username", username), zap.Error(err))
+			writeQuizRuleError(w, ruleErr)
+		default:
+			logger.Error("Failed to start quiz", zap.String("username", username), zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
 		return
 	}
 
-	logger.Info("Quiz started successfully", zap.String("username", username))
+	logger.Info("Quiz started successfully", zap.String("username", username), zap.String("quizID", payload.QuizID))
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]string{
 		"status":        "quiz started",
@@ -108,6 +145,11 @@ func (h *QuizHandler) NextQuestion(w http.ResponseWriter, r *http.Request) {
 
 	question, err := h.QuizService.GetNextQuestion(username)
 	if err != nil {
+		if status, ok := attemptErrorStatus(err); ok && status != http.StatusGone {
+			logger.Warn("Next question not available", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, status, err.Error())
+			return
+		}
 		if err.Error() == "quiz complete" {
 			logger.Info("Quiz complete", zap.String("username", username))
 			w.WriteHeader(http.StatusGone)
@@ -129,13 +171,14 @@ func (h *QuizHandler) NextQuestion(w http.ResponseWriter, r *http.Request) {
 
 // SubmitAnswer submits an answer for the current question
 // @Summary Submit an answer
-// @Description Validates and submits the user's answer to the current question
+// @Description Validates and submits the user's answer to the current question. Practice quizzes answer with feedback and an explanation and allow retries, exams only confirm that the answer was recorded.
 // @Tags Quiz
 // @Accept json
 // @Produce json
 // @Param payload body models.AnswerPayload true "Answer payload"
-// @Success 200 {object} map[string]string "Answer feedback"
-// @Failure 400 {string} string "Invalid input"
+// @Success 200 {object} map[string]interface{} "Answer feedback"
+// @Failure 400 {string} string "Invalid input or question not served"
+// @Failure 409 {object} map[string]string "Question already answered"
 // @Failure 500 {string} string "Internal server error"
 // @Router /quiz/answer [post]
 func (h *QuizHandler) SubmitAnswer(w http.ResponseWriter, r *http.Request) {
@@ -163,15 +206,32 @@ func (h *QuizHandler) SubmitAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	correct, err := h.QuizService.SubmitAnswer(username, payload.QuestionIndex, payload.Answer)
+	result, err := h.QuizService.SubmitAnswer(username, payload.QuestionIndex, payload.Answer)
 	if err != nil {
+		if status, ok := attemptErrorStatus(err); ok {
+			logger.Warn("Answer not accepted", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, status, err.Error())
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrAnswerLocked),
+			errors.Is(err, services.ErrAlreadyAnswered):
+			logger.Warn("Answer not accepted", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, http.StatusConflict, err.Error())
+			return
+		case errors.Is(err, services.ErrQuestionNotServed),
+			errors.Is(err, services.ErrQuestionNotInQuiz):
+			logger.Warn("Answer not accepted", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		logger.Error("Failed to submit answer", zap.String("username", username), zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	message := ""
-	if correct {
+	if result.Correct {
 		message = "Correct answer"
 		logger.Info("Correct answer submitted", zap.String("username", username), zap.Int("questionIndex", payload.QuestionIndex))
 	} else {
@@ -179,17 +239,168 @@ func (h *QuizHandler) SubmitAnswer(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Wrong answer submitted", zap.String("username", username), zap.Int("questionIndex", payload.QuestionIndex))
 	}
 
-	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+	response := map[string]interface{}{"message": message}
+	switch result.Mode {
+	case models.QuizModeExam:
+		// No feedback until the exam is complete
+		response = map[string]interface{}{"message": "Answer recorded"}
+	case models.QuizModePractice:
+		response["correct"] = result.Correct
+		response["can_retry"] = result.CanRetry
+		if result.Explanation != "" {
+			response["explanation"] = result.Explanation
+		}
+	}
+	// Unlocks during an exam would give away answers, they are announced with the results
+	if result.Mode != models.QuizModeExam {
+		announceUnlocks(h.Achievements, username, response)
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		logger.Warn("Failed to encode answer response", zap.Error(err))
 	}
 }
 
+// UseLifeline spends one of the player's lifelines on a served question
+// @Summary Use a lifeline
+// @Description Reveals the hint of a question or removes wrong options (50/50). Each use costs points on the question.
+// @Tags Quiz
+// @Accept json
+// @Produce json
+// @Param payload body models.LifelinePayload true "Lifeline payload"
+// @Success 200 {object} models.LifelineResult "Lifeline result"
+// @Failure 400 {object} map[string]string "Invalid input or lifeline not available"
+// @Failure 409 {object} map[string]string "No lifelines left or quiz not started"
+// @Failure 500 {string} string "Internal server error"
+// @Router /quiz/lifeline [post]
+func (h *QuizHandler) UseLifeline(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	var payload models.LifelinePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logger.Warn("Invalid input for lifeline", zap.Error(err))
+		http.Error(w, `{"message":"Invalid input"}`, http.StatusBadRequest)
+		return
+	}
+
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	result, err := h.QuizService.UseLifeline(username, payload.QuestionIndex, payload.Type)
+	if err != nil {
+		if status, ok := attemptErrorStatus(err); ok {
+			logger.Warn("Lifeline rejected", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, status, err.Error())
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrNoLifelinesLeft),
+			errors.Is(err, services.ErrLifelineAlreadyUsed):
+			logger.Warn("Lifeline rejected", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrUnknownLifeline),
+			errors.Is(err, services.ErrLifelineNotAvailable),
+			errors.Is(err, services.ErrQuestionNotServed),
+			err.Error() == "question index is out of range":
+			logger.Warn("Invalid lifeline request", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.Error("Failed to use lifeline", zap.String("username", username), zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	logger.Info("Lifeline used successfully", zap.String("username", username), zap.String("lifeline", string(payload.Type)))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Warn("Failed to encode lifeline response", zap.Error(err))
+	}
+}
+
+// PauseQuiz pauses the ongoing quiz attempt of the user
+// @Summary Pause the quiz
+// @Description Pauses the ongoing attempt. The time limit is paused as well when the quiz allows it.
+// @Tags Quiz
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Quiz paused and resume endpoint"
+// @Failure 409 {object} map[string]string "No quiz in progress"
+// @Failure 500 {string} string "Internal server error"
+// @Router /quiz/pause [post]
+func (h *QuizHandler) PauseQuiz(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	attempt, err := h.QuizService.PauseQuiz(username)
+	if err != nil {
+		if status, ok := attemptErrorStatus(err); ok {
+			logger.Warn("Quiz could not be paused", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, status, err.Error())
+			return
+		}
+		logger.Error("Failed to pause quiz", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Quiz paused successfully", zap.String("username", username))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":                 "quiz paused",
+		"attempt_id":             attempt.AttemptID,
+		"time_remaining_seconds": int(attempt.TimeRemaining.Seconds()),
+		"resume_endpoint":        "/quiz/resume",
+	}); err != nil {
+		logger.Warn("Failed to encode pause response", zap.Error(err))
+	}
+}
+
+// ResumeQuiz resumes the paused quiz attempt of the user
+// @Summary Resume the quiz
+// @Description Resumes the paused attempt from any session and returns the question to answer next
+// @Tags Quiz
+// @Produce json
+// @Success 200 {object} models.Question "Question to answer next"
+// @Failure 404 {object} map[string]string "No paused quiz"
+// @Failure 409 {object} map[string]string "Quiz expired"
+// @Failure 410 {object} map[string]string "Quiz complete"
+// @Failure 500 {string} string "Internal server error"
+// @Router /quiz/resume [post]
+func (h *QuizHandler) ResumeQuiz(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	question, err := h.QuizService.ResumeQuiz(username)
+	if err != nil {
+		if errors.Is(err, services.ErrNothingToResume) {
+			writeJSONMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if status, ok := attemptErrorStatus(err); ok {
+			logger.Warn("Quiz could not be resumed", zap.String("username", username), zap.Error(err))
+			writeJSONMessage(w, status, err.Error())
+			return
+		}
+		logger.Error("Failed to resume quiz", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Quiz resumed successfully", zap.String("username", username))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(question); err != nil {
+		logger.Warn("Failed to encode question response", zap.Error(err))
+	}
+}
+
 // GetResults retrieves the quiz results for the user
 // @Summary Get quiz results
-// @Description Fetches the quiz results for the logged-in user
+// @Description Fetches the quiz results for the logged-in user. Exams show their results, with a review of every answer, once they are complete.
 // @Tags Quiz
 // @Produce json
-// @Success 200 {object} map[string]int "Quiz score"
+// @Success 200 {object} map[string]interface{} "Quiz score"
+// @Failure 409 {object} map[string]string "Exam still in progress"
 // @Failure 500 {string} string "Internal server error"
 // @Router /quiz/results [get]
 func (h *QuizHandler) GetResults(w http.ResponseWriter, r *http.Request) {
@@ -197,14 +408,38 @@ func (h *QuizHandler) GetResults(w http.ResponseWriter, r *http.Request) {
 	session, _ := utils.SessionStore.Get(r, "quiz-session")
 	username, _ := session.Values["username"].(string)
 
+	response := map[string]interface{}{}
+	attempt, err := h.QuizService.GetLatestAttempt(username)
+	if err == nil && attempt.Mode == models.QuizModeExam {
+		if attempt.Status != models.AttemptCompleted && attempt.Status != models.AttemptExpired {
+			logger.Info("Exam results requested before completion", zap.String("username", username))
+			writeJSONMessage(w, http.StatusConflict, "Results are available when the exam is complete")
+			return
+		}
+		response["review"] = examReview(attempt)
+	}
+
 	score, err := h.QuizService.GetResults(username)
 	if err != nil {
 		logger.Error("Failed to retrieve quiz results", zap.String("username", username), zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	response["score"] = score
+	if attempt != nil && attempt.Strategy == models.StrategyAdaptive {
+		response["ability"] = attempt.Ability
+	}
+	if attempt != nil && attempt.Status == models.AttemptCompleted {
+		response["xp_earned"] = attempt.XPEarned
+	}
+	if attempt != nil && attempt.Ranked {
+		if counted, err := h.QuizService.GetCountedScore(username, attempt.QuizID); err == nil {
+			response["counted_score"] = counted
+		}
+	}
+	announceUnlocks(h.Achievements, username, response)
 	logger.Info("Quiz results retrieved successfully", zap.String("username", username), zap.Int("score", score))
-	if err := json.NewEncoder(w).Encode(map[string]int{"score": score}); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		logger.Warn("Failed to encode results response", zap.Error(err))
 	}
 }
@@ -257,3 +492,55 @@ func (h *QuizHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 		logger.Warn("Failed to encode statistics response", zap.Error(err))
 	}
 }
+
+func writeJSONMessage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// attemptErrorStatus maps attempt lifecycle errors to HTTP status codes.
+func attemptErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrQuizComplete):
+		return http.StatusGone, true
+	case errors.Is(err, services.ErrNoActiveAttempt),
+		errors.Is(err, services.ErrAttemptPaused),
+		errors.Is(err, services.ErrAttemptExpired):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
+
+// examReview lists every answer of a finished exam and whether it was right.
+func examReview(attempt *models.Attempt) []map[string]interface{} {
+	review := make([]map[string]interface{}, 0, len(attempt.Answers))
+	for _, record := range attempt.Answers {
+		review = append(review, map[string]interface{}{
+			"question_id": record.QuestionID,
+			"answer":      record.Answer,
+			"correct":     record.Correct,
+			"skipped":     record.Skipped,
+		})
+	}
+	return review
+}
+
+// writeQuizRuleError explains which rule blocks a quiz and when it can be tried again.
+func writeQuizRuleError(w http.ResponseWriter, ruleErr *services.QuizRuleError) {
+	status := http.StatusForbidden
+	response := map[string]interface{}{
+		"message": ruleErr.Error(),
+		"quiz_id": ruleErr.QuizID,
+	}
+	if !ruleErr.RetryAt.IsZero() {
+		response["retry_at"] = ruleErr.RetryAt
+	}
+	if errors.Is(ruleErr, services.ErrCooldownActive) {
+		status = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(ruleErr.RetryAt).Seconds())+1))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
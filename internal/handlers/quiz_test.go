@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Dzsodie/quiz_app/config"
 	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,8 +29,13 @@ func (m *MockQuizService) LoadQuestions(questions []models.Question) {
 	m.Called(questions)
 }
 
-func (m *MockQuizService) StartQuiz(username string) error {
-	args := m.Called(username)
+func (m *MockQuizService) ListQuizzes() []models.Quiz {
+	args := m.Called()
+	return args.Get(0).([]models.Quiz)
+}
+
+func (m *MockQuizService) StartQuiz(username, quizID string) error {
+	args := m.Called(username, quizID)
 	return args.Error(0)
 }
 
@@ -35,9 +44,28 @@ func (m *MockQuizService) GetNextQuestion(username string) (*models.Question, er
 	return args.Get(0).(*models.Question), args.Error(1)
 }
 
-func (m *MockQuizService) SubmitAnswer(username string, questionIndex int, answer int) (bool, error) {
+func (m *MockQuizService) SubmitAnswer(username string, questionIndex int, answer int) (*models.AnswerResult, error) {
 	args := m.Called(username, questionIndex, answer)
-	return args.Bool(0), args.Error(1)
+	result, _ := args.Get(0).(*models.AnswerResult)
+	return result, args.Error(1)
+}
+
+func (m *MockQuizService) UseLifeline(username string, questionIndex int, lifeline models.LifelineType) (*models.LifelineResult, error) {
+	args := m.Called(username, questionIndex, lifeline)
+	result, _ := args.Get(0).(*models.LifelineResult)
+	return result, args.Error(1)
+}
+
+func (m *MockQuizService) PauseQuiz(username string) (*models.Attempt, error) {
+	args := m.Called(username)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockQuizService) ResumeQuiz(username string) (*models.Question, error) {
+	args := m.Called(username)
+	question, _ := args.Get(0).(*models.Question)
+	return question, args.Error(1)
 }
 
 func (m *MockQuizService) GetResults(username string) (int, error) {
@@ -45,11 +73,41 @@ func (m *MockQuizService) GetResults(username string) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockQuizService) GetLatestAttempt(username string) (*models.Attempt, error) {
+	args := m.Called(username)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockQuizService) GetCountedScore(username, quizID string) (float64, error) {
+	args := m.Called(username, quizID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 func (m *MockQuizService) GetStats(username string) ([]models.User, string, error) {
 	args := m.Called(username)
 	return args.Get(0).([]models.User), args.String(1), args.Error(2)
 }
 
+// newSessionRequest builds a request carrying the session cookie of a logged-in user.
+func newSessionRequest(t *testing.T, method, target string, body io.Reader, username string) *http.Request {
+	t.Helper()
+	utils.InitializeSessionStore(config.LoadConfig())
+
+	login := httptest.NewRequest(http.MethodPost, "/login", nil)
+	rr := httptest.NewRecorder()
+	session, err := utils.SessionStore.Get(login, "quiz-session")
+	assert.NoError(t, err)
+	session.Values["username"] = username
+	assert.NoError(t, session.Save(login, rr))
+
+	req := httptest.NewRequest(method, target, body)
+	for _, cookie := range rr.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
 func TestGetQuestions(t *testing.T) {
 	mockService := new(MockQuizService)
 	handler := NewQuizHandler(mockService)
@@ -69,3 +127,47 @@ func TestGetQuestions(t *testing.T) {
 	assert.Equal(t, expectedQuestions, actualQuestions)
 	mockService.AssertExpectations(t)
 }
+
+func TestSubmitAnswerFeedbackByMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       *models.AnswerResult
+		expectedBody string
+	}{
+		{
+			name:         "Standard mode reveals the verdict",
+			result:       &models.AnswerResult{Mode: models.QuizModeStandard, Correct: true},
+			expectedBody: `{"message":"Correct answer"}`,
+		},
+		{
+			name:         "Practice mode explains and allows a retry",
+			result:       &models.AnswerResult{Mode: models.QuizModePractice, Correct: false, CanRetry: true, Explanation: "Because"},
+			expectedBody: `{"message":"Wrong answer","correct":false,"can_retry":true,"explanation":"Because"}`,
+		},
+		{
+			name:         "Exam mode hides the verdict",
+			result:       &models.AnswerResult{Mode: models.QuizModeExam, Correct: true},
+			expectedBody: `{"message":"Answer recorded"}`,
+		},
+	}
+
+	questions := []models.Question{{QuestionID: 1, Question: "What is Go?", Options: []string{"A", "B", "C"}, Answer: 1}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockQuizService)
+			handler := NewQuizHandler(mockService)
+			mockService.On("GetQuestions").Return(questions, nil)
+			mockService.On("SubmitAnswer", "testuser", 0, 1).Return(tt.result, nil)
+
+			body, _ := json.Marshal(models.AnswerPayload{QuestionIndex: 0, Answer: 1})
+			req := newSessionRequest(t, http.MethodPost, "/quiz/submit", bytes.NewBuffer(body), "testuser")
+			rr := httptest.NewRecorder()
+
+			handler.SubmitAnswer(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// roomPongWait is how long a room connection may stay silent, pings are
+	// sent well within it so that dead connections are noticed
+	roomPongWait     = 60 * time.Second
+	roomPingInterval = 25 * time.Second
+	roomWriteWait    = 10 * time.Second
+)
+
+type RoomHandler struct {
+	RoomService services.IRoomService
+	Upgrader    websocket.Upgrader
+}
+
+func NewRoomHandler(roomService services.IRoomService) *RoomHandler {
+	return &RoomHandler{RoomService: roomService}
+}
+
+// CreateRoom opens a live quiz room
+// @Summary Create a live quiz room
+// @Description Opens a room hosted by the logged-in user and returns its join code. Players connect to /rooms/{code}/ws.
+// @Tags Rooms
+// @Accept json
+// @Produce json
+// @Param settings body models.RoomSettings false "Quiz, number of questions and seconds per question"
+// @Success 201 {object} models.Room "Room in the lobby"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Quiz not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /rooms [post]
+func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	var settings models.RoomSettings
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+			logger.Warn("Invalid room settings", zap.Error(err))
+			writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+			return
+		}
+	}
+
+	room, err := h.RoomService.CreateRoom(username, settings)
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			writeJSONMessage(w, http.StatusNotFound, "Quiz not found")
+			return
+		}
+		logger.Error("Failed to create room", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(room); err != nil {
+		logger.Warn("Failed to encode room response", zap.Error(err))
+	}
+}
+
+// GetRoom returns the state of a room
+// @Summary Get a live quiz room
+// @Tags Rooms
+// @Produce json
+// @Param code path string true "Join code"
+// @Success 200 {object} models.Room "Room"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Room not found"
+// @Router /rooms/{code} [get]
+func (h *RoomHandler) GetRoom(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	room, err := h.RoomService.GetRoom(mux.Vars(r)["code"])
+	if err != nil {
+		writeJSONMessage(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(room); err != nil {
+		logger.Warn("Failed to encode room response", zap.Error(err))
+	}
+}
+
+// Connect upgrades to a WebSocket on which the room is played
+// @Summary Play in a live quiz room
+// @Description Joins the room and streams its events as JSON: state, question, answered, scoreboard and finished, each with the whole room. Clients send {"type":"answer","answer":n}, the host sends {"type":"next"} and {"type":"end"}. Reconnecting keeps the score.
+// @Tags Rooms
+// @Param code path string true "Join code"
+// @Success 101 {string} string "Switching protocols"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Room not found"
+// @Failure 409 {object} map[string]string "Room is finished"
+// @Router /rooms/{code}/ws [get]
+func (h *RoomHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+	code := mux.Vars(r)["code"]
+
+	subscription, err := h.RoomService.Connect(code, username)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoomNotFound):
+			writeJSONMessage(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrRoomFinished):
+			writeJSONMessage(w, http.StatusConflict, err.Error())
+		default:
+			logger.Error("Failed to join room", zap.String("code", code), zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer subscription.Close()
+
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("Failed to upgrade room connection", zap.String("code", code), zap.Error(err))
+		return
+	}
+	defer conn.Close()
+	logger.Info("Room connection opened", zap.String("code", code), zap.String("username", username))
+
+	// Errors of this connection's own commands are only sent back to it
+	replies := make(chan models.RoomEvent, 1)
+	done := make(chan struct{})
+	go h.writeEvents(conn, subscription.Events, replies, done)
+
+	conn.SetReadDeadline(time.Now().Add(roomPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(roomPongWait))
+	})
+	for {
+		var command models.RoomCommand
+		if err := conn.ReadJSON(&command); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Warn("Room connection lost", zap.String("code", code), zap.String("username", username), zap.Error(err))
+			}
+			break
+		}
+
+		var err error
+		switch command.Type {
+		case models.RoomCommandNext:
+			err = h.RoomService.Next(code, username)
+		case models.RoomCommandEnd:
+			err = h.RoomService.End(code, username)
+		case models.RoomCommandAnswer:
+			err = h.RoomService.Answer(code, username, command.Answer)
+		default:
+			err = errors.New("unknown command")
+		}
+		if err != nil {
+			select {
+			case replies <- models.RoomEvent{Type: models.RoomEventError, Message: err.Error()}:
+			case <-done:
+			}
+		}
+	}
+	logger.Info("Room connection closed", zap.String("code", code), zap.String("username", username))
+}
+
+// writeEvents is the only writer of a room connection. It stops when the
+// subscription is closed or a write fails.
+func (h *RoomHandler) writeEvents(conn *websocket.Conn, events <-chan models.RoomEvent, replies <-chan models.RoomEvent, done chan<- struct{}) {
+	defer close(done)
+	defer conn.Close()
+	ping := time.NewTicker(roomPingInterval)
+	defer ping.Stop()
+
+	for {
+		var event models.RoomEvent
+		select {
+		case received, ok := <-events:
+			if !ok {
+				return
+			}
+			event = received
+		case event = <-replies:
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(roomWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			continue
+		}
+		conn.SetWriteDeadline(time.Now().Add(roomWriteWait))
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoomService is a mock implementation of the IRoomService interface.
+type MockRoomService struct {
+	mock.Mock
+}
+
+func (m *MockRoomService) CreateRoom(host string, settings models.RoomSettings) (*models.Room, error) {
+	args := m.Called(host, settings)
+	room, _ := args.Get(0).(*models.Room)
+	return room, args.Error(1)
+}
+
+func (m *MockRoomService) GetRoom(code string) (*models.Room, error) {
+	args := m.Called(code)
+	room, _ := args.Get(0).(*models.Room)
+	return room, args.Error(1)
+}
+
+func (m *MockRoomService) Connect(code, username string) (*services.RoomSubscription, error) {
+	args := m.Called(code, username)
+	subscription, _ := args.Get(0).(*services.RoomSubscription)
+	return subscription, args.Error(1)
+}
+
+func (m *MockRoomService) Next(code, username string) error {
+	return m.Called(code, username).Error(0)
+}
+
+func (m *MockRoomService) Answer(code, username string, answer int) error {
+	return m.Called(code, username, answer).Error(0)
+}
+
+func (m *MockRoomService) End(code, username string) error {
+	return m.Called(code, username).Error(0)
+}
+
+func TestCreateRoom(t *testing.T) {
+	mockService := new(MockRoomService)
+	handler := NewRoomHandler(mockService)
+	settings := models.RoomSettings{Questions: 5, SecondsPerQuestion: 15}
+	mockService.On("CreateRoom", "host", settings).Return(&models.Room{Code: "ABC234", Host: "host", State: models.RoomLobby}, nil)
+
+	req := newSessionRequest(t, http.MethodPost, "/rooms", strings.NewReader(`{"questions":5,"seconds_per_question":15}`), "host")
+	rr := httptest.NewRecorder()
+	handler.CreateRoom(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"ABC234"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestRoomConnection(t *testing.T) {
+	mockService := new(MockRoomService)
+	handler := NewRoomHandler(mockService)
+	events := make(chan models.RoomEvent, 1)
+	closed := make(chan struct{})
+	mockService.On("Connect", "ABC234", "alice").Return(&services.RoomSubscription{
+		Events: events,
+		Close:  func() { close(closed) },
+	}, nil)
+	mockService.On("Connect", "NOPE", "alice").Return(nil, services.ErrRoomNotFound)
+	mockService.On("Answer", "ABC234", "alice", 2).Return(services.ErrNoQuestionOpen)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/rooms/{code}/ws", handler.Connect)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	header := http.Header{}
+	for _, cookie := range newSessionRequest(t, http.MethodGet, "/", nil, "alice").Cookies() {
+		header.Add("Cookie", cookie.String())
+	}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/rooms/"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"NOPE/ws", header)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"ABC234/ws", header)
+	assert.NoError(t, err)
+
+	events <- models.RoomEvent{Type: models.RoomEventQuestion, Room: &models.Room{Code: "ABC234", State: models.RoomQuestion}}
+	var event models.RoomEvent
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, models.RoomEventQuestion, event.Type)
+	assert.Equal(t, models.RoomQuestion, event.Room.State)
+
+	assert.NoError(t, conn.WriteJSON(models.RoomCommand{Type: models.RoomCommandAnswer, Answer: 2}))
+	var reply models.RoomEvent
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, models.RoomEvent{Type: models.RoomEventError, Message: services.ErrNoQuestionOpen.Error()}, reply)
+
+	conn.Close()
+	<-closed
+	mockService.AssertExpectations(t)
+}
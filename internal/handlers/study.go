@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type StudyHandler struct {
+	StudyService services.IStudyService
+}
+
+func NewStudyHandler(studyService services.IStudyService) *StudyHandler {
+	return &StudyHandler{StudyService: studyService}
+}
+
+// NextCard returns the next question to study
+// @Summary Get the next study question
+// @Description Returns the question that is due for review, the most overdue first, followed by questions never answered. The schedule follows SM-2 and includes answers given in quizzes.
+// @Tags Study
+// @Produce json
+// @Success 200 {object} models.StudyItem "Question to study and its schedule"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]interface{} "Nothing due, with the time of the next review"
+// @Failure 500 {string} string "Internal server error"
+// @Router /study/next [get]
+func (h *StudyHandler) NextCard(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	item, err := h.StudyService.NextCard(username)
+	if err != nil {
+		var nothingDue *services.NothingDueError
+		if errors.As(err, &nothingDue) {
+			logger.Info("Nothing to study", zap.String("username", username))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message":     nothingDue.Error(),
+				"next_due_at": nothingDue.NextDueAt.Format(time.RFC3339),
+			})
+			return
+		}
+		logger.Error("Failed to get study question", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(item); err != nil {
+		logger.Warn("Failed to encode study question response", zap.Error(err))
+	}
+}
+
+// Review submits the answer to a study question
+// @Summary Review a study question
+// @Description Checks the answer and schedules the question again. A correct answer can be graded with quality 3 (hard) to 5 (easy).
+// @Tags Study
+// @Accept json
+// @Produce json
+// @Param payload body models.StudyReviewPayload true "Study answer"
+// @Success 200 {object} models.StudyReviewResult "Answer feedback and the next review"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /study/review [post]
+func (h *StudyHandler) Review(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	var payload models.StudyReviewPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logger.Warn("Invalid input for study review", zap.Error(err))
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	result, err := h.StudyService.Review(username, payload.QuestionIndex, payload.Answer, payload.Quality)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStudyAnswer) {
+			logger.Warn("Validation failed for study review", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Error("Failed to review study question", zap.String("username", username), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Study question reviewed", zap.String("username", username), zap.Bool("correct", result.Correct))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Warn("Failed to encode study review response", zap.Error(err))
+	}
+}
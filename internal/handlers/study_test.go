@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStudyService is a mock implementation of the IStudyService interface.
+type MockStudyService struct {
+	mock.Mock
+}
+
+func (m *MockStudyService) NextCard(username string) (*models.StudyItem, error) {
+	args := m.Called(username)
+	item, _ := args.Get(0).(*models.StudyItem)
+	return item, args.Error(1)
+}
+
+func (m *MockStudyService) Review(username string, questionIndex, answer, quality int) (*models.StudyReviewResult, error) {
+	args := m.Called(username, questionIndex, answer, quality)
+	result, _ := args.Get(0).(*models.StudyReviewResult)
+	return result, args.Error(1)
+}
+
+func TestStudyNextCard(t *testing.T) {
+	t.Run("Nothing due", func(t *testing.T) {
+		mockService := new(MockStudyService)
+		handler := NewStudyHandler(mockService)
+		nextDueAt := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+		mockService.On("NextCard", "testuser").Return(nil, &services.NothingDueError{NextDueAt: nextDueAt})
+
+		req := newSessionRequest(t, http.MethodGet, "/study/next", nil, "testuser")
+		rr := httptest.NewRecorder()
+		handler.NextCard(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"next_due_at":"2025-01-02T12:00:00Z"`)
+	})
+
+	t.Run("Due question", func(t *testing.T) {
+		mockService := new(MockStudyService)
+		handler := NewStudyHandler(mockService)
+		item := &models.StudyItem{Question: &models.Question{QuestionID: 1, Question: "What is Go?"}, Card: models.StudyCard{QuestionID: 1}}
+		mockService.On("NextCard", "testuser").Return(item, nil)
+
+		req := newSessionRequest(t, http.MethodGet, "/study/next", nil, "testuser")
+		rr := httptest.NewRecorder()
+		handler.NextCard(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"question":"What is Go?"`)
+	})
+}
+
+func TestStudyReview(t *testing.T) {
+	mockService := new(MockStudyService)
+	handler := NewStudyHandler(mockService)
+	mockService.On("Review", "testuser", 9, 1, 0).Return(nil, fmt.Errorf("%w: question index is out of range", services.ErrInvalidStudyAnswer))
+	mockService.On("Review", "testuser", 0, 1, 5).Return(&models.StudyReviewResult{Correct: true, Quality: 5}, nil)
+
+	req := newSessionRequest(t, http.MethodPost, "/study/review", bytes.NewBufferString(`{"question_index":9,"answer":1}`), "testuser")
+	rr := httptest.NewRecorder()
+	handler.Review(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = newSessionRequest(t, http.MethodPost, "/study/review", bytes.NewBufferString(`{"question_index":0,"answer":1,"quality":5}`), "testuser")
+	rr = httptest.NewRecorder()
+	handler.Review(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"correct":true`)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type TeamHandler struct {
+	TeamService services.ITeamService
+}
+
+func NewTeamHandler(teamService services.ITeamService) *TeamHandler {
+	return &TeamHandler{TeamService: teamService}
+}
+
+// ListTeams lists the teams
+// @Summary List teams
+// @Tags Teams
+// @Produce json
+// @Success 200 {array} models.Team "Teams ordered by ID"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /teams [get]
+func (h *TeamHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	teams, err := h.TeamService.ListTeams()
+	if err != nil {
+		logger.Error("Failed to list teams", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(teams); err != nil {
+		logger.Warn("Failed to encode teams response", zap.Error(err))
+	}
+}
+
+// GetTeam returns a team with its members
+// @Summary Get a team
+// @Tags Teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} models.Team "Team"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Team not found"
+// @Router /teams/{id} [get]
+func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	team, err := h.TeamService.GetTeam(mux.Vars(r)["id"])
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeTeam(w, http.StatusOK, team)
+}
+
+// JoinTeam adds the user to a team
+// @Summary Join a team
+// @Description Makes the logged-in user a member of the team. Players are in one team at most and have to leave their team before joining another.
+// @Tags Teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} models.Team "Team"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Team not found"
+// @Failure 409 {object} map[string]string "Already in another team"
+// @Router /teams/{id}/join [post]
+func (h *TeamHandler) JoinTeam(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	team, err := h.TeamService.JoinTeam(mux.Vars(r)["id"], username)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeTeam(w, http.StatusOK, team)
+}
+
+// LeaveTeam removes the user from a team
+// @Summary Leave a team
+// @Tags Teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} models.Team "Team"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Team not found"
+// @Failure 409 {object} map[string]string "Not a member of the team"
+// @Router /teams/{id}/leave [post]
+func (h *TeamHandler) LeaveTeam(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	team, err := h.TeamService.LeaveTeam(mux.Vars(r)["id"], username)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeTeam(w, http.StatusOK, team)
+}
+
+// GetTeamLeaderboard returns a page of the team leaderboard
+// @Summary Get the team leaderboard
+// @Description Ranks the teams by the leaderboard scores of their members. The rule adds up every member (sum), divides the sum by the team size (average) or adds up the best members (top). The caller's team is returned as "my_team".
+// @Tags Leaderboard
+// @Produce json
+// @Param quiz query string false "Quiz ID, all quizzes when empty"
+// @Param period query string false "all, today, week, month, 24h, 7d or 30d" default(all)
+// @Param rule query string false "sum, average or top" default(sum)
+// @Param top query int false "Members counted by the top rule" default(3)
+// @Param limit query int false "Page size, at most 100" default(10)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} models.TeamLeaderboard "Team leaderboard page"
+// @Failure 400 {object} map[string]string "Invalid query"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Quiz not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /leaderboard/teams [get]
+func (h *TeamHandler) GetTeamLeaderboard(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	values := r.URL.Query()
+	query := models.TeamLeaderboardQuery{
+		LeaderboardQuery: models.LeaderboardQuery{
+			QuizID: values.Get("quiz"),
+			Period: models.LeaderboardPeriod(values.Get("period")),
+			Cursor: values.Get("cursor"),
+		},
+		Rule: models.TeamRule(values.Get("rule")),
+	}
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			writeJSONMessage(w, http.StatusBadRequest, services.ErrInvalidLimit.Error())
+			return
+		}
+		query.Limit = parsed
+	}
+	if top := values.Get("top"); top != "" {
+		parsed, err := strconv.Atoi(top)
+		if err != nil {
+			writeJSONMessage(w, http.StatusBadRequest, services.ErrInvalidTopN.Error())
+			return
+		}
+		query.TopN = parsed
+	}
+
+	leaderboard, err := h.TeamService.GetTeamLeaderboard(username, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPeriod),
+			errors.Is(err, services.ErrInvalidLimit),
+			errors.Is(err, services.ErrInvalidCursor),
+			errors.Is(err, services.ErrInvalidTeamRule),
+			errors.Is(err, services.ErrInvalidTopN):
+			logger.Warn("Invalid team leaderboard query", zap.Error(err))
+			writeJSONMessage(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, database.ErrQuizNotFound):
+			writeJSONMessage(w, http.StatusNotFound, err.Error())
+		default:
+			logger.Error("Failed to compute team leaderboard", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(leaderboard); err != nil {
+		logger.Warn("Failed to encode team leaderboard response", zap.Error(err))
+	}
+}
+
+// CreateTeam adds a team
+// @Summary Create a team
+// @Description Admins only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param team body models.TeamPayload true "Team ID and name"
+// @Success 201 {object} models.Team "Team"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {object} map[string]string "Team already exists"
+// @Router /admin/teams [post]
+func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	var payload models.TeamPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logger.Warn("Invalid team payload", zap.Error(err))
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	team, err := h.TeamService.CreateTeam(payload)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeTeam(w, http.StatusCreated, team)
+}
+
+// DeleteTeam removes a team
+// @Summary Delete a team
+// @Description Its members are left without a team. Admins only.
+// @Tags Admin
+// @Param id path string true "Team ID"
+// @Success 204 "Team deleted"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {object} map[string]string "Team not found"
+// @Router /admin/teams/{id} [delete]
+func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	if err := h.TeamService.DeleteTeam(mux.Vars(r)["id"]); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddMember puts a user in a team
+// @Summary Add a team member
+// @Description Moves the user into the team, out of the team they were in. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param username path string true "Username"
+// @Success 200 {object} models.Team "Team"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {object} map[string]string "Team or user not found"
+// @Router /admin/teams/{id}/members/{username} [put]
+func (h *TeamHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	team, err := h.TeamService.AddMember(vars["id"], vars["username"])
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeTeam(w, http.StatusOK, team)
+}
+
+// RemoveMember takes a user out of a team
+// @Summary Remove a team member
+// @Description Admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param username path string true "Username"
+// @Success 200 {object} models.Team "Team"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {object} map[string]string "Team not found"
+// @Failure 409 {object} map[string]string "Not a member of the team"
+// @Router /admin/teams/{id}/members/{username} [delete]
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	team, err := h.TeamService.RemoveMember(vars["id"], vars["username"])
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeTeam(w, http.StatusOK, team)
+}
+
+func writeTeam(w http.ResponseWriter, status int, team *models.Team) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(team); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to encode team response", zap.Error(err))
+	}
+}
+
+// writeTeamError maps team errors to HTTP status codes.
+func writeTeamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTeam):
+		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrTeamNotFound), errors.Is(err, database.ErrUserNotFound):
+		writeJSONMessage(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, database.ErrTeamExists),
+		errors.Is(err, services.ErrAlreadyInTeam),
+		errors.Is(err, services.ErrNotTeamMember):
+		writeJSONMessage(w, http.StatusConflict, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("Team request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTeamService is a mock implementation of the ITeamService interface.
+type MockTeamService struct {
+	mock.Mock
+}
+
+func (m *MockTeamService) CreateTeam(payload models.TeamPayload) (*models.Team, error) {
+	args := m.Called(payload)
+	team, _ := args.Get(0).(*models.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamService) DeleteTeam(teamID string) error {
+	args := m.Called(teamID)
+	return args.Error(0)
+}
+
+func (m *MockTeamService) GetTeam(teamID string) (*models.Team, error) {
+	args := m.Called(teamID)
+	team, _ := args.Get(0).(*models.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamService) ListTeams() ([]models.Team, error) {
+	args := m.Called()
+	teams, _ := args.Get(0).([]models.Team)
+	return teams, args.Error(1)
+}
+
+func (m *MockTeamService) JoinTeam(teamID, username string) (*models.Team, error) {
+	args := m.Called(teamID, username)
+	team, _ := args.Get(0).(*models.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamService) LeaveTeam(teamID, username string) (*models.Team, error) {
+	args := m.Called(teamID, username)
+	team, _ := args.Get(0).(*models.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamService) AddMember(teamID, username string) (*models.Team, error) {
+	args := m.Called(teamID, username)
+	team, _ := args.Get(0).(*models.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamService) RemoveMember(teamID, username string) (*models.Team, error) {
+	args := m.Called(teamID, username)
+	team, _ := args.Get(0).(*models.Team)
+	return team, args.Error(1)
+}
+
+func (m *MockTeamService) GetTeamLeaderboard(username string, query models.TeamLeaderboardQuery) (*models.TeamLeaderboard, error) {
+	args := m.Called(username, query)
+	leaderboard, _ := args.Get(0).(*models.TeamLeaderboard)
+	return leaderboard, args.Error(1)
+}
+
+func TestJoinTeam(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"Joined", nil, http.StatusOK},
+		{"Unknown team", database.ErrTeamNotFound, http.StatusNotFound},
+		{"In another team", services.ErrAlreadyInTeam, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTeamService)
+			handler := NewTeamHandler(mockService)
+			var team *models.Team
+			if tt.err == nil {
+				team = &models.Team{TeamID: "sales", Members: []string{"alice"}}
+			}
+			mockService.On("JoinTeam", "sales", "alice").Return(team, tt.err)
+
+			req := newSessionRequest(t, http.MethodPost, "/teams/sales/join", nil, "alice")
+			req = mux.SetURLVars(req, map[string]string{"id": "sales"})
+			rr := httptest.NewRecorder()
+			handler.JoinTeam(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTeam(t *testing.T) {
+	mockService := new(MockTeamService)
+	handler := NewTeamHandler(mockService)
+	mockService.On("CreateTeam", models.TeamPayload{TeamID: "sales", Name: "Sales"}).Return(&models.Team{TeamID: "sales", Name: "Sales"}, nil)
+	mockService.On("CreateTeam", models.TeamPayload{TeamID: "sales"}).Return(nil, services.ErrInvalidTeam)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/teams", strings.NewReader(`{"team_id":"sales","name":"Sales"}`))
+	rr := httptest.NewRecorder()
+	handler.CreateTeam(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/teams", strings.NewReader(`{"team_id":"sales"}`))
+	rr = httptest.NewRecorder()
+	handler.CreateTeam(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetTeamLeaderboard(t *testing.T) {
+	mockService := new(MockTeamService)
+	handler := NewTeamHandler(mockService)
+	query := models.TeamLeaderboardQuery{
+		LeaderboardQuery: models.LeaderboardQuery{QuizID: "exam", Period: models.PeriodThisWeek},
+		Rule:             models.TeamRuleTopN,
+		TopN:             2,
+	}
+	mockService.On("GetTeamLeaderboard", "alice", query).Return(&models.TeamLeaderboard{
+		QuizID:  "exam",
+		Rule:    models.TeamRuleTopN,
+		TopN:    2,
+		Entries: []models.TeamLeaderboardEntry{{Rank: 1, TeamID: "sales", Score: 9}},
+	}, nil)
+
+	req := newSessionRequest(t, http.MethodGet, "/leaderboard/teams?quiz=exam&period=week&rule=top&top=2", nil, "alice")
+	rr := httptest.NewRecorder()
+	handler.GetTeamLeaderboard(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"team_id":"sales"`)
+	mockService.AssertExpectations(t)
+
+	req = newSessionRequest(t, http.MethodGet, "/leaderboard/teams?top=many", nil, "alice")
+	rr = httptest.NewRecorder()
+	handler.GetTeamLeaderboard(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type TokenHandler struct {
+	TokenService services.ITokenService
+}
+
+func NewTokenHandler(tokenService services.ITokenService) *TokenHandler {
+	return &TokenHandler{TokenService: tokenService}
+}
+
+// IssueTokens logs an API client in with bearer tokens
+// @Summary Get bearer tokens
+// @Description Logs in with a username and password and returns a short-lived access token for the Authorization: Bearer header and a refresh token for /token/refresh. Users with two-factor authentication on get a challenge to finish on /token/2fa instead.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param user body models.User true "Username and password"
+// @Success 200 {object} models.TokenPair "Tokens"
+// @Success 202 {object} models.LoginChallenge "Two-factor code required"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {object} map[string]string "Invalid credentials"
+// @Failure 429 {object} map[string]interface{} "Too many attempts or account locked, see Retry-After"
+// @Router /token [post]
+func (h *TokenHandler) IssueTokens(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil || user.Username == "" || user.Password == "" {
+		logger.Warn("Invalid input for token login", zap.Error(err))
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	pair, challenge, err := h.TokenService.Login(user.Username, user.Password, utils.ClientIP(r))
+	if err != nil {
+		var limitErr *services.LoginLimitError
+		if errors.As(err, &limitErr) {
+			writeLoginLimitError(w, limitErr)
+			return
+		}
+		logger.Warn("Token login failed", zap.String("username", user.Username), zap.Error(err))
+		writeJSONMessage(w, http.StatusUnauthorized, "Invalid username or password")
+		return
+	}
+	if challenge != nil {
+		writeLoginChallenge(w, challenge)
+		return
+	}
+	writeTokenPair(w, pair)
+}
+
+// CompleteTokenLogin finishes a two-factor token login
+// @Summary Finish a two-factor token login
+// @Description Exchanges the challenge token from /token and a code from the authenticator app, or a recovery code, for bearer tokens.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorLoginPayload true "Challenge token and code"
+// @Success 200 {object} models.TokenPair "Tokens"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {object} map[string]string "Invalid code or challenge"
+// @Failure 429 {object} map[string]interface{} "Too many attempts or account locked, see Retry-After"
+// @Router /token/2fa [post]
+func (h *TokenHandler) CompleteTokenLogin(w http.ResponseWriter, r *http.Request) {
+	var payload models.TwoFactorLoginPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.ChallengeToken == "" || payload.Code == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	pair, err := h.TokenService.CompleteLogin(payload.ChallengeToken, payload.Code, utils.ClientIP(r))
+	if err != nil {
+		writeTwoFactorLoginError(w, err)
+		return
+	}
+	writeTokenPair(w, pair)
+}
+
+// RefreshTokens rotates a refresh token
+// @Summary Refresh bearer tokens
+// @Description Exchanges a refresh token for a new access and refresh token. Each refresh token works once; using one again revokes every token of that login.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshTokenPayload true "Refresh token"
+// @Success 200 {object} models.TokenPair "Tokens"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {object} map[string]string "Invalid, expired or reused refresh token"
+// @Router /token/refresh [post]
+func (h *TokenHandler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+	var payload models.RefreshTokenPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.RefreshToken == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	pair, err := h.TokenService.Refresh(payload.RefreshToken)
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+	writeTokenPair(w, pair)
+}
+
+// RevokeTokens logs an API client out
+// @Summary Revoke bearer tokens
+// @Description Revokes the refresh token with every token rotated from the same login, and the access token of the Authorization header when there is one.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshTokenPayload true "Refresh token"
+// @Success 200 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {object} map[string]string "Invalid refresh token"
+// @Router /token/revoke [post]
+func (h *TokenHandler) RevokeTokens(w http.ResponseWriter, r *http.Request) {
+	var payload models.RefreshTokenPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.RefreshToken == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	accessToken, _ := utils.BearerToken(r.Header.Get("Authorization"))
+	if err := h.TokenService.Revoke(payload.RefreshToken, accessToken); err != nil {
+		writeTokenError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusOK, "Tokens revoked")
+}
+
+func writeTokenPair(w http.ResponseWriter, pair *models.TokenPair) {
+	// Tokens must not end up in caches
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pair); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to encode token response", zap.Error(err))
+	}
+}
+
+// writeTokenError maps token errors to HTTP status codes.
+func writeTokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRefreshToken), errors.Is(err, services.ErrRefreshTokenReused):
+		writeJSONMessage(w, http.StatusUnauthorized, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("Token request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
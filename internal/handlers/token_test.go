@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTokenService is a mock implementation of the ITokenService interface.
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) Login(username, password, ip string) (*models.TokenPair, *models.LoginChallenge, error) {
+	args := m.Called(username, password, ip)
+	pair, _ := args.Get(0).(*models.TokenPair)
+	challenge, _ := args.Get(1).(*models.LoginChallenge)
+	return pair, challenge, args.Error(2)
+}
+
+func (m *MockTokenService) CompleteLogin(challengeToken, code, ip string) (*models.TokenPair, error) {
+	args := m.Called(challengeToken, code, ip)
+	pair, _ := args.Get(0).(*models.TokenPair)
+	return pair, args.Error(1)
+}
+
+func (m *MockTokenService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	args := m.Called(refreshToken)
+	pair, _ := args.Get(0).(*models.TokenPair)
+	return pair, args.Error(1)
+}
+
+func (m *MockTokenService) Revoke(refreshToken, accessToken string) error {
+	args := m.Called(refreshToken, accessToken)
+	return args.Error(0)
+}
+
+func TestIssueTokens(t *testing.T) {
+	mockService := new(MockTokenService)
+	handler := NewTokenHandler(mockService)
+	pair := &models.TokenPair{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh"}
+	mockService.On("Login", "alice", "Password123!", "192.0.2.1").Return(pair, nil, nil)
+	mockService.On("Login", "alice", "wrong", "192.0.2.1").Return(nil, nil, errors.New("invalid username or password"))
+	mockService.On("Login", "bob", "wrong", "192.0.2.1").Return(nil, nil, &services.LoginLimitError{Rule: services.ErrAccountLocked, RetryAt: time.Now().Add(time.Minute)})
+
+	body, _ := json.Marshal(models.User{Username: "alice", Password: "Password123!"})
+	rr := httptest.NewRecorder()
+	handler.IssueTokens(rr, httptest.NewRequest(http.MethodPost, "/token", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	var got models.TokenPair
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, *pair, got)
+
+	body, _ = json.Marshal(models.User{Username: "alice", Password: "wrong"})
+	rr = httptest.NewRecorder()
+	handler.IssueTokens(rr, httptest.NewRequest(http.MethodPost, "/token", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	body, _ = json.Marshal(models.User{Username: "bob", Password: "wrong"})
+	rr = httptest.NewRecorder()
+	handler.IssueTokens(rr, httptest.NewRequest(http.MethodPost, "/token", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+}
+
+func TestIssueTokensTwoFactor(t *testing.T) {
+	mockService := new(MockTokenService)
+	handler := NewTokenHandler(mockService)
+	pair := &models.TokenPair{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh"}
+	challenge := &models.LoginChallenge{TwoFactorRequired: true, ChallengeToken: "challenge"}
+	mockService.On("Login", "alice", "Password123!", "192.0.2.1").Return(nil, challenge, nil)
+	mockService.On("CompleteLogin", "challenge", "000000", "192.0.2.1").Return(nil, services.ErrInvalidTwoFactorCode)
+	mockService.On("CompleteLogin", "challenge", "123456", "192.0.2.1").Return(pair, nil)
+
+	body, _ := json.Marshal(models.User{Username: "alice", Password: "Password123!"})
+	rr := httptest.NewRecorder()
+	handler.IssueTokens(rr, httptest.NewRequest(http.MethodPost, "/token", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"challenge_token":"challenge"`)
+
+	rr = httptest.NewRecorder()
+	handler.CompleteTokenLogin(rr, httptest.NewRequest(http.MethodPost, "/token/2fa", bytes.NewBufferString(`{"challenge_token":"challenge","code":"000000"}`)))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.CompleteTokenLogin(rr, httptest.NewRequest(http.MethodPost, "/token/2fa", bytes.NewBufferString(`{"challenge_token":"challenge","code":"123456"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var got models.TokenPair
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, *pair, got)
+}
+
+func TestRefreshAndRevokeTokens(t *testing.T) {
+	mockService := new(MockTokenService)
+	handler := NewTokenHandler(mockService)
+	mockService.On("Refresh", "used").Return(nil, services.ErrRefreshTokenReused)
+	mockService.On("Revoke", "refresh", "access").Return(nil)
+
+	rr := httptest.NewRecorder()
+	handler.RefreshTokens(rr, httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBufferString(`{"refresh_token":"used"}`)))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.RefreshTokens(rr, httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBufferString(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/token/revoke", bytes.NewBufferString(`{"refresh_token":"refresh"}`))
+	req.Header.Set("Authorization", "Bearer access")
+	rr = httptest.NewRecorder()
+	handler.RevokeTokens(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
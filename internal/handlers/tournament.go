@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type TournamentHandler struct {
+	TournamentService services.ITournamentService
+}
+
+func NewTournamentHandler(tournamentService services.ITournamentService) *TournamentHandler {
+	return &TournamentHandler{TournamentService: tournamentService}
+}
+
+// ListTournaments lists the tournaments
+// @Summary List tournaments
+// @Tags Tournaments
+// @Produce json
+// @Success 200 {array} models.Tournament "Tournaments, the latest first"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 500 {string} string "Internal server error"
+// @Router /tournaments [get]
+func (h *TournamentHandler) ListTournaments(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	tournaments, err := h.TournamentService.ListTournaments()
+	if err != nil {
+		logger.Error("Failed to list tournaments", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tournaments); err != nil {
+		logger.Warn("Failed to encode tournaments response", zap.Error(err))
+	}
+}
+
+// GetTournament returns a tournament with its bracket
+// @Summary Get a tournament
+// @Description Returns the players with their seeds and points and every round with its matches. Each active match is played through /quiz/start with its quiz_id.
+// @Tags Tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} models.Tournament "Tournament"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Tournament not found"
+// @Router /tournaments/{id} [get]
+func (h *TournamentHandler) GetTournament(w http.ResponseWriter, r *http.Request) {
+	tournament, err := h.TournamentService.GetTournament(mux.Vars(r)["id"])
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	writeTournament(w, http.StatusOK, tournament)
+}
+
+// GetBracket returns the bracket of a tournament as text
+// @Summary Get a tournament bracket as text
+// @Tags Tournaments
+// @Produce plain
+// @Param id path string true "Tournament ID"
+// @Success 200 {string} string "Bracket"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Tournament not found"
+// @Router /tournaments/{id}/bracket [get]
+func (h *TournamentHandler) GetBracket(w http.ResponseWriter, r *http.Request) {
+	tournament, err := h.TournamentService.GetTournament(mux.Vars(r)["id"])
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write([]byte(services.RenderBracket(*tournament))); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to write bracket response", zap.Error(err))
+	}
+}
+
+// RegisterPlayer registers the user for a tournament
+// @Summary Register for a tournament
+// @Tags Tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} models.Tournament "Tournament"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 404 {object} map[string]string "Tournament not found"
+// @Failure 409 {object} map[string]string "Already registered or registration closed"
+// @Router /tournaments/{id}/register [post]
+func (h *TournamentHandler) RegisterPlayer(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	tournament, err := h.TournamentService.Register(mux.Vars(r)["id"], username)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	writeTournament(w, http.StatusOK, tournament)
+}
+
+// CreateTournament opens the registration of a tournament
+// @Summary Create a tournament
+// @Description The format is single_elimination (default) or swiss. Swiss tournaments play the given number of rounds, enough for a single winner when it is 0. Admins only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param tournament body models.TournamentPayload true "Name, format, rounds and questions per match"
+// @Success 201 {object} models.Tournament "Tournament"
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/tournaments [post]
+func (h *TournamentHandler) CreateTournament(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger().Sugar()
+
+	var payload models.TournamentPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logger.Warn("Invalid tournament payload", zap.Error(err))
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return
+	}
+
+	tournament, err := h.TournamentService.CreateTournament(payload)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	writeTournament(w, http.StatusCreated, tournament)
+}
+
+// StartTournament closes the registration and starts the first round
+// @Summary Start a tournament
+// @Description Seeds the registered players by rating and starts the first round. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} models.Tournament "Running tournament"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {object} map[string]string "Tournament not found"
+// @Failure 409 {object} map[string]string "Already started or not enough players"
+// @Failure 503 {object} map[string]string "No questions available"
+// @Router /admin/tournaments/{id}/start [post]
+func (h *TournamentHandler) StartTournament(w http.ResponseWriter, r *http.Request) {
+	tournament, err := h.TournamentService.Start(mux.Vars(r)["id"])
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+	writeTournament(w, http.StatusOK, tournament)
+}
+
+func writeTournament(w http.ResponseWriter, status int, tournament *models.Tournament) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(tournament); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to encode tournament response", zap.Error(err))
+	}
+}
+
+// writeTournamentError maps tournament errors to HTTP status codes.
+func writeTournamentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTournament), errors.Is(err, services.ErrInvalidTournamentFormat):
+		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrTournamentNotFound), errors.Is(err, database.ErrUserNotFound):
+		writeJSONMessage(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrRegistrationClosed),
+		errors.Is(err, services.ErrAlreadyRegistered),
+		errors.Is(err, services.ErrTournamentStarted),
+		errors.Is(err, services.ErrNotEnoughPlayers):
+		writeJSONMessage(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrNoQuestionsForTournament):
+		writeJSONMessage(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("Tournament request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTournamentService is a mock implementation of the ITournamentService interface.
+type MockTournamentService struct {
+	mock.Mock
+}
+
+func (m *MockTournamentService) CreateTournament(payload models.TournamentPayload) (*models.Tournament, error) {
+	args := m.Called(payload)
+	tournament, _ := args.Get(0).(*models.Tournament)
+	return tournament, args.Error(1)
+}
+
+func (m *MockTournamentService) Register(tournamentID, username string) (*models.Tournament, error) {
+	args := m.Called(tournamentID, username)
+	tournament, _ := args.Get(0).(*models.Tournament)
+	return tournament, args.Error(1)
+}
+
+func (m *MockTournamentService) Start(tournamentID string) (*models.Tournament, error) {
+	args := m.Called(tournamentID)
+	tournament, _ := args.Get(0).(*models.Tournament)
+	return tournament, args.Error(1)
+}
+
+func (m *MockTournamentService) GetTournament(tournamentID string) (*models.Tournament, error) {
+	args := m.Called(tournamentID)
+	tournament, _ := args.Get(0).(*models.Tournament)
+	return tournament, args.Error(1)
+}
+
+func (m *MockTournamentService) ListTournaments() ([]models.Tournament, error) {
+	args := m.Called()
+	tournaments, _ := args.Get(0).([]models.Tournament)
+	return tournaments, args.Error(1)
+}
+
+func TestRegisterForTournament(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"Registered", nil, http.StatusOK},
+		{"Unknown tournament", database.ErrTournamentNotFound, http.StatusNotFound},
+		{"Registration closed", services.ErrRegistrationClosed, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTournamentService)
+			handler := NewTournamentHandler(mockService)
+			var tournament *models.Tournament
+			if tt.err == nil {
+				tournament = &models.Tournament{TournamentID: "t1", Status: models.TournamentRegistration}
+			}
+			mockService.On("Register", "t1", "alice").Return(tournament, tt.err)
+
+			req := newSessionRequest(t, http.MethodPost, "/tournaments/t1/register", nil, "alice")
+			req = mux.SetURLVars(req, map[string]string{"id": "t1"})
+			rr := httptest.NewRecorder()
+			handler.RegisterPlayer(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBracket(t *testing.T) {
+	mockService := new(MockTournamentService)
+	handler := NewTournamentHandler(mockService)
+	mockService.On("GetTournament", "t1").Return(&models.Tournament{
+		Name:    "Cup",
+		Format:  models.TournamentSingleElimination,
+		Status:  models.TournamentCompleted,
+		Rounds:  1,
+		Players: []models.TournamentPlayer{{Username: "alice", Seed: 1}, {Username: "bob", Seed: 2}},
+		Bracket: []models.TournamentRound{{Number: 1, Matches: []models.TournamentMatch{{
+			Number:  1,
+			Players: []string{"alice", "bob"},
+			Status:  models.MatchCompleted,
+			Winner:  "alice",
+			Results: []models.DuelResult{{Username: "alice", Score: 3}, {Username: "bob", Score: 1}},
+		}}}},
+		Winner: "alice",
+	}, nil)
+
+	req := newSessionRequest(t, http.MethodGet, "/tournaments/t1/bracket", nil, "bob")
+	req = mux.SetURLVars(req, map[string]string{"id": "t1"})
+	rr := httptest.NewRecorder()
+	handler.GetBracket(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "[1] alice  vs  [2] bob  3-1, alice wins")
+	assert.Contains(t, rr.Body.String(), "Winner: alice")
+}
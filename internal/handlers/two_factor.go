@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+type TwoFactorHandler struct {
+	TwoFactorService services.ITwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService services.ITwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{TwoFactorService: twoFactorService}
+}
+
+// GetTwoFactorStatus tells whether two-factor authentication is on
+// @Summary Get my two-factor authentication status
+// @Tags User
+// @Produce json
+// @Success 200 {object} models.TwoFactorStatus "Status"
+// @Failure 401 {string} string "Invalid session"
+// @Router /me/2fa [get]
+func (h *TwoFactorHandler) GetTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	status, err := h.TwoFactorService.Status(username)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeTwoFactorJSON(w, http.StatusOK, status)
+}
+
+// EnrollTwoFactor starts turning two-factor authentication on
+// @Summary Enroll in two-factor authentication
+// @Description Returns a new TOTP secret with its otpauth URI for an authenticator app. Two-factor authentication is on once a code of it is sent to /me/2fa/verify.
+// @Tags User
+// @Produce json
+// @Success 200 {object} models.TwoFactorEnrollment "Secret and otpauth URI"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 409 {object} map[string]string "Already on"
+// @Router /me/2fa/enroll [post]
+func (h *TwoFactorHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	enrollment, err := h.TwoFactorService.Enroll(username)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeTwoFactorJSON(w, http.StatusOK, enrollment)
+}
+
+// VerifyTwoFactor turns two-factor authentication on
+// @Summary Verify the two-factor enrollment
+// @Description Turns two-factor authentication on with a code from the authenticator app and returns one-time recovery codes. The recovery codes are only shown here. Every other session and bearer token of the user is logged out, and a bearer token the request was sent with too.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodePayload true "Code from the authenticator app"
+// @Success 200 {object} models.RecoveryCodes "Recovery codes"
+// @Failure 400 {object} map[string]string "Invalid code or no enrollment"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 409 {object} map[string]string "Already on"
+// @Router /me/2fa/verify [post]
+func (h *TwoFactorHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username, code, ok := readTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	sessionToken, _ := session.Values["session_token"].(string)
+
+	codes, err := h.TwoFactorService.Confirm(username, code, sessionToken)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeTwoFactorJSON(w, http.StatusOK, codes)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes
+// @Summary Regenerate my recovery codes
+// @Description Replaces every recovery code after a code from the authenticator app. The old codes stop working.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodePayload true "Code from the authenticator app"
+// @Success 200 {object} models.RecoveryCodes "Recovery codes"
+// @Failure 400 {object} map[string]string "Invalid code or two-factor authentication off"
+// @Failure 401 {string} string "Invalid session"
+// @Router /me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	username, code, ok := readTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.TwoFactorService.RegenerateRecoveryCodes(username, code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeTwoFactorJSON(w, http.StatusOK, codes)
+}
+
+// DisableTwoFactor turns two-factor authentication off
+// @Summary Turn two-factor authentication off
+// @Description Turns two-factor authentication off with a code from the authenticator app or a recovery code. Accounts that require it cannot turn it off.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodePayload true "Code or recovery code"
+// @Success 200 {object} map[string]string "message"
+// @Failure 400 {object} map[string]string "Invalid code or two-factor authentication off"
+// @Failure 401 {string} string "Invalid session"
+// @Failure 403 {object} map[string]string "Required for this account"
+// @Router /me/2fa/disable [post]
+func (h *TwoFactorHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username, code, ok := readTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.TwoFactorService.Disable(username, code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeJSONMessage(w, http.StatusOK, "Two-factor authentication turned off")
+}
+
+// readTwoFactorCode returns the user of the request and the code it sent.
+func readTwoFactorCode(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	username, _ := session.Values["username"].(string)
+
+	var payload models.TwoFactorCodePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Code == "" {
+		writeJSONMessage(w, http.StatusBadRequest, "Invalid input")
+		return "", "", false
+	}
+	return username, payload.Code, true
+}
+
+// writeLoginChallenge asks for the second login step.
+func writeLoginChallenge(w http.ResponseWriter, challenge *models.LoginChallenge) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeTwoFactorJSON(w, http.StatusAccepted, challenge)
+}
+
+func writeTwoFactorJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		utils.GetLogger().Sugar().Warn("Failed to encode two-factor response", zap.Error(err))
+	}
+}
+
+// writeTwoFactorLoginError answers a second login step that failed.
+func writeTwoFactorLoginError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		writeJSONMessage(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	writeTwoFactorError(w, err)
+}
+
+// writeTwoFactorError maps two-factor errors to HTTP status codes.
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	var limitErr *services.LoginLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		writeLoginLimitError(w, limitErr)
+	case errors.Is(err, services.ErrInvalidLoginChallenge):
+		writeJSONMessage(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrInvalidTwoFactorCode),
+		errors.Is(err, services.ErrNoTwoFactorEnrollment),
+		errors.Is(err, services.ErrTwoFactorNotEnabled):
+		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTwoFactorEnabled):
+		writeJSONMessage(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		writeJSONMessage(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, database.ErrUserNotFound):
+		writeJSONMessage(w, http.StatusNotFound, err.Error())
+	default:
+		utils.GetLogger().Sugar().Error("Two-factor request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/config"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/services"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTwoFactorService is a mock implementation of the ITwoFactorService interface.
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Status(username string) (*models.TwoFactorStatus, error) {
+	args := m.Called(username)
+	status, _ := args.Get(0).(*models.TwoFactorStatus)
+	return status, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Enroll(username string) (*models.TwoFactorEnrollment, error) {
+	args := m.Called(username)
+	enrollment, _ := args.Get(0).(*models.TwoFactorEnrollment)
+	return enrollment, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(username, code, keepSessionToken string) (*models.RecoveryCodes, error) {
+	args := m.Called(username, code, keepSessionToken)
+	codes, _ := args.Get(0).(*models.RecoveryCodes)
+	return codes, args.Error(1)
+}
+
+func (m *MockTwoFactorService) RegenerateRecoveryCodes(username, code string) (*models.RecoveryCodes, error) {
+	args := m.Called(username, code)
+	codes, _ := args.Get(0).(*models.RecoveryCodes)
+	return codes, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Disable(username, code string) error {
+	args := m.Called(username, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) StartLogin(username string) (*models.LoginChallenge, error) {
+	args := m.Called(username)
+	challenge, _ := args.Get(0).(*models.LoginChallenge)
+	return challenge, args.Error(1)
+}
+
+func (m *MockTwoFactorService) CompleteLogin(challengeToken, code, ip string) (string, error) {
+	args := m.Called(challengeToken, code, ip)
+	return args.String(0), args.Error(1)
+}
+
+func TestEnrollAndVerifyTwoFactor(t *testing.T) {
+	mockService := new(MockTwoFactorService)
+	handler := NewTwoFactorHandler(mockService)
+	enrollment := &models.TwoFactorEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/Quiz%20App:alice?secret=JBSWY3DPEHPK3PXP"}
+	mockService.On("Enroll", "alice").Return(enrollment, nil).Once()
+	mockService.On("Enroll", "alice").Return(nil, services.ErrTwoFactorEnabled)
+	mockService.On("Confirm", "alice", "123456", mock.Anything).Return(&models.RecoveryCodes{RecoveryCodes: []string{"abcd-efgh-ijkl-mnop"}}, nil)
+	mockService.On("Confirm", "alice", "000000", mock.Anything).Return(nil, services.ErrInvalidTwoFactorCode)
+
+	rr := httptest.NewRecorder()
+	handler.EnrollTwoFactor(rr, newSessionRequest(t, http.MethodPost, "/me/2fa/enroll", nil, "alice"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"secret":"JBSWY3DPEHPK3PXP","otpauth_uri":"otpauth://totp/Quiz%20App:alice?secret=JBSWY3DPEHPK3PXP"}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.EnrollTwoFactor(rr, newSessionRequest(t, http.MethodPost, "/me/2fa/enroll", nil, "alice"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.VerifyTwoFactor(rr, newSessionRequest(t, http.MethodPost, "/me/2fa/verify", bytes.NewBufferString(`{"code":"000000"}`), "alice"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.VerifyTwoFactor(rr, newSessionRequest(t, http.MethodPost, "/me/2fa/verify", bytes.NewBufferString(`{"code":"123456"}`), "alice"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"recovery_codes":["abcd-efgh-ijkl-mnop"]}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{"Disabled", `{"code":"123456"}`, nil, http.StatusOK},
+		{"Required", `{"code":"123456"}`, services.ErrTwoFactorRequired, http.StatusForbidden},
+		{"Off already", `{"code":"123456"}`, services.ErrTwoFactorNotEnabled, http.StatusBadRequest},
+		{"Missing code", `{}`, nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTwoFactorService)
+			handler := NewTwoFactorHandler(mockService)
+			mockService.On("Disable", "alice", "123456").Return(tt.err)
+
+			rr := httptest.NewRecorder()
+			handler.DisableTwoFactor(rr, newSessionRequest(t, http.MethodPost, "/me/2fa/disable", bytes.NewBufferString(tt.body), "alice"))
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestLoginUserTwoFactor(t *testing.T) {
+	utils.InitializeSessionStore(config.LoadConfig())
+	t.Cleanup(func() { utils.RevokeUserSessions("alice") })
+	authService := new(MockAuthService)
+	twoFactor := new(MockTwoFactorService)
+	handler := NewAuthHandler(authService)
+	handler.TwoFactor = twoFactor
+	challenge := &models.LoginChallenge{TwoFactorRequired: true, ChallengeToken: "challenge", ExpiresAt: time.Date(2025, 1, 10, 12, 5, 0, 0, time.UTC)}
+	authService.On("Login", "alice", "Password123!", "192.0.2.1").Return(nil)
+	authService.On("GetUserID", "alice").Return("user-1", nil)
+	twoFactor.On("StartLogin", "alice").Return(challenge, nil)
+	twoFactor.On("CompleteLogin", "challenge", "000000", "192.0.2.1").Return("", services.ErrInvalidTwoFactorCode)
+	twoFactor.On("CompleteLogin", "used", "123456", "192.0.2.1").Return("", services.ErrInvalidLoginChallenge)
+	twoFactor.On("CompleteLogin", "challenge", "123456", "192.0.2.1").Return("alice", nil)
+
+	body, _ := json.Marshal(models.User{Username: "alice", Password: "Password123!"})
+	rr := httptest.NewRecorder()
+	handler.LoginUser(rr, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Empty(t, rr.Result().Cookies(), "expected no session before the second step")
+	assert.JSONEq(t, `{"two_factor_required":true,"challenge_token":"challenge","expires_at":"2025-01-10T12:05:00Z"}`, rr.Body.String())
+
+	for payload, status := range map[string]int{
+		`{"challenge_token":"challenge","code":"000000"}`: http.StatusUnauthorized,
+		`{"challenge_token":"used","code":"123456"}`:      http.StatusUnauthorized,
+		`{"challenge_token":"challenge"}`:                 http.StatusBadRequest,
+	} {
+		rr = httptest.NewRecorder()
+		handler.CompleteLogin(rr, httptest.NewRequest(http.MethodPost, "/login/2fa", bytes.NewBufferString(payload)))
+		assert.Equal(t, status, rr.Code, payload)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.CompleteLogin(rr, httptest.NewRequest(http.MethodPost, "/login/2fa", bytes.NewBufferString(`{"challenge_token":"challenge","code":"123456"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEmpty(t, rr.Result().Cookies())
+	assert.Contains(t, rr.Body.String(), "Login successful")
+}
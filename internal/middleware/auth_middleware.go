@@ -12,9 +12,20 @@ type contextKey string
 
 const usernameKey contextKey = "username"
 
+// AuthMiddleware lets through requests with a valid session cookie, a valid
+// Authorization: Bearer access token or a valid X-API-Key.
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := utils.GetLogger().Sugar()
+
+		if header := r.Header.Get("Authorization"); header != "" {
+			bearerAuth(next, w, r, header)
+			return
+		}
+		if key := r.Header.Get("X-API-Key"); key != "" {
+			apiKeyAuth(next, w, r, key)
+			return
+		}
 		logger.Debug("Incoming cookies", zap.String("raw_cookies", r.Header.Get("Cookie")))
 
 		session, err := utils.SessionStore.Get(r, "quiz-session")
@@ -34,14 +45,14 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		storedUsername, exists := utils.SessionDB[sessionToken]
+		storedUsername, exists := utils.LookupSession(sessionToken)
 		logger.Debug("Session token validation",
 			zap.String("session_token_in_cookie", sessionToken),
 			zap.String("stored_username", storedUsername),
 			zap.Bool("exists", exists))
 
 		if !exists || storedUsername != username {
-			logger.Warn("Session token not found or mismatched",
+			logger.Warn("Session token not found, expired or mismatched",
 				zap.String("session_token_in_cookie", sessionToken),
 				zap.String("stored_username", storedUsername))
 			http.Error(w, "Invalid session", http.StatusUnauthorized)
@@ -52,3 +63,37 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// bearerAuth authenticates a request by its access token. The handlers read
+// the user from the session, so the user is put in the session of this
+// request; it is never saved to a cookie unless a handler saves it.
+func bearerAuth(next http.Handler, w http.ResponseWriter, r *http.Request, header string) {
+	logger := utils.GetLogger().Sugar()
+
+	token, ok := utils.BearerToken(header)
+	if !ok {
+		logger.Warn("Unsupported Authorization header")
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+	claims, err := utils.ParseAccessToken(token)
+	if err != nil {
+		logger.Warn("Access token rejected", zap.Error(err))
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	serveAs(next, w, r, claims.Subject)
+}
+
+// serveAs passes a request authenticated without the cookie on as the user.
+func serveAs(next http.Handler, w http.ResponseWriter, r *http.Request, username string) {
+	// The cookie store returns a fresh session when the cookie cannot be read
+	session, _ := utils.SessionStore.Get(r, "quiz-session")
+	session.Values["username"] = username
+	delete(session.Values, "session_token")
+
+	ctx := context.WithValue(r.Context(), usernameKey, username)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+// RequireRole only lets users with the given role through. It must run after
+// AuthMiddleware, which puts the username in the request context.
+func RequireRole(db *database.MemoryDB, role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := utils.GetLogger().Sugar()
+
+			username, _ := r.Context().Value(usernameKey).(string)
+			user, err := db.GetUser(username)
+			if err != nil || user.Role != role {
+				logger.Warn("Access denied for missing role",
+					zap.String("username", username),
+					zap.String("role", role))
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireTwoFactor only lets users with two-factor authentication on
+// through. Turning it on logs out the sessions and bearer tokens from before,
+// so the ones left passed the second step. API keys, admin-scoped ones
+// included, pass when they were created after it was turned on, which took a
+// login with the second step. It must run after AuthMiddleware.
+func RequireTwoFactor(db *database.MemoryDB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := utils.GetLogger().Sugar()
+
+			username, _ := r.Context().Value(usernameKey).(string)
+			user, err := db.GetUser(username)
+			if err != nil || !user.TwoFactor.Enabled {
+				logger.Warn("Access denied without two-factor authentication", zap.String("username", username))
+				http.Error(w, "Two-factor authentication required, turn it on at /me/2fa", http.StatusForbidden)
+				return
+			}
+			if created, isAPIKey := r.Context().Value(apiKeyCreatedKey).(time.Time); isAPIKey && created.Before(user.TwoFactor.EnabledAt) {
+				logger.Warn("Access denied for API key older than two-factor authentication", zap.String("username", username))
+				http.Error(w, "API key created before two-factor authentication was turned on, create a new one", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
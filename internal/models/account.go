@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Purposes of account tokens.
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeVerifyEmail   = "verify_email"
+	// TokenPurposeLoginChallenge tokens are the second login step, never mailed
+	TokenPurposeLoginChallenge = "login_challenge"
+)
+
+// AccountToken is a single-use secret mailed to a user to reset the password
+// or to verify an email address. Only the hash of the token is stored.
+type AccountToken struct {
+	TokenHash string `json:"-"`
+	Purpose   string `json:"purpose"`
+	Username  string `json:"username"`
+	// Email is the address a verify_email token confirms
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// UsedAt is set once the token was used or replaced by a newer one
+	UsedAt time.Time `json:"used_at,omitempty"`
+	// Attempts counts the wrong codes sent with a login challenge
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// EmailPayload sets the email address of the logged-in user.
+type EmailPayload struct {
+	CurrentPassword string `json:"current_password"`
+	Email           string `json:"email"`
+}
+
+// ForgotPasswordPayload names the account by username or verified email.
+type ForgotPasswordPayload struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// ResetPasswordPayload sets a new password with a mailed reset token.
+type ResetPasswordPayload struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// AccountTokenPayload carries a mailed token, such as an email verification.
+type AccountTokenPayload struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// AchievementRule is the kind of condition that unlocks an achievement.
+type AchievementRule string
+
+const (
+	// RulePerfectScore unlocks on a completed attempt with every question right
+	RulePerfectScore AchievementRule = "perfect_score"
+	// RuleQuizzesCompleted unlocks once Count attempts are completed
+	RuleQuizzesCompleted AchievementRule = "quizzes_completed"
+	// RuleCorrectAnswers unlocks once Count answers are right
+	RuleCorrectAnswers AchievementRule = "correct_answers"
+	// RuleDayStreak unlocks after answering on Count days in a row
+	RuleDayStreak AchievementRule = "day_streak"
+	// RuleTopicMastered unlocks once every question of Category or Tag was answered right
+	RuleTopicMastered AchievementRule = "topic_mastered"
+)
+
+// Achievement is the declarative definition of an achievement.
+type Achievement struct {
+	AchievementID string          `json:"achievement_id"`
+	Title         string          `json:"title"`
+	Description   string          `json:"description,omitempty"`
+	Rule          AchievementRule `json:"rule"`
+	Count         int             `json:"count,omitempty"`
+	Category      string          `json:"category,omitempty"`
+	Tag           string          `json:"tag,omitempty"`
+}
+
+// AchievementUnlock records when a user unlocked an achievement.
+type AchievementUnlock struct {
+	AchievementID string    `json:"achievement_id"`
+	Title         string    `json:"title"`
+	Username      string    `json:"username"`
+	UnlockedAt    time.Time `json:"unlocked_at"`
+	// Announced is set once the unlock was reported in a response
+	Announced bool `json:"-"`
+}
+
+// AchievementStatus is an achievement together with whether the user unlocked it.
+type AchievementStatus struct {
+	Achievement
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// QuestionAnalytics is the item analysis of one question over the answers of
+// completed attempts.
+type QuestionAnalytics struct {
+	QuestionID int    `json:"question_id"`
+	Question   string `json:"question"`
+	Responses  int    `json:"responses"`
+	// PValue is the share of the responses that were correct
+	PValue float64 `json:"p_value"`
+	// Discrimination is the point-biserial correlation between answering the
+	// question right and the total score of the attempt
+	Discrimination float64 `json:"discrimination"`
+	// OptionCounts counts how often each option was picked, the first option first
+	OptionCounts         []int   `json:"option_counts"`
+	AverageAnswerSeconds float64 `json:"average_answer_seconds"`
+	Flagged              bool    `json:"flagged"`
+	FlagReason           string  `json:"flag_reason,omitempty"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Scopes of API keys. Keys only reach the endpoints of their scopes, and the
+// admin endpoints only when their owner is an admin too.
+const (
+	// ScopeRead covers the leaderboards and the endpoints under /me
+	ScopeRead = "read"
+	// ScopePlay covers quizzes, study, daily challenges, rooms, duels, teams and tournaments
+	ScopePlay = "play"
+	// ScopeAnalytics covers the admin analytics
+	ScopeAnalytics = "analytics"
+	// ScopeAdmin covers the other admin endpoints
+	ScopeAdmin = "admin"
+)
+
+// APIKeyScopes lists every scope a key can have.
+var APIKeyScopes = []string{ScopeRead, ScopePlay, ScopeAnalytics, ScopeAdmin}
+
+// APIKey is a personal key for scripts, sent in the X-API-Key header. Only the
+// hash of the key is stored; Prefix is the start of the key to tell keys apart.
+type APIKey struct {
+	KeyID      string     `json:"key_id"`
+	Name       string     `json:"name"`
+	Username   string     `json:"username"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// HasScope reports whether the key was given the scope.
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyPayload creates an API key. Keys without ExpiresInDays never expire.
+type APIKeyPayload struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// NewAPIKey is a key just created. Key is the secret itself, shown this once.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
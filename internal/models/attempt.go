@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+type LifelineType string
+
+const (
+	LifelineHint       LifelineType = "hint"
+	LifelineFiftyFifty LifelineType = "fifty_fifty"
+)
+
+type AttemptStatus string
+
+const (
+	AttemptActive    AttemptStatus = "active"
+	AttemptPaused    AttemptStatus = "paused"
+	AttemptCompleted AttemptStatus = "completed"
+	AttemptExpired   AttemptStatus = "expired"
+	AttemptAbandoned AttemptStatus = "abandoned"
+)
+
+type LifelineUse struct {
+	Type          LifelineType `json:"type"`
+	QuestionIndex int          `json:"question_index"`
+	Penalty       int          `json:"penalty"`
+	UsedAt        time.Time    `json:"used_at"`
+}
+
+type LifelineResult struct {
+	Type           LifelineType `json:"type"`
+	QuestionIndex  int          `json:"question_index"`
+	Hint           string       `json:"hint,omitempty"`
+	RemovedOptions []int        `json:"removed_options,omitempty"`
+	Penalty        int          `json:"penalty"`
+	Remaining      int          `json:"remaining"`
+}
+
+// AnswerRecord tracks a single question of an attempt from the moment it is
+// served until it is answered or skipped.
+type AnswerRecord struct {
+	QuestionIndex int       `json:"question_index"`
+	QuestionID    int       `json:"question_id"`
+	Answer        int       `json:"answer"`
+	Correct       bool      `json:"correct"`
+	Skipped       bool      `json:"skipped"`
+	Points        int       `json:"points"`
+	Tries         int       `json:"tries"`
+	ServedAt      time.Time `json:"served_at"`
+	AnsweredAt    time.Time `json:"answered_at"`
+}
+
+func (r AnswerRecord) Pending() bool {
+	return r.AnsweredAt.IsZero() && !r.Skipped
+}
+
+// AnswerResult is the outcome of a submitted answer. Handlers decide how much
+// of it to reveal based on the quiz mode.
+type AnswerResult struct {
+	Mode          QuizMode `json:"mode"`
+	Correct       bool     `json:"correct"`
+	CorrectAnswer int      `json:"correct_answer"`
+	Explanation   string   `json:"explanation,omitempty"`
+	Points        int      `json:"points"`
+	CanRetry      bool     `json:"can_retry"`
+}
+
+type Attempt struct {
+	AttemptID     string           `json:"attempt_id"`
+	Username      string           `json:"username"`
+	QuizID        string           `json:"quiz_id"`
+	Mode          QuizMode         `json:"mode"`
+	Ranked        bool             `json:"ranked"`
+	Strategy      QuestionStrategy `json:"strategy,omitempty"`
+	Status        AttemptStatus    `json:"status"`
+	StartedAt     time.Time        `json:"started_at"`
+	Deadline      time.Time        `json:"deadline"`
+	PausedAt      time.Time        `json:"paused_at"`
+	TimeRemaining time.Duration    `json:"time_remaining"`
+	CompletedAt   time.Time        `json:"completed_at"`
+	Score         int              `json:"score"`
+	Ability       float64          `json:"ability"`
+	AbilityError  float64          `json:"ability_error,omitempty"`
+	XPEarned      int              `json:"xp_earned,omitempty"`
+	Answers       []AnswerRecord   `json:"answers"`
+	Lifelines     []LifelineUse    `json:"lifelines"`
+}
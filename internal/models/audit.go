@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Types of audit events.
+const (
+	AuditLoginSucceeded       = "login_succeeded"
+	AuditLoginFailed          = "login_failed"
+	AuditLoginThrottled       = "login_throttled"
+	AuditAccountLocked        = "account_locked"
+	AuditAccountUnlocked      = "account_unlocked"
+	AuditTwoFactorFailed      = "two_factor_failed"
+	AuditTwoFactorEnabled     = "two_factor_enabled"
+	AuditTwoFactorDisabled    = "two_factor_disabled"
+	AuditRecoveryCodeUsed     = "recovery_code_used"
+	AuditPasswordChanged      = "password_changed"
+	AuditPasswordChangeFailed = "password_change_failed"
+)
+
+// AuditEvent records a security relevant event, such as a failed login.
+type AuditEvent struct {
+	EventID  string `json:"event_id"`
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	IP       string `json:"ip,omitempty"`
+	// Actor is the admin behind the event, when it was not the user
+	Actor  string    `json:"actor,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+	Time   time.Time `json:"time"`
+}
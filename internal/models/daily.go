@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DailyChallenge is the short quiz every player gets on one day. It is played
+// as the quiz QuizID, which has its own leaderboard.
+type DailyChallenge struct {
+	Date        string    `json:"date"`
+	QuizID      string    `json:"quiz_id"`
+	QuestionIDs []int     `json:"question_ids"`
+	OpensAt     time.Time `json:"opens_at"`
+	ClosesAt    time.Time `json:"closes_at"`
+}
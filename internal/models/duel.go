@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+type DuelMode string
+
+const (
+	// DuelSync is played by both players right after it is accepted.
+	DuelSync DuelMode = "sync"
+	// DuelAsync lets each player play when they are free until the deadline.
+	DuelAsync DuelMode = "async"
+)
+
+type DuelStatus string
+
+const (
+	DuelPending   DuelStatus = "pending"
+	DuelDeclined  DuelStatus = "declined"
+	DuelActive    DuelStatus = "active"
+	DuelCompleted DuelStatus = "completed"
+	// DuelExpired was not accepted in time, or neither player finished it.
+	DuelExpired DuelStatus = "expired"
+)
+
+const DefaultRating = 1200
+
+// DuelResult is how one player did in a duel or a tournament match.
+type DuelResult struct {
+	Username     string  `json:"username"`
+	Finished     bool    `json:"finished"`
+	Score        int     `json:"score"`
+	Seconds      float64 `json:"seconds"`
+	RatingBefore int     `json:"rating_before,omitempty"`
+	RatingAfter  int     `json:"rating_after,omitempty"`
+}
+
+// Duel is a challenge between two players on the same question set, played
+// as the quiz QuizID once accepted.
+type Duel struct {
+	DuelID      string     `json:"duel_id"`
+	Challenger  string     `json:"challenger"`
+	Opponent    string     `json:"opponent"`
+	Mode        DuelMode   `json:"mode"`
+	Status      DuelStatus `json:"status"`
+	QuizID      string     `json:"quiz_id"`
+	QuestionIDs []int      `json:"question_ids"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  time.Time  `json:"accepted_at,omitempty"`
+	DeadlineAt  time.Time  `json:"deadline_at,omitempty"`
+	CompletedAt time.Time  `json:"completed_at,omitempty"`
+	// Winner is empty for a draw
+	Winner  string       `json:"winner,omitempty"`
+	Results []DuelResult `json:"results,omitempty"`
+}
+
+// DuelChallengePayload is the invitation sent by the challenger.
+type DuelChallengePayload struct {
+	Opponent  string   `json:"opponent"`
+	Mode      DuelMode `json:"mode"`
+	Questions int      `json:"questions"`
+}
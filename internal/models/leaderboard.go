@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// LeaderboardPeriod is the time window a leaderboard covers.
+type LeaderboardPeriod string
+
+const (
+	PeriodAllTime LeaderboardPeriod = "all"
+
+	// Rolling periods reach back a fixed time from now
+	PeriodLast24Hours LeaderboardPeriod = "24h"
+	PeriodLast7Days   LeaderboardPeriod = "7d"
+	PeriodLast30Days  LeaderboardPeriod = "30d"
+
+	// Calendar periods reset at midnight, on Monday and on the first of the month
+	// in the timezone of the leaderboard
+	PeriodToday     LeaderboardPeriod = "today"
+	PeriodThisWeek  LeaderboardPeriod = "week"
+	PeriodThisMonth LeaderboardPeriod = "month"
+)
+
+// Window returns how far back a rolling period reaches, zero for the others.
+func (p LeaderboardPeriod) Window() time.Duration {
+	switch p {
+	case PeriodLast24Hours:
+		return 24 * time.Hour
+	case PeriodLast7Days:
+		return 7 * 24 * time.Hour
+	case PeriodLast30Days:
+		return 30 * 24 * time.Hour
+	}
+	return 0
+}
+
+// LeaderboardQuery selects and pages a leaderboard. An empty QuizID ranks
+// players over every quiz.
+type LeaderboardQuery struct {
+	QuizID string
+	Period LeaderboardPeriod
+	Limit  int
+	Cursor string
+}
+
+type LeaderboardEntry struct {
+	Rank     int     `json:"rank"`
+	Username string  `json:"username"`
+	Score    float64 `json:"score"`
+	Attempts int     `json:"attempts"`
+}
+
+// PlayerStanding is the place of the caller on a leaderboard. Percentile is
+// the share of the other players with a lower score.
+type PlayerStanding struct {
+	Rank       int     `json:"rank"`
+	Score      float64 `json:"score"`
+	Percentile float64 `json:"percentile"`
+}
+
+type Leaderboard struct {
+	QuizID     string             `json:"quiz_id,omitempty"`
+	Period     LeaderboardPeriod  `json:"period"`
+	Total      int                `json:"total"`
+	Entries    []LeaderboardEntry `json:"entries"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	ResetsAt   *time.Time         `json:"resets_at,omitempty"`
+	Me         *PlayerStanding    `json:"me,omitempty"`
+}
@@ -4,3 +4,26 @@ type AnswerPayload struct {
 	QuestionIndex int `json:"question_index"`
 	Answer        int `json:"answer"`
 }
+
+type LifelinePayload struct {
+	QuestionIndex int          `json:"question_index"`
+	Type          LifelineType `json:"type"`
+}
+
+type StartQuizPayload struct {
+	QuizID string `json:"quiz_id"`
+}
+
+// StudyReviewPayload answers a study question. Quality optionally grades a
+// correct answer from 3 (hard) to 5 (easy).
+type StudyReviewPayload struct {
+	QuestionIndex int `json:"question_index"`
+	Answer        int `json:"answer"`
+	Quality       int `json:"quality,omitempty"`
+}
+
+// PasswordChangePayload changes the password of the logged-in user.
+type PasswordChangePayload struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AttemptSummary is one point on the attempts-over-time chart.
+type AttemptSummary struct {
+	AttemptID   string        `json:"attempt_id"`
+	QuizID      string        `json:"quiz_id"`
+	Mode        QuizMode      `json:"mode"`
+	Status      AttemptStatus `json:"status"`
+	StartedAt   time.Time     `json:"started_at"`
+	CompletedAt time.Time     `json:"completed_at,omitempty"`
+	Score       int           `json:"score"`
+	Answered    int           `json:"answered"`
+	Correct     int           `json:"correct"`
+	Accuracy    float64       `json:"accuracy"`
+}
+
+// TopicAccuracy is how well a user answers the questions of a category or tag.
+type TopicAccuracy struct {
+	Topic    string  `json:"topic"`
+	Answered int     `json:"answered"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// Streaks are counted back from the latest answer and the latest day played.
+type Streaks struct {
+	CorrectAnswers     int `json:"correct_answers"`
+	BestCorrectAnswers int `json:"best_correct_answers"`
+	DaysPlayed         int `json:"days_played"`
+}
+
+// Progress is the dashboard data of a user.
+type Progress struct {
+	Username             string           `json:"username"`
+	Attempts             []AttemptSummary `json:"attempts"`
+	Categories           []TopicAccuracy  `json:"categories"`
+	Tags                 []TopicAccuracy  `json:"tags"`
+	AverageAnswerSeconds float64          `json:"average_answer_seconds"`
+	Streaks              Streaks          `json:"streaks"`
+	WeakestTopics        []TopicAccuracy  `json:"weakest_topics"`
+	Profile              PlayerProfile    `json:"profile"`
+}
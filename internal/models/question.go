@@ -1,8 +1,13 @@
 package models
 
 type Question struct {
-	QuestionID int      `json:"question_id"`
-	Question   string   `json:"question"`
-	Options    []string `json:"options"`
-	Answer     int      `json:"answer"`
+	QuestionID  int      `json:"question_id"`
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	Answer      int      `json:"answer"`
+	Hint        string   `json:"hint,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+	Difficulty  float64  `json:"difficulty,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
 }
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+const DefaultQuizID = "general"
+
+type QuizMode string
+
+const (
+	// QuizModeStandard reveals whether an answer was right and counts on the leaderboards.
+	QuizModeStandard QuizMode = "standard"
+	// QuizModePractice gives instant feedback with explanations, allows retries and is never ranked.
+	QuizModePractice QuizMode = "practice"
+	// QuizModeExam hides all feedback until the end, locks answers and limits attempts.
+	QuizModeExam QuizMode = "exam"
+)
+
+type QuestionStrategy string
+
+const (
+	// StrategySequential serves the questions in the order of the question bank.
+	StrategySequential QuestionStrategy = "sequential"
+	// StrategyAdaptive picks each question from the player's estimated ability.
+	StrategyAdaptive QuestionStrategy = "adaptive"
+)
+
+type ScoringPolicy string
+
+const (
+	ScoreBest    ScoringPolicy = "best"
+	ScoreLast    ScoringPolicy = "last"
+	ScoreAverage ScoringPolicy = "average"
+)
+
+type ResumePolicy string
+
+const (
+	// ResumeRepeat serves the question that was on screen when the attempt was paused again.
+	ResumeRepeat ResumePolicy = "repeat"
+	// ResumeSkip counts the unanswered question as skipped and moves on to the next one.
+	ResumeSkip ResumePolicy = "skip"
+)
+
+// Quiz holds the rules a quiz is played by. A quiz registered without a
+// ScoringPolicy counts the best completed attempt.
+type Quiz struct {
+	QuizID           string           `json:"quiz_id"`
+	Title            string           `json:"title"`
+	Mode             QuizMode         `json:"mode"`
+	MaxAttempts      int              `json:"max_attempts,omitempty"`
+	CooldownSeconds  int              `json:"cooldown_seconds,omitempty"`
+	OpensAt          *time.Time       `json:"opens_at,omitempty"`
+	ClosesAt         *time.Time       `json:"closes_at,omitempty"`
+	ScoringPolicy    ScoringPolicy    `json:"scoring_policy,omitempty"`
+	Strategy         QuestionStrategy `json:"strategy,omitempty"`
+	MaxQuestions     int              `json:"max_questions,omitempty"`
+	TargetPrecision  float64          `json:"target_precision,omitempty"`
+	TimeLimitSeconds int              `json:"time_limit_seconds"`
+	PauseTimer       bool             `json:"pause_timer"`
+	ResumePolicy     ResumePolicy     `json:"resume_policy"`
+	// QuestionIDs limits the quiz to these questions, served in this order
+	QuestionIDs []int `json:"question_ids,omitempty"`
+	// Daily is the date of the daily challenge the quiz was generated for
+	Daily string `json:"daily,omitempty"`
+	// Players limits who may start the quiz, everyone may when empty
+	Players []string `json:"players,omitempty"`
+	// Unlisted quizzes are started by their ID but left out of the quiz list
+	Unlisted bool `json:"unlisted,omitempty"`
+}
+
+func (q Quiz) TimeLimit() time.Duration {
+	return time.Duration(q.TimeLimitSeconds) * time.Second
+}
+
+func (q Quiz) Cooldown() time.Duration {
+	return time.Duration(q.CooldownSeconds) * time.Second
+}
+
+// Allows reports whether the user may start the quiz.
+func (q Quiz) Allows(username string) bool {
+	if len(q.Players) == 0 {
+		return true
+	}
+	for _, player := range q.Players {
+		if player == username {
+			return true
+		}
+	}
+	return false
+}
+
+// Ranked reports whether attempts of the quiz count on leaderboards.
+func (q Quiz) Ranked() bool {
+	return q.Mode != QuizModePractice
+}
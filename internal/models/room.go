@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+type RoomState string
+
+const (
+	// RoomLobby waits for players until the host serves the first question.
+	RoomLobby RoomState = "lobby"
+	// RoomQuestion collects answers until the countdown runs out.
+	RoomQuestion RoomState = "question"
+	// RoomReveal shows the answer and the scoreboard until the host moves on.
+	RoomReveal RoomState = "reveal"
+	// RoomFinished ends the room, the final scoreboard stays available.
+	RoomFinished RoomState = "finished"
+)
+
+// RoomSettings are chosen by the host when creating a room.
+type RoomSettings struct {
+	QuizID             string `json:"quiz_id"`
+	Questions          int    `json:"questions"`
+	SecondsPerQuestion int    `json:"seconds_per_question"`
+}
+
+// RoomScore is a line of the live scoreboard of a room.
+type RoomScore struct {
+	Rank      int    `json:"rank"`
+	Username  string `json:"username"`
+	Score     int    `json:"score"`
+	Answered  bool   `json:"answered"`
+	Connected bool   `json:"connected"`
+}
+
+// Room is the state of a live quiz room as every participant sees it.
+type Room struct {
+	Code           string      `json:"code"`
+	Host           string      `json:"host"`
+	State          RoomState   `json:"state"`
+	QuestionNumber int         `json:"question_number"`
+	QuestionCount  int         `json:"question_count"`
+	Question       *Question   `json:"question,omitempty"`
+	Deadline       *time.Time  `json:"deadline,omitempty"`
+	CorrectAnswer  int         `json:"correct_answer,omitempty"`
+	Scoreboard     []RoomScore `json:"scoreboard"`
+}
+
+type RoomEventType string
+
+const (
+	// RoomEventState is sent on connecting and whenever players come and go.
+	RoomEventState    RoomEventType = "state"
+	RoomEventQuestion RoomEventType = "question"
+	// RoomEventAnswered tells that a player answered, without the answer.
+	RoomEventAnswered   RoomEventType = "answered"
+	RoomEventScoreboard RoomEventType = "scoreboard"
+	RoomEventFinished   RoomEventType = "finished"
+	RoomEventError      RoomEventType = "error"
+)
+
+// RoomEvent is pushed to the participants of a room. Every event carries the
+// whole room, so a client that missed events or reconnects is in sync again
+// with the next one.
+type RoomEvent struct {
+	Type    RoomEventType `json:"type"`
+	Room    *Room         `json:"room,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+type RoomCommandType string
+
+const (
+	// RoomCommandNext serves the next question, or closes the open one early. Host only.
+	RoomCommandNext RoomCommandType = "next"
+	// RoomCommandEnd finishes the room. Host only.
+	RoomCommandEnd    RoomCommandType = "end"
+	RoomCommandAnswer RoomCommandType = "answer"
+)
+
+// RoomCommand is sent by a participant over the room connection.
+type RoomCommand struct {
+	Type   RoomCommandType `json:"type"`
+	Answer int             `json:"answer,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session is a login kept on the server. The token is the secret in the
+// session cookie and is never sent back, SessionID names the session instead.
+type Session struct {
+	SessionID  string    `json:"session_id"`
+	Token      string    `json:"-"`
+	Username   string    `json:"username"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	// Current marks the session of the request that listed the sessions
+	Current bool `json:"current,omitempty"`
+}
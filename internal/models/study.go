@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// StudyCard is the spaced-repetition schedule of one question for one user,
+// following the SM-2 algorithm.
+type StudyCard struct {
+	Username       string    `json:"username"`
+	QuestionID     int       `json:"question_id"`
+	QuestionIndex  int       `json:"question_index"`
+	EaseFactor     float64   `json:"ease_factor"`
+	IntervalDays   int       `json:"interval_days"`
+	Repetitions    int       `json:"repetitions"`
+	DueAt          time.Time `json:"due_at"`
+	LastReviewedAt time.Time `json:"last_reviewed_at,omitempty"`
+}
+
+// New reports whether the question was never answered by the user.
+func (c StudyCard) New() bool {
+	return c.LastReviewedAt.IsZero()
+}
+
+// StudyItem is the next question to study together with its schedule.
+type StudyItem struct {
+	Question *Question `json:"question"`
+	Card     StudyCard `json:"card"`
+}
+
+// StudyReviewResult tells how a study answer went and when the question is due again.
+type StudyReviewResult struct {
+	Correct       bool      `json:"correct"`
+	CorrectAnswer int       `json:"correct_answer"`
+	Explanation   string    `json:"explanation,omitempty"`
+	Quality       int       `json:"quality"`
+	Card          StudyCard `json:"card"`
+}
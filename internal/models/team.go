@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// TeamRule is how the scores of the members add up to the score of a team.
+type TeamRule string
+
+const (
+	TeamRuleSum TeamRule = "sum"
+	// TeamRuleAverage divides by every member, so members who did not play
+	// pull the team down.
+	TeamRuleAverage TeamRule = "average"
+	// TeamRuleTopN adds up the scores of the N best members.
+	TeamRuleTopN TeamRule = "top"
+)
+
+// Team is a group of players ranked together. A player is in one team at most.
+type Team struct {
+	TeamID    string    `json:"team_id"`
+	Name      string    `json:"name"`
+	Members   []string  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasMember reports whether the user is a member of the team.
+func (t Team) HasMember(username string) bool {
+	for _, member := range t.Members {
+		if member == username {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamPayload creates a team.
+type TeamPayload struct {
+	TeamID string `json:"team_id"`
+	Name   string `json:"name"`
+}
+
+// TeamLeaderboardQuery selects a team leaderboard. TopN is only used by the
+// top rule.
+type TeamLeaderboardQuery struct {
+	LeaderboardQuery
+	Rule TeamRule
+	TopN int
+}
+
+type TeamLeaderboardEntry struct {
+	Rank   int     `json:"rank"`
+	TeamID string  `json:"team_id"`
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	// Members is the size of the team and Players how many of them scored
+	Members int `json:"members"`
+	Players int `json:"players"`
+}
+
+type TeamLeaderboard struct {
+	QuizID     string                 `json:"quiz_id,omitempty"`
+	Period     LeaderboardPeriod      `json:"period"`
+	Rule       TeamRule               `json:"rule"`
+	TopN       int                    `json:"top_n,omitempty"`
+	Total      int                    `json:"total"`
+	Entries    []TeamLeaderboardEntry `json:"entries"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	ResetsAt   *time.Time             `json:"resets_at,omitempty"`
+	// MyTeam is the standing of the caller's team, when they are in one
+	MyTeam *TeamLeaderboardEntry `json:"my_team,omitempty"`
+}
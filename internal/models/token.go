@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// TokenPair is what API clients get instead of a session cookie. The access
+// token goes in the Authorization header, the refresh token buys the next pair.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenPayload carries a refresh token to rotate or revoke.
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken is a refresh token kept on the server. Only the hash of the
+// token is stored. Every token rotated from the same login shares FamilyID,
+// so that reusing a rotated token can revoke the whole family.
+type RefreshToken struct {
+	TokenHash string    `json:"-"`
+	FamilyID  string    `json:"family_id"`
+	Username  string    `json:"username"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// RotatedAt is set once the token was exchanged for a new pair
+	RotatedAt time.Time `json:"rotated_at,omitempty"`
+	Revoked   bool      `json:"revoked"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+type TournamentFormat string
+
+const (
+	// TournamentSingleElimination knocks out the loser of every match.
+	TournamentSingleElimination TournamentFormat = "single_elimination"
+	// TournamentSwiss pairs players with equal points for a fixed number of
+	// rounds and nobody is knocked out.
+	TournamentSwiss TournamentFormat = "swiss"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentRegistration TournamentStatus = "registration"
+	TournamentRunning      TournamentStatus = "running"
+	TournamentCompleted    TournamentStatus = "completed"
+)
+
+type MatchStatus string
+
+const (
+	MatchActive    MatchStatus = "active"
+	MatchCompleted MatchStatus = "completed"
+	// MatchBye has a single player, who wins it without playing.
+	MatchBye MatchStatus = "bye"
+)
+
+// TournamentPlayer is a registered player with their seed, 1 being the
+// highest rated, and how they are doing so far.
+type TournamentPlayer struct {
+	Username string `json:"username"`
+	Seed     int    `json:"seed,omitempty"`
+	Rating   int    `json:"rating"`
+	// Points counts wins as 1 and draws as 0.5
+	Points     float64 `json:"points"`
+	Score      int     `json:"score"`
+	Eliminated bool    `json:"eliminated,omitempty"`
+}
+
+// TournamentMatch is played as the quiz QuizID, which only its players can
+// start, once each.
+type TournamentMatch struct {
+	Number  int          `json:"number"`
+	QuizID  string       `json:"quiz_id,omitempty"`
+	Players []string     `json:"players"`
+	Status  MatchStatus  `json:"status"`
+	Winner  string       `json:"winner,omitempty"`
+	Results []DuelResult `json:"results,omitempty"`
+}
+
+// TournamentRound gives every match of the round the same questions.
+type TournamentRound struct {
+	Number      int               `json:"number"`
+	QuestionIDs []int             `json:"question_ids"`
+	StartedAt   time.Time         `json:"started_at"`
+	DeadlineAt  time.Time         `json:"deadline_at"`
+	Matches     []TournamentMatch `json:"matches"`
+}
+
+type Tournament struct {
+	TournamentID string           `json:"tournament_id"`
+	Name         string           `json:"name"`
+	Format       TournamentFormat `json:"format"`
+	Status       TournamentStatus `json:"status"`
+	// Rounds is the number of rounds the tournament will have once started
+	Rounds      int                `json:"rounds,omitempty"`
+	Questions   int                `json:"questions"`
+	Players     []TournamentPlayer `json:"players"`
+	Bracket     []TournamentRound  `json:"bracket"`
+	CreatedAt   time.Time          `json:"created_at"`
+	StartedAt   time.Time          `json:"started_at,omitempty"`
+	CompletedAt time.Time          `json:"completed_at,omitempty"`
+	Winner      string             `json:"winner,omitempty"`
+}
+
+// Player returns the registered player with the username.
+func (t Tournament) Player(username string) (TournamentPlayer, bool) {
+	for _, player := range t.Players {
+		if player.Username == username {
+			return player, true
+		}
+	}
+	return TournamentPlayer{}, false
+}
+
+// TournamentPayload creates a tournament. Rounds is only used by Swiss
+// tournaments, single elimination plays until one player is left.
+type TournamentPayload struct {
+	Name      string           `json:"name"`
+	Format    TournamentFormat `json:"format"`
+	Rounds    int              `json:"rounds"`
+	Questions int              `json:"questions"`
+}
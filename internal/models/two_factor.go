@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// TwoFactor is the TOTP (RFC 6238) second login step of a user.
+type TwoFactor struct {
+	// Secret is the base32 TOTP key, only an enrollment until Enabled
+	Secret    string
+	Enabled   bool
+	EnabledAt time.Time
+	// LastStep is the time step of the last accepted code, so that every
+	// code works once
+	LastStep int64
+	// RecoveryCodes are the hashes of the unused recovery codes
+	RecoveryCodes []string
+}
+
+// TwoFactorStatus tells the user whether two-factor authentication is on.
+type TwoFactorStatus struct {
+	Enabled bool `json:"enabled"`
+	// Required is set for accounts that may not turn it off
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment is the key to add to an authenticator app.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodePayload carries a code from the authenticator app or, where
+// accepted, a recovery code.
+type TwoFactorCodePayload struct {
+	Code string `json:"code"`
+}
+
+// RecoveryCodes are shown once; each works once instead of a TOTP code.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginChallenge answers a correct password of an account with two-factor
+// authentication on. The login finishes with the challenge token and a code.
+type LoginChallenge struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// TwoFactorLoginPayload finishes a login with a TOTP or recovery code.
+type TwoFactorLoginPayload struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
@@ -1,5 +1,11 @@
 package models
 
+// Roles of users. Users without a role are players.
+const (
+	RolePlayer = "player"
+	RoleAdmin  = "admin"
+)
+
 type User struct {
 	UserID     string  `json:"userID"`
 	Username   string  `json:"username"`
@@ -8,4 +14,37 @@ type User struct {
 	Score      int     `json:"score"`
 	QuizTaken  int     `json:"quizTaken"`
 	Percentage float64 `json:"percentage"`
+
+	ActiveAttemptID string `json:"activeAttemptID,omitempty"`
+	Role            string `json:"role,omitempty"`
+	// Email is where password resets go once EmailVerified
+	Email         string    `json:"email,omitempty"`
+	EmailVerified bool      `json:"email_verified,omitempty"`
+	TwoFactor     TwoFactor `json:"-"`
+
+	Profile PlayerProfile `json:"profile"`
+}
+
+// PlayerProfile is what a player builds up across every quiz: experience
+// points, the level they give and the daily-play streak.
+type PlayerProfile struct {
+	XP            int `json:"xp"`
+	Level         int `json:"level"`
+	NextLevelXP   int `json:"next_level_xp"`
+	DayStreak     int `json:"day_streak"`
+	BestDayStreak int `json:"best_day_streak"`
+	// FreezeTokens keep the streak alive over a day without play
+	FreezeTokens int `json:"freeze_tokens"`
+	// LastPlayedDay is the day, as 2006-01-02, of the last completed attempt
+	LastPlayedDay string `json:"last_played_day,omitempty"`
+	// Rating is the Elo rating from duels, DefaultRating until the first one
+	Rating int `json:"rating,omitempty"`
+}
+
+// DuelRating returns the rating of the player, DefaultRating when unrated.
+func (p PlayerProfile) DuelRating() int {
+	if p.Rating == 0 {
+		return DefaultRating
+	}
+	return p.Rating
 }
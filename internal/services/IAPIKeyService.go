@@ -0,0 +1,10 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IAPIKeyService interface {
+	CreateKey(username string, payload models.APIKeyPayload) (*models.NewAPIKey, error)
+	ListKeys(username string) ([]models.APIKey, error)
+	RevokeKey(username, keyID string) error
+	Authenticate(key string) (*models.APIKey, error)
+}
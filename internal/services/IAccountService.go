@@ -0,0 +1,10 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IAccountService interface {
+	SetEmail(username, currentPassword, email string) error
+	VerifyEmail(token string) error
+	RequestPasswordReset(payload models.ForgotPasswordPayload, ip string) error
+	ResetPassword(token, newPassword string) error
+}
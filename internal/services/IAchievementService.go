@@ -0,0 +1,8 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IAchievementService interface {
+	GetAchievements(username string) ([]models.AchievementStatus, error)
+	TakeNewUnlocks(username string) []models.AchievementUnlock
+}
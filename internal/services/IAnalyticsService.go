@@ -0,0 +1,7 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IAnalyticsService interface {
+	QuestionAnalytics() ([]models.QuestionAnalytics, error)
+}
@@ -1,7 +1,16 @@
 package services
 
+import "github.com/Dzsodie/quiz_app/internal/models"
+
 type IAuthService interface {
 	RegisterUser(username, password string) error
 	AuthenticateUser(username, password string) error
+	Login(username, password, ip string) error
 	GetUserID(username string) (string, error)
+	Logout(sessionToken string)
+	RevokeSessions(username string) (int, error)
+	ListSessions(username string) ([]models.Session, error)
+	ChangePassword(username, currentPassword, newPassword, keepSessionToken, ip string) (int, error)
+	UnlockUser(username, admin string) (bool, error)
+	ListAuditEvents(username string, limit int) []models.AuditEvent
 }
@@ -0,0 +1,8 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IDailyService interface {
+	Today() (*models.DailyChallenge, error)
+	History() []models.DailyChallenge
+}
@@ -0,0 +1,10 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IDuelService interface {
+	Challenge(challenger string, payload models.DuelChallengePayload) (*models.Duel, error)
+	Respond(duelID, username string, accept bool) (*models.Duel, error)
+	GetDuel(duelID, username string) (*models.Duel, error)
+	ListDuels(username string) ([]models.Duel, error)
+}
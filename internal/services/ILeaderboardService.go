@@ -0,0 +1,7 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type ILeaderboardService interface {
+	GetLeaderboard(username string, query models.LeaderboardQuery) (*models.Leaderboard, error)
+}
@@ -0,0 +1,7 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IProgressService interface {
+	GetProgress(username string) (*models.Progress, error)
+}
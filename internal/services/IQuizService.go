@@ -5,9 +5,15 @@ import "github.com/Dzsodie/quiz_app/internal/models"
 type IQuizService interface {
 	GetQuestions() ([]models.Question, error)
 	LoadQuestions(qs []models.Question)
-	StartQuiz(username string) error
+	ListQuizzes() []models.Quiz
+	StartQuiz(username, quizID string) error
 	GetNextQuestion(username string) (*models.Question, error)
-	SubmitAnswer(username string, questionIndex, answer int) (bool, error)
+	SubmitAnswer(username string, questionIndex, answer int) (*models.AnswerResult, error)
+	UseLifeline(username string, questionIndex int, lifeline models.LifelineType) (*models.LifelineResult, error)
+	PauseQuiz(username string) (*models.Attempt, error)
+	ResumeQuiz(username string) (*models.Question, error)
 	GetResults(username string) (int, error)
+	GetLatestAttempt(username string) (*models.Attempt, error)
+	GetCountedScore(username, quizID string) (float64, error)
 	GetStats(username string) ([]models.User, string, error)
 }
@@ -0,0 +1,12 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IRoomService interface {
+	CreateRoom(host string, settings models.RoomSettings) (*models.Room, error)
+	GetRoom(code string) (*models.Room, error)
+	Connect(code, username string) (*RoomSubscription, error)
+	Next(code, username string) error
+	Answer(code, username string, answer int) error
+	End(code, username string) error
+}
@@ -0,0 +1,8 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type IStudyService interface {
+	NextCard(username string) (*models.StudyItem, error)
+	Review(username string, questionIndex, answer, quality int) (*models.StudyReviewResult, error)
+}
@@ -0,0 +1,15 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type ITeamService interface {
+	CreateTeam(payload models.TeamPayload) (*models.Team, error)
+	DeleteTeam(teamID string) error
+	GetTeam(teamID string) (*models.Team, error)
+	ListTeams() ([]models.Team, error)
+	JoinTeam(teamID, username string) (*models.Team, error)
+	LeaveTeam(teamID, username string) (*models.Team, error)
+	AddMember(teamID, username string) (*models.Team, error)
+	RemoveMember(teamID, username string) (*models.Team, error)
+	GetTeamLeaderboard(username string, query models.TeamLeaderboardQuery) (*models.TeamLeaderboard, error)
+}
@@ -0,0 +1,10 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type ITokenService interface {
+	Login(username, password, ip string) (*models.TokenPair, *models.LoginChallenge, error)
+	CompleteLogin(challengeToken, code, ip string) (*models.TokenPair, error)
+	Refresh(refreshToken string) (*models.TokenPair, error)
+	Revoke(refreshToken, accessToken string) error
+}
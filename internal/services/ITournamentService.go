@@ -0,0 +1,11 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type ITournamentService interface {
+	CreateTournament(payload models.TournamentPayload) (*models.Tournament, error)
+	Register(tournamentID, username string) (*models.Tournament, error)
+	Start(tournamentID string) (*models.Tournament, error)
+	GetTournament(tournamentID string) (*models.Tournament, error)
+	ListTournaments() ([]models.Tournament, error)
+}
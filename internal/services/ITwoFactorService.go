@@ -0,0 +1,13 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+type ITwoFactorService interface {
+	Status(username string) (*models.TwoFactorStatus, error)
+	Enroll(username string) (*models.TwoFactorEnrollment, error)
+	Confirm(username, code, keepSessionToken string) (*models.RecoveryCodes, error)
+	RegenerateRecoveryCodes(username, code string) (*models.RecoveryCodes, error)
+	Disable(username, code string) error
+	StartLogin(username string) (*models.LoginChallenge, error)
+	CompleteLogin(challengeToken, code, ip string) (string, error)
+}
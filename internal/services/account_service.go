@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultPasswordResetTTL = 30 * time.Minute
+	DefaultEmailVerifyTTL   = 48 * time.Hour
+)
+
+var (
+	ErrInvalidEmail        = errors.New("invalid email address")
+	ErrEmailTaken          = errors.New("email address is used by another account")
+	ErrInvalidAccountToken = errors.New("invalid, used or expired token")
+)
+
+// accountMu makes every account token single-use.
+var accountMu sync.Mutex
+
+// AccountService verifies email addresses and resets forgotten passwords
+// through single-use tokens sent by email.
+type AccountService struct {
+	DB     *database.MemoryDB
+	Mailer utils.Mailer
+	Clock  func() time.Time
+	// ResetTTL is how long a password reset token works
+	ResetTTL time.Duration
+	// VerifyTTL is how long an email verification token works
+	VerifyTTL time.Duration
+	// Limiter caps the password reset requests per IP address and per account
+	Limiter *LoginLimiter
+
+	// mailing counts the reset emails still being sent
+	mailing sync.WaitGroup
+}
+
+func NewAccountService(db *database.MemoryDB, mailer utils.Mailer) *AccountService {
+	return &AccountService{DB: db, Mailer: mailer, ResetTTL: DefaultPasswordResetTTL, VerifyTTL: DefaultEmailVerifyTTL}
+}
+
+func (s *AccountService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// SetEmail gives the user a new, unverified email address and mails a token
+// to verify it, after checking the current password. The previous address is
+// told about the change, since it no longer receives password resets.
+func (s *AccountService) SetEmail(username, currentPassword, email string) error {
+	logger := utils.GetLogger().Sugar()
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != strings.TrimSpace(email) {
+		return ErrInvalidEmail
+	}
+	email = strings.ToLower(address.Address)
+
+	authMu.Lock()
+	for _, other := range s.DB.GetAllUsers() {
+		if other.Username != username && other.Email == email {
+			authMu.Unlock()
+			return ErrEmailTaken
+		}
+	}
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		authMu.Unlock()
+		return fmt.Errorf("user not found: %w", err)
+	}
+	if !utils.ComparePassword(user.Password, currentPassword) {
+		authMu.Unlock()
+		logger.Warn("Email change refused: wrong current password", zap.String("username", username))
+		return ErrWrongPassword
+	}
+	previous := user.Email
+	user.Email = email
+	user.EmailVerified = false
+	err = s.DB.UpdateUser(user)
+	authMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	token, err := s.issue(username, models.TokenPurposeVerifyEmail, email, s.VerifyTTL)
+	if err != nil {
+		return err
+	}
+	if err := s.Mailer.Send(utils.Email{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nconfirm this address for your quiz account by sending this token to POST /email/verify within %s:\n\n%s\n\nIf you did not ask for this, ignore this email.",
+			username, s.VerifyTTL, token),
+	}); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	if previous != "" && previous != email {
+		if err := s.Mailer.Send(utils.Email{
+			To:      previous,
+			Subject: "Your email address was changed",
+			Body: fmt.Sprintf("Hello %s,\n\nthe email address of your quiz account was changed to %s. Password resets are no longer sent to this address.\n\nIf you did not do this, change your password and contact an administrator.",
+				username, email),
+		}); err != nil {
+			// The change is done; the notice is best effort
+			logger.Error("Failed to notify the previous email address", zap.String("username", username), zap.Error(err))
+		}
+	}
+
+	logger.Info("Email verification sent", zap.String("username", username))
+	return nil
+}
+
+// Wait blocks until the password reset emails being sent are out. The server
+// calls it on shutdown so that no reset email is lost.
+func (s *AccountService) Wait() {
+	s.mailing.Wait()
+}
+
+// VerifyEmail marks the address of a verification token as verified.
+func (s *AccountService) VerifyEmail(token string) error {
+	accountToken, err := s.consume(token, models.TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	authMu.Lock()
+	defer authMu.Unlock()
+	user, err := s.DB.GetUser(accountToken.Username)
+	// The address changed again after the token was sent
+	if err != nil || user.Email != accountToken.Email {
+		return ErrInvalidAccountToken
+	}
+	user.EmailVerified = true
+	if err := s.DB.UpdateUser(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	utils.GetLogger().Sugar().Info("Email verified", zap.String("username", user.Username))
+	return nil
+}
+
+// RequestPasswordReset mails a reset token to the verified address of the
+// account named by username or email. It says nothing about whether the
+// account exists, so that accounts cannot be discovered through it: the
+// email is sent in the background, so the answer takes as long either way,
+// and requests for unknown accounts count towards the limits too.
+func (s *AccountService) RequestPasswordReset(payload models.ForgotPasswordPayload, ip string) error {
+	logger := utils.GetLogger().Sugar()
+
+	user, found := s.findAccount(payload)
+	if s.Limiter != nil {
+		key := strings.ToLower(payload.Username + payload.Email)
+		if found {
+			key = user.Username
+		}
+		if err := s.Limiter.Allow(key, ip); err != nil {
+			logger.Warn("Password reset request refused", zap.String("ip", ip), zap.Error(err))
+			return err
+		}
+	}
+	if !found || user.Email == "" || !user.EmailVerified {
+		logger.Info("Password reset requested for an account without a verified email")
+		return nil
+	}
+
+	token, err := s.issue(user.Username, models.TokenPurposePasswordReset, user.Email, s.ResetTTL)
+	if err != nil {
+		return err
+	}
+	email := utils.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nsomeone asked to reset the password of your quiz account. To choose a new password, send this token with it to POST /password/reset within %s:\n\n%s\n\nThe token works once. If you did not ask for this, ignore this email; your password stays the same.",
+			user.Username, s.ResetTTL, token),
+	}
+	s.mailing.Add(1)
+	go func() {
+		defer s.mailing.Done()
+		if err := s.Mailer.Send(email); err != nil {
+			// Not reported to the caller, which would tell that the account exists
+			logger.Error("Failed to send password reset email", zap.String("username", user.Username), zap.Error(err))
+			return
+		}
+		logger.Info("Password reset sent", zap.String("username", user.Username))
+	}()
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and logs the user out
+// everywhere.
+func (s *AccountService) ResetPassword(token, newPassword string) error {
+	logger := utils.GetLogger().Sugar()
+
+	// Checked first so that a weak password does not use the token up
+	if err := utils.ValidatePassword(newPassword, ""); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPassword, err)
+	}
+	accountToken, err := s.consume(token, models.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	authMu.Lock()
+	user, err := s.DB.GetUser(accountToken.Username)
+	if err != nil {
+		authMu.Unlock()
+		return ErrInvalidAccountToken
+	}
+	user.Password = hashedPassword
+	err = s.DB.UpdateUser(user)
+	authMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if _, err := utils.RevokeUserSessions(user.Username); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if _, err := revokeUserTokens(s.DB, user.Username, s.now()); err != nil {
+		return err
+	}
+
+	logger.Info("Password reset", zap.String("username", user.Username))
+	return nil
+}
+
+func (s *AccountService) findAccount(payload models.ForgotPasswordPayload) (database.User, bool) {
+	if payload.Username != "" {
+		user, err := s.DB.GetUser(payload.Username)
+		return user, err == nil
+	}
+	email := strings.ToLower(strings.TrimSpace(payload.Email))
+	if email == "" {
+		return database.User{}, false
+	}
+	for _, user := range s.DB.GetAllUsers() {
+		if user.Email == email {
+			return user, true
+		}
+	}
+	return database.User{}, false
+}
+
+// issue stores a new token for the purpose and retires the unused older ones,
+// so that only the latest email works.
+func (s *AccountService) issue(username, purpose, email string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateSessionToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	accountMu.Lock()
+	defer accountMu.Unlock()
+
+	now := s.now()
+	for _, old := range s.DB.ListAccountTokens(username) {
+		if old.Purpose == purpose && old.UsedAt.IsZero() {
+			old.UsedAt = now
+			if err := s.DB.UpdateAccountToken(old); err != nil {
+				return "", fmt.Errorf("failed to retire token: %w", err)
+			}
+		}
+	}
+	if err := s.DB.AddAccountToken(database.AccountToken{
+		TokenHash: utils.HashToken(token),
+		Purpose:   purpose,
+		Username:  username,
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, nil
+}
+
+// consume uses a token up and returns it.
+func (s *AccountService) consume(token, purpose string) (database.AccountToken, error) {
+	accountMu.Lock()
+	defer accountMu.Unlock()
+
+	accountToken, err := s.DB.GetAccountToken(utils.HashToken(token))
+	if errors.Is(err, database.ErrAccountTokenNotFound) {
+		return database.AccountToken{}, ErrInvalidAccountToken
+	} else if err != nil {
+		return database.AccountToken{}, err
+	}
+	now := s.now()
+	if accountToken.Purpose != purpose || !accountToken.UsedAt.IsZero() || !now.Before(accountToken.ExpiresAt) {
+		return database.AccountToken{}, ErrInvalidAccountToken
+	}
+	accountToken.UsedAt = now
+	if err := s.DB.UpdateAccountToken(accountToken); err != nil {
+		return database.AccountToken{}, fmt.Errorf("failed to use token: %w", err)
+	}
+	return accountToken, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// outbox is a mailer that keeps the emails for the test to read.
+type outbox struct {
+	mu     sync.Mutex
+	emails []utils.Email
+}
+
+func (o *outbox) Send(email utils.Email) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails = append(o.emails, email)
+	return nil
+}
+
+var mailedToken = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// lastToken returns the token of the last email.
+func (o *outbox) lastToken(t *testing.T) string {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !assert.NotEmpty(t, o.emails) {
+		return ""
+	}
+	return mailedToken.FindString(o.emails[len(o.emails)-1].Body)
+}
+
+func TestEmailVerification(t *testing.T) {
+	clock, now := newTestClock()
+	mails := &outbox{}
+	s := NewAccountService(newTestAuthService(t, "alice", "bob").DB, mails)
+	s.Clock = clock
+
+	assert.ErrorIs(t, s.SetEmail("alice", "Password123!", "not an email"), ErrInvalidEmail)
+	assert.NoError(t, s.SetEmail("alice", "Password123!", "Alice@Example.com"))
+	assert.ErrorIs(t, s.SetEmail("bob", "Password123!", "alice@example.com"), ErrEmailTaken)
+	assert.Equal(t, "alice@example.com", mails.emails[0].To)
+	first := mails.lastToken(t)
+
+	// Asking again retires the first token
+	assert.NoError(t, s.SetEmail("alice", "Password123!", "alice@example.com"))
+	assert.ErrorIs(t, s.VerifyEmail(first), ErrInvalidAccountToken)
+	second := mails.lastToken(t)
+
+	*now = now.Add(s.VerifyTTL)
+	assert.ErrorIs(t, s.VerifyEmail(second), ErrInvalidAccountToken, "expected the token to expire")
+
+	assert.NoError(t, s.SetEmail("alice", "Password123!", "alice@example.com"))
+	third := mails.lastToken(t)
+	assert.NoError(t, s.VerifyEmail(third))
+	assert.ErrorIs(t, s.VerifyEmail(third), ErrInvalidAccountToken, "expected the token to work once")
+	user, _ := s.DB.GetUser("alice")
+	assert.True(t, user.EmailVerified)
+
+	assert.ErrorIs(t, s.SetEmail("alice", "Wrong123!", "mallory@example.org"), ErrWrongPassword)
+	assert.NoError(t, s.SetEmail("alice", "Password123!", "alice@example.org"))
+	user, _ = s.DB.GetUser("alice")
+	assert.False(t, user.EmailVerified, "expected a new address to need verifying")
+	assert.Equal(t, "alice@example.org", user.Email)
+	notice := mails.emails[len(mails.emails)-1]
+	assert.Equal(t, "alice@example.com", notice.To, "expected the previous address to be told about the change")
+	assert.Contains(t, notice.Body, "alice@example.org")
+}
+
+func TestPasswordReset(t *testing.T) {
+	clock, now := newTestClock()
+	mails := &outbox{}
+	s := NewAccountService(newTestAuthService(t, "alice", "bob").DB, mails)
+	s.Clock = clock
+
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "alice"}, ""))
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "nobody"}, ""))
+	s.Wait()
+	assert.Empty(t, mails.emails, "expected no email without a verified address")
+
+	assert.NoError(t, s.SetEmail("alice", "Password123!", "alice@example.com"))
+	assert.NoError(t, s.VerifyEmail(mails.lastToken(t)))
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Email: "ALICE@example.com"}, ""))
+	s.Wait()
+	token := mails.lastToken(t)
+	assert.Equal(t, "Reset your password", mails.emails[len(mails.emails)-1].Subject)
+	assert.ErrorIs(t, s.VerifyEmail(token), ErrInvalidAccountToken, "expected a reset token not to verify emails")
+
+	assert.NoError(t, utils.SaveSession("alice-laptop", "alice", "", ""))
+	assert.ErrorIs(t, s.ResetPassword(token, "weak"), ErrInvalidPassword)
+	assert.NoError(t, s.ResetPassword(token, "Changed@456"), "expected a weak password not to use the token up")
+	assert.ErrorIs(t, s.ResetPassword(token, "Changed@789"), ErrInvalidAccountToken)
+
+	auth := NewAuthService(s.DB)
+	assert.NoError(t, auth.AuthenticateUser("alice", "Changed@456"))
+	_, ok := utils.LookupSession("alice-laptop")
+	assert.False(t, ok, "expected the reset to log the user out everywhere")
+
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "alice"}, ""))
+	s.Wait()
+	*now = now.Add(s.ResetTTL)
+	assert.ErrorIs(t, s.ResetPassword(mails.lastToken(t), "Changed@789"), ErrInvalidAccountToken, "expected the token to expire")
+}
+
+func TestPasswordResetLimits(t *testing.T) {
+	clock, _ := newTestClock()
+	mails := &outbox{}
+	s := NewAccountService(newTestAuthService(t, "alice", "bob").DB, mails)
+	s.Clock = clock
+	limiter, limiterNow := newTestLimiter(LoginLimitConfig{IPAttempts: 5, IPWindow: time.Minute, UserAttempts: 2, UserWindow: time.Hour})
+	s.Limiter = limiter
+	assert.NoError(t, s.SetEmail("alice", "Password123!", "alice@example.com"))
+	assert.NoError(t, s.VerifyEmail(mails.lastToken(t)))
+
+	// By username and by email address count for the same account
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "alice"}, "192.0.2.1"))
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Email: "alice@example.com"}, "192.0.2.2"))
+	assertLimited(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "alice"}, "192.0.2.3"), ErrLoginRateLimited, limiterNow.Add(time.Hour))
+	s.Wait()
+	assert.Len(t, mails.emails, 3, "expected the verification and two reset emails")
+
+	// Unknown accounts are limited the same way
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "nobody"}, "192.0.2.1"))
+	assert.NoError(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "nobody"}, "192.0.2.1"))
+	assertLimited(t, s.RequestPasswordReset(models.ForgotPasswordPayload{Username: "nobody"}, "192.0.2.1"), ErrLoginRateLimited, limiterNow.Add(time.Hour))
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+// AchievementService unlocks the declared achievements as players answer
+// questions and complete attempts.
+type AchievementService struct {
+	DB           *database.MemoryDB
+	Achievements []models.Achievement
+	Clock        func() time.Time
+	Location     *time.Location
+
+	mu sync.Mutex
+}
+
+func NewAchievementService(db *database.MemoryDB, achievements []models.Achievement) *AchievementService {
+	return &AchievementService{DB: db, Achievements: achievements, Location: time.UTC}
+}
+
+func (s *AchievementService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// AnswerSubmitted evaluates the achievements of the player after an answer.
+func (s *AchievementService) AnswerSubmitted(attempt models.Attempt, _ models.AnswerRecord) {
+	s.evaluate(attempt.Username)
+}
+
+// AttemptCompleted evaluates the achievements of the player after an attempt.
+func (s *AchievementService) AttemptCompleted(attempt models.Attempt) {
+	s.evaluate(attempt.Username)
+}
+
+// playerRecord sums up the history of a player for the achievement rules.
+type playerRecord struct {
+	completed      int
+	perfectScore   bool
+	correctAnswers int
+	dayStreak      int
+	answeredRight  map[int]bool
+}
+
+// evaluate unlocks every achievement the player newly earned. It runs from the
+// quiz listeners, so the quiz lock is held and the questions can be read.
+func (s *AchievementService) evaluate(username string) {
+	logger := utils.GetLogger().Sugar()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlocked := make(map[string]bool)
+	for _, unlock := range s.DB.ListAchievementUnlocks(username) {
+		unlocked[unlock.AchievementID] = true
+	}
+
+	var record *playerRecord
+	now := s.now()
+	for _, achievement := range s.Achievements {
+		if unlocked[achievement.AchievementID] {
+			continue
+		}
+		if record == nil {
+			record = s.playerRecord(username, now)
+		}
+		if !achievementMet(achievement, record) {
+			continue
+		}
+
+		unlock := database.AchievementUnlock{
+			AchievementID: achievement.AchievementID,
+			Title:         achievement.Title,
+			Username:      username,
+			UnlockedAt:    now,
+		}
+		if err := s.DB.SaveAchievementUnlock(unlock); err != nil {
+			logger.Error("Failed to save achievement unlock", zap.String("username", username), zap.String("achievementID", achievement.AchievementID), zap.Error(err))
+			continue
+		}
+		logger.Info("Achievement unlocked", zap.String("username", username), zap.String("achievementID", achievement.AchievementID))
+	}
+}
+
+func (s *AchievementService) playerRecord(username string, now time.Time) *playerRecord {
+	record := &playerRecord{answeredRight: make(map[int]bool)}
+	daysPlayed := make(map[string]bool)
+	for _, attempt := range s.DB.ListAttempts(username) {
+		allRight := len(attempt.Answers) > 0
+		for _, answer := range attempt.Answers {
+			if !answer.Correct {
+				allRight = false
+			}
+			if answer.AnsweredAt.IsZero() {
+				continue
+			}
+			daysPlayed[answer.AnsweredAt.In(s.Location).Format("2006-01-02")] = true
+			if answer.Correct {
+				record.correctAnswers++
+				record.answeredRight[answer.QuestionID] = true
+			}
+		}
+		if attempt.Status == models.AttemptCompleted {
+			record.completed++
+			record.perfectScore = record.perfectScore || allRight
+		}
+	}
+	record.dayStreak = dayStreak(daysPlayed, now.In(s.Location))
+	return record
+}
+
+func achievementMet(achievement models.Achievement, record *playerRecord) bool {
+	switch achievement.Rule {
+	case models.RulePerfectScore:
+		return record.perfectScore
+	case models.RuleQuizzesCompleted:
+		return record.completed >= achievement.Count
+	case models.RuleCorrectAnswers:
+		return record.correctAnswers >= achievement.Count
+	case models.RuleDayStreak:
+		return record.dayStreak >= achievement.Count
+	case models.RuleTopicMastered:
+		inTopic := 0
+		for _, question := range questions {
+			if !questionInTopic(question, achievement) {
+				continue
+			}
+			if !record.answeredRight[question.QuestionID] {
+				return false
+			}
+			inTopic++
+		}
+		return inTopic > 0
+	}
+	return false
+}
+
+func questionInTopic(question models.Question, achievement models.Achievement) bool {
+	if achievement.Category != "" && question.Category != achievement.Category {
+		return false
+	}
+	if achievement.Tag == "" {
+		return true
+	}
+	for _, tag := range question.Tags {
+		if tag == achievement.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAchievements lists every achievement with whether and when the user unlocked it.
+func (s *AchievementService) GetAchievements(username string) ([]models.AchievementStatus, error) {
+	if _, err := s.DB.GetUser(username); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	unlocks := make(map[string]database.AchievementUnlock)
+	for _, unlock := range s.DB.ListAchievementUnlocks(username) {
+		unlocks[unlock.AchievementID] = unlock
+	}
+
+	statuses := make([]models.AchievementStatus, 0, len(s.Achievements))
+	for _, achievement := range s.Achievements {
+		status := models.AchievementStatus{Achievement: achievement}
+		if unlock, ok := unlocks[achievement.AchievementID]; ok {
+			unlockedAt := unlock.UnlockedAt
+			status.Unlocked = true
+			status.UnlockedAt = &unlockedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// TakeNewUnlocks returns the unlocks of the user that were not announced yet
+// and marks them as announced.
+func (s *AchievementService) TakeNewUnlocks(username string) []models.AchievementUnlock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fresh []models.AchievementUnlock
+	for _, unlock := range s.DB.ListAchievementUnlocks(username) {
+		if unlock.Announced {
+			continue
+		}
+		unlock.Announced = true
+		if err := s.DB.SaveAchievementUnlock(unlock); err != nil {
+			continue
+		}
+		fresh = append(fresh, models.AchievementUnlock(unlock))
+	}
+	return fresh
+}
+
+var (
+	_ IAchievementService = &AchievementService{}
+	_ AttemptListener     = &AchievementService{}
+	_ AnswerListener      = &AchievementService{}
+)
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAchievementService(t *testing.T) {
+	db := database.NewMemoryDB()
+	quizService := NewQuizService(db)
+	quizService.LoadQuestions([]models.Question{
+		{QuestionID: 1, Question: "Leia?", Options: []string{"a", "b", "c"}, Answer: 1, Tags: []string{"star wars"}},
+		{QuestionID: 2, Question: "Go?", Options: []string{"a", "b", "c"}, Answer: 2, Tags: []string{"go"}},
+	})
+	db.AddUser(database.User{Username: "testuser"})
+
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	s := NewAchievementService(db, []models.Achievement{
+		{AchievementID: "perfect", Title: "Flawless", Rule: models.RulePerfectScore},
+		{AchievementID: "jedi", Title: "Jedi", Rule: models.RuleTopicMastered, Tag: "star wars"},
+		{AchievementID: "streak", Title: "On a roll", Rule: models.RuleDayStreak, Count: 2},
+		{AchievementID: "regular", Title: "Regular", Rule: models.RuleQuizzesCompleted, Count: 2},
+	})
+	s.Clock = func() time.Time { return now }
+
+	yesterday := now.AddDate(0, 0, -1)
+	db.AddAttempt(database.Attempt{
+		AttemptID: "first", Username: "testuser", QuizID: "general", Status: models.AttemptActive, StartedAt: yesterday,
+		Answers: []models.AnswerRecord{{QuestionIndex: 0, QuestionID: 1, Correct: true, AnsweredAt: yesterday}},
+	})
+	s.AnswerSubmitted(models.Attempt{Username: "testuser"}, models.AnswerRecord{})
+	assert.Equal(t, []models.AchievementUnlock{{AchievementID: "jedi", Title: "Jedi", Username: "testuser", UnlockedAt: now, Announced: true}}, s.TakeNewUnlocks("testuser"))
+	assert.Empty(t, s.TakeNewUnlocks("testuser"), "expected an unlock to be announced once")
+
+	db.UpdateAttempt(database.Attempt{
+		AttemptID: "first", Username: "testuser", QuizID: "general", Status: models.AttemptCompleted, Score: 2, StartedAt: yesterday,
+		Answers: []models.AnswerRecord{
+			{QuestionIndex: 0, QuestionID: 1, Correct: true, AnsweredAt: yesterday},
+			{QuestionIndex: 1, QuestionID: 2, Correct: true, AnsweredAt: now},
+		},
+	})
+	s.AttemptCompleted(models.Attempt{Username: "testuser"})
+	unlocks := s.TakeNewUnlocks("testuser")
+	assert.Len(t, unlocks, 2)
+	assert.ElementsMatch(t, []string{"perfect", "streak"}, []string{unlocks[0].AchievementID, unlocks[1].AchievementID})
+
+	statuses, err := s.GetAchievements("testuser")
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 4)
+	assert.True(t, statuses[0].Unlocked)
+	assert.Equal(t, now, *statuses[0].UnlockedAt)
+	assert.False(t, statuses[3].Unlocked)
+	assert.Nil(t, statuses[3].UnlockedAt)
+
+	_, err = s.GetAchievements("nobody")
+	assert.Error(t, err)
+}
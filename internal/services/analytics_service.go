@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+// AnalyticsService reports how the questions perform, so that broken or
+// misleading questions can be fixed.
+type AnalyticsService struct {
+	DB *database.MemoryDB
+}
+
+func NewAnalyticsService(db *database.MemoryDB) *AnalyticsService {
+	return &AnalyticsService{DB: db}
+}
+
+// itemResponse is one answer to a question together with the total score of
+// the attempt it was given in.
+type itemResponse struct {
+	correct    bool
+	totalScore float64
+}
+
+// QuestionAnalytics runs an item analysis of every loaded question over the
+// answered questions of completed attempts.
+func (s *AnalyticsService) QuestionAnalytics() ([]models.QuestionAnalytics, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	if len(questions) == 0 {
+		logger.Warn("No questions available to analyse")
+		return nil, errors.New("no questions available")
+	}
+
+	report := make([]models.QuestionAnalytics, len(questions))
+	byID := make(map[int]int, len(questions))
+	for i, question := range questions {
+		byID[question.QuestionID] = i
+		report[i] = models.QuestionAnalytics{
+			QuestionID:   question.QuestionID,
+			Question:     question.Question,
+			OptionCounts: make([]int, len(question.Options)),
+		}
+	}
+
+	responses := make([][]itemResponse, len(questions))
+	answerTime := make([]float64, len(questions))
+	timed := make([]int, len(questions))
+	for _, attempt := range s.DB.ListAllAttempts() {
+		if attempt.Status != models.AttemptCompleted {
+			continue
+		}
+		for _, record := range attempt.Answers {
+			i, known := byID[record.QuestionID]
+			if !known || record.AnsweredAt.IsZero() {
+				continue
+			}
+			responses[i] = append(responses[i], itemResponse{correct: record.Correct, totalScore: float64(attempt.Score)})
+			if record.Answer >= 1 && record.Answer <= len(report[i].OptionCounts) {
+				report[i].OptionCounts[record.Answer-1]++
+			}
+			if !record.ServedAt.IsZero() {
+				answerTime[i] += record.AnsweredAt.Sub(record.ServedAt).Seconds()
+				timed[i]++
+			}
+		}
+	}
+
+	for i, question := range questions {
+		item := &report[i]
+		item.Responses = len(responses[i])
+		if item.Responses == 0 {
+			continue
+		}
+
+		correct := 0
+		for _, response := range responses[i] {
+			if response.correct {
+				correct++
+			}
+		}
+		item.PValue = float64(correct) / float64(item.Responses)
+		item.Discrimination = pointBiserial(responses[i])
+		if timed[i] > 0 {
+			item.AverageAnswerSeconds = answerTime[i] / float64(timed[i])
+		}
+
+		keyed := item.OptionCounts[question.Answer-1]
+		for option, count := range item.OptionCounts {
+			if option != question.Answer-1 && count > keyed {
+				item.Flagged = true
+				item.FlagReason = fmt.Sprintf("option %d is picked more often than the keyed answer %d", option+1, question.Answer)
+				break
+			}
+		}
+	}
+
+	logger.Info("Question analytics computed", zap.Int("questions", len(report)))
+	return report, nil
+}
+
+// pointBiserial correlates answering an item right with the total score. It
+// is zero when everybody answered alike or all totals are equal.
+func pointBiserial(responses []itemResponse) float64 {
+	var sumRight, sumWrong, sum float64
+	var right int
+	for _, response := range responses {
+		sum += response.totalScore
+		if response.correct {
+			sumRight += response.totalScore
+			right++
+		} else {
+			sumWrong += response.totalScore
+		}
+	}
+	n := len(responses)
+	wrong := n - right
+	if right == 0 || wrong == 0 {
+		return 0
+	}
+
+	mean := sum / float64(n)
+	var variance float64
+	for _, response := range responses {
+		variance += (response.totalScore - mean) * (response.totalScore - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(n))
+	if stdDev == 0 {
+		return 0
+	}
+
+	p := float64(right) / float64(n)
+	meanRight := sumRight / float64(right)
+	meanWrong := sumWrong / float64(wrong)
+	return (meanRight - meanWrong) / stdDev * math.Sqrt(p*(1-p))
+}
+
+var _ IAnalyticsService = &AnalyticsService{}
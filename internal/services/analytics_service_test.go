@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuestionAnalytics(t *testing.T) {
+	db := database.NewMemoryDB()
+	NewQuizService(db).LoadQuestions([]models.Question{
+		{QuestionID: 1, Question: "Good question", Options: []string{"a", "b", "c"}, Answer: 1},
+		{QuestionID: 2, Question: "Misleading question", Options: []string{"a", "b", "c"}, Answer: 2},
+		{QuestionID: 3, Question: "Unanswered question", Options: []string{"a", "b", "c"}, Answer: 3},
+	})
+	s := NewAnalyticsService(db)
+
+	servedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// The strong players answer the first question right and fall for option 3 of the second
+	answers := []struct {
+		score          int
+		first, second  int
+		secondsToFirst int
+	}{
+		{score: 2, first: 1, second: 3, secondsToFirst: 10},
+		{score: 2, first: 1, second: 3, secondsToFirst: 20},
+		{score: 1, first: 2, second: 2, secondsToFirst: 30},
+		{score: 0, first: 3, second: 1, secondsToFirst: 40},
+	}
+	for i, answer := range answers {
+		db.AddAttempt(database.Attempt{
+			AttemptID: fmt.Sprintf("attempt-%d", i),
+			Username:  fmt.Sprintf("player-%d", i),
+			Status:    models.AttemptCompleted,
+			Score:     answer.score,
+			Answers: []models.AnswerRecord{
+				{QuestionID: 1, Answer: answer.first, Correct: answer.first == 1, ServedAt: servedAt, AnsweredAt: servedAt.Add(time.Duration(answer.secondsToFirst) * time.Second)},
+				{QuestionIndex: 1, QuestionID: 2, Answer: answer.second, Correct: answer.second == 2, AnsweredAt: servedAt},
+				{QuestionIndex: 2, QuestionID: 3, Skipped: true},
+			},
+		})
+	}
+	// Attempts still in progress are not analysed
+	db.AddAttempt(database.Attempt{AttemptID: "active", Status: models.AttemptActive, Answers: []models.AnswerRecord{{QuestionID: 1, Answer: 3, AnsweredAt: servedAt}}})
+
+	report, err := s.QuestionAnalytics()
+	assert.NoError(t, err)
+	assert.Len(t, report, 3)
+
+	good := report[0]
+	assert.Equal(t, 4, good.Responses)
+	assert.Equal(t, 0.5, good.PValue)
+	assert.Equal(t, []int{2, 1, 1}, good.OptionCounts)
+	assert.Equal(t, 25.0, good.AverageAnswerSeconds)
+	assert.Greater(t, good.Discrimination, 0.8)
+	assert.False(t, good.Flagged)
+
+	misleading := report[1]
+	assert.Equal(t, 0.25, misleading.PValue)
+	assert.Equal(t, []int{1, 1, 2}, misleading.OptionCounts)
+	assert.Zero(t, misleading.AverageAnswerSeconds, "expected answers without a serve time not to be timed")
+	assert.True(t, misleading.Flagged)
+	assert.Contains(t, misleading.FlagReason, "option 3")
+
+	assert.Zero(t, report[2].Responses, "expected skipped questions not to count as responses")
+	assert.Equal(t, []int{0, 0, 0}, report[2].OptionCounts)
+}
+
+func TestPointBiserial(t *testing.T) {
+	assert.Zero(t, pointBiserial([]itemResponse{{correct: true, totalScore: 3}, {correct: true, totalScore: 1}}), "expected no discrimination when everybody is right")
+	assert.InDelta(t, -1.0, pointBiserial([]itemResponse{{correct: true, totalScore: 0}, {correct: false, totalScore: 2}}), 1e-9)
+}
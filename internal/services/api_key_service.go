@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// apiKeyPrefix starts every key so that leaked keys are easy to search for.
+const apiKeyPrefix = "qk_"
+
+var (
+	ErrInvalidAPIKeyName   = errors.New("API key needs a name of at most 64 characters")
+	ErrInvalidAPIKeyScope  = errors.New("API key needs scopes out of " + strings.Join(models.APIKeyScopes, ", "))
+	ErrInvalidAPIKeyExpiry = errors.New("API key expiry must be a positive number of days")
+	ErrAPIKeyUnauthorized  = errors.New("invalid or expired API key")
+)
+
+// APIKeyService manages the personal API keys of the users and checks the
+// keys of incoming requests.
+type APIKeyService struct {
+	DB    *database.MemoryDB
+	Clock func() time.Time
+}
+
+func NewAPIKeyService(db *database.MemoryDB) *APIKeyService {
+	return &APIKeyService{DB: db}
+}
+
+func (s *APIKeyService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// CreateKey creates a key for the user. The key itself is only in the result.
+func (s *APIKeyService) CreateKey(username string, payload models.APIKeyPayload) (*models.NewAPIKey, error) {
+	logger := utils.GetLogger().Sugar()
+
+	name := strings.TrimSpace(payload.Name)
+	if name == "" || len(name) > 64 {
+		return nil, ErrInvalidAPIKeyName
+	}
+	if len(payload.Scopes) == 0 {
+		return nil, ErrInvalidAPIKeyScope
+	}
+	var scopes []string
+	for _, scope := range payload.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyScope, scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if payload.ExpiresInDays < 0 {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+	if _, err := s.DB.GetUser(username); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	secret, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + secret
+	now := s.now()
+	apiKey := models.APIKey{
+		KeyID:     uuid.New().String(),
+		Name:      name,
+		Username:  username,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+	if payload.ExpiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, payload.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := s.DB.AddAPIKey(database.APIKey(apiKey)); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	logger.Info("API key created", zap.String("username", username), zap.String("keyID", apiKey.KeyID), zap.Strings("scopes", scopes))
+	return &models.NewAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// ListKeys returns the keys of the user, the oldest first.
+func (s *APIKeyService) ListKeys(username string) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	for _, key := range s.DB.ListAPIKeys(username) {
+		keys = append(keys, models.APIKey(key))
+	}
+	return keys, nil
+}
+
+// RevokeKey deletes a key of the user. Keys of other users are not found.
+func (s *APIKeyService) RevokeKey(username, keyID string) error {
+	key, err := s.DB.GetAPIKey(keyID)
+	if err != nil {
+		return err
+	}
+	if key.Username != username {
+		return database.ErrAPIKeyNotFound
+	}
+	if err := s.DB.DeleteAPIKey(keyID); err != nil {
+		return err
+	}
+
+	utils.GetLogger().Sugar().Info("API key revoked", zap.String("username", username), zap.String("keyID", keyID))
+	return nil
+}
+
+// Authenticate returns the key of a request and records that it was used.
+func (s *APIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrAPIKeyUnauthorized
+	}
+	apiKey, err := s.DB.FindAPIKey(utils.HashToken(key))
+	if errors.Is(err, database.ErrAPIKeyNotFound) {
+		return nil, ErrAPIKeyUnauthorized
+	} else if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, ErrAPIKeyUnauthorized
+	}
+
+	apiKey.LastUsedAt = &now
+	// Fails when the key was revoked in the meantime rather than bringing it back
+	if err := s.DB.UpdateAPIKey(apiKey); errors.Is(err, database.ErrAPIKeyNotFound) {
+		return nil, ErrAPIKeyUnauthorized
+	} else if err != nil {
+		return nil, err
+	}
+	result := models.APIKey(apiKey)
+	return &result, nil
+}
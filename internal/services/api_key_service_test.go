@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeys(t *testing.T) {
+	db := database.NewMemoryDB()
+	db.AddUser(database.User{Username: "ci"})
+	db.AddUser(database.User{Username: "other"})
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	s := NewAPIKeyService(db)
+	s.Clock = func() time.Time { return now }
+
+	_, err := s.CreateKey("ci", models.APIKeyPayload{Name: "", Scopes: []string{models.ScopeRead}})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyName)
+	_, err = s.CreateKey("ci", models.APIKeyPayload{Name: "seed", Scopes: []string{"write"}})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyScope)
+	_, err = s.CreateKey("ci", models.APIKeyPayload{Name: "seed", Scopes: []string{models.ScopeRead}, ExpiresInDays: -1})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyExpiry)
+
+	created, err := s.CreateKey("ci", models.APIKeyPayload{Name: "analytics", Scopes: []string{models.ScopeAnalytics, models.ScopeAnalytics}, ExpiresInDays: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.ScopeAnalytics}, created.Scopes)
+	assert.Contains(t, created.Key, created.Prefix)
+	stored, _ := db.GetAPIKey(created.KeyID)
+	assert.NotEqual(t, created.Key, stored.KeyHash, "expected only the hash to be stored")
+
+	now = now.Add(time.Hour)
+	key, err := s.Authenticate(created.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", key.Username)
+	keys, err := s.ListKeys("ci")
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 1) && assert.NotNil(t, keys[0].LastUsedAt) {
+		assert.Equal(t, now, *keys[0].LastUsedAt)
+	}
+	_, err = s.Authenticate(created.Key + "x")
+	assert.ErrorIs(t, err, ErrAPIKeyUnauthorized)
+
+	now = now.AddDate(0, 0, 7)
+	_, err = s.Authenticate(created.Key)
+	assert.ErrorIs(t, err, ErrAPIKeyUnauthorized, "expected the key to expire")
+
+	forever, err := s.CreateKey("ci", models.APIKeyPayload{Name: "seed", Scopes: []string{models.ScopeAdmin}})
+	assert.NoError(t, err)
+	assert.Nil(t, forever.ExpiresAt)
+	assert.ErrorIs(t, s.RevokeKey("other", forever.KeyID), database.ErrAPIKeyNotFound, "expected keys of other users to be hidden")
+	assert.NoError(t, s.RevokeKey("ci", forever.KeyID))
+	_, err = s.Authenticate(forever.Key)
+	assert.ErrorIs(t, err, ErrAPIKeyUnauthorized)
+}
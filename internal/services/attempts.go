@@ -0,0 +1,370 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+var ErrNothingToResume = errors.New("no paused quiz to resume")
+
+// DefaultQuiz is played when no quiz has been registered under models.DefaultQuizID.
+func DefaultQuiz() models.Quiz {
+	return models.Quiz{
+		QuizID:           models.DefaultQuizID,
+		Title:            "General knowledge",
+		Mode:             models.QuizModeStandard,
+		TimeLimitSeconds: 600,
+		PauseTimer:       true,
+		ResumePolicy:     models.ResumeRepeat,
+	}
+}
+
+func (s *QuizService) RegisterQuiz(quiz models.Quiz) error {
+	logger := utils.GetLogger().Sugar()
+	if quiz.ResumePolicy == "" {
+		quiz.ResumePolicy = models.ResumeRepeat
+	}
+	if quiz.Mode == "" {
+		quiz.Mode = models.QuizModeStandard
+	}
+	if quiz.ScoringPolicy == "" {
+		quiz.ScoringPolicy = models.ScoreBest
+	}
+	if err := s.DB.AddQuiz(database.Quiz(quiz)); err != nil {
+		logger.Error("Failed to register quiz", zap.String("quizID", quiz.QuizID), zap.Error(err))
+		return fmt.Errorf("failed to register quiz: %w", err)
+	}
+	logger.Info("Quiz registered", zap.String("quizID", quiz.QuizID))
+	return nil
+}
+
+// ListQuizzes returns the quizzes players can start, without the unlisted
+// ones such as daily challenges and duels.
+func (s *QuizService) ListQuizzes() []models.Quiz {
+	var quizzes []models.Quiz
+	hasDefault := false
+	for _, quiz := range s.DB.ListQuizzes() {
+		if quiz.Unlisted {
+			continue
+		}
+		hasDefault = hasDefault || quiz.QuizID == models.DefaultQuizID
+		quizzes = append(quizzes, models.Quiz(quiz))
+	}
+	if !hasDefault {
+		quizzes = append([]models.Quiz{DefaultQuiz()}, quizzes...)
+	}
+	return quizzes
+}
+
+func (s *QuizService) getQuiz(quizID string) (models.Quiz, error) {
+	if quizID == "" {
+		quizID = models.DefaultQuizID
+	}
+	quiz, err := s.DB.GetQuiz(quizID)
+	if err == nil {
+		return models.Quiz(quiz), nil
+	}
+	if quizID == models.DefaultQuizID {
+		return DefaultQuiz(), nil
+	}
+	return models.Quiz{}, err
+}
+
+// PauseQuiz parks the user's attempt. The time limit stops too when the quiz allows it.
+func (s *QuizService) PauseQuiz(username string) (*models.Attempt, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	attempt, err := s.activeAttempt(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := attemptPlayable(attempt); err != nil {
+		logger.Warn("Attempt cannot be paused", zap.String("username", username), zap.Error(err))
+		return nil, err
+	}
+
+	quiz, err := s.getQuiz(attempt.QuizID)
+	if err != nil {
+		return nil, fmt.Errorf("quiz not found: %w", err)
+	}
+
+	now := s.now()
+	attempt.Status = models.AttemptPaused
+	attempt.PausedAt = now
+	if !attempt.Deadline.IsZero() {
+		attempt.TimeRemaining = attempt.Deadline.Sub(now)
+		if quiz.PauseTimer {
+			stopTimer(username)
+		}
+	}
+
+	if err := s.DB.UpdateAttempt(attempt); err != nil {
+		logger.Error("Failed to pause attempt", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("failed to update attempt: %w", err)
+	}
+
+	logger.Info("Quiz paused",
+		zap.String("username", username),
+		zap.String("attemptID", attempt.AttemptID),
+		zap.Bool("timerPaused", quiz.PauseTimer))
+	paused := models.Attempt(attempt)
+	return &paused, nil
+}
+
+// ResumeQuiz continues the user's attempt from any session and returns the
+// question to answer next. A question that was served but not answered before
+// pausing is handled according to the quiz's resume policy.
+func (s *QuizService) ResumeQuiz(username string) (*models.Question, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	attempt, err := s.activeAttempt(user)
+	if err != nil {
+		return nil, ErrNothingToResume
+	}
+	switch attempt.Status {
+	case models.AttemptCompleted:
+		return nil, ErrQuizComplete
+	case models.AttemptExpired:
+		return nil, ErrAttemptExpired
+	case models.AttemptAbandoned:
+		return nil, ErrNothingToResume
+	}
+
+	quiz, err := s.getQuiz(attempt.QuizID)
+	if err != nil {
+		return nil, fmt.Errorf("quiz not found: %w", err)
+	}
+
+	now := s.now()
+	wasPaused := attempt.Status == models.AttemptPaused
+	if wasPaused {
+		if !attempt.Deadline.IsZero() {
+			if quiz.PauseTimer {
+				attempt.Deadline = now.Add(attempt.TimeRemaining)
+				s.startTimer(username, attempt.AttemptID, attempt.TimeRemaining)
+			} else if now.After(attempt.Deadline) {
+				s.expireAttempt(username, attempt.AttemptID)
+				return nil, ErrAttemptExpired
+			}
+		}
+		attempt.Status = models.AttemptActive
+		attempt.PausedAt = time.Time{}
+		attempt.TimeRemaining = 0
+	}
+
+	if pending := pendingRecord(&attempt); pending != nil {
+		if !wasPaused || quiz.ResumePolicy != models.ResumeSkip {
+			pending.ServedAt = now
+			if err := s.DB.UpdateAttempt(attempt); err != nil {
+				logger.Error("Failed to resume attempt", zap.String("username", username), zap.Error(err))
+				return nil, fmt.Errorf("failed to update attempt: %w", err)
+			}
+			logger.Info("Quiz resumed at unanswered question",
+				zap.String("username", username),
+				zap.Int("questionIndex", pending.QuestionIndex))
+			return servedQuestion(questions[pending.QuestionIndex]), nil
+		}
+		pending.Skipped = true
+		logger.Info("Unanswered question skipped on resume",
+			zap.String("username", username),
+			zap.Int("questionIndex", pending.QuestionIndex))
+	}
+
+	logger.Info("Quiz resumed", zap.String("username", username), zap.String("attemptID", attempt.AttemptID))
+	return s.serveNext(&user, &attempt)
+}
+
+// activeAttempt loads the latest attempt of the user, whatever its status.
+func (s *QuizService) activeAttempt(user database.User) (database.Attempt, error) {
+	if user.ActiveAttemptID == "" {
+		return database.Attempt{}, ErrNoActiveAttempt
+	}
+	attempt, err := s.DB.GetAttempt(user.ActiveAttemptID)
+	if err != nil {
+		return database.Attempt{}, ErrNoActiveAttempt
+	}
+	return attempt, nil
+}
+
+// attemptPlayable reports why an attempt cannot take answers right now.
+func attemptPlayable(attempt database.Attempt) error {
+	switch attempt.Status {
+	case models.AttemptPaused:
+		return ErrAttemptPaused
+	case models.AttemptExpired:
+		return ErrAttemptExpired
+	case models.AttemptCompleted:
+		return ErrQuizComplete
+	case models.AttemptAbandoned:
+		return ErrNoActiveAttempt
+	}
+	return nil
+}
+
+// serveNext records the question picked by the quiz's strategy on the attempt
+// and persists both the attempt and the user. The attempt is completed when
+// the strategy has no question left to serve.
+// Callers must hold quizMu.
+func (s *QuizService) serveNext(user *database.User, attempt *database.Attempt) (*models.Question, error) {
+	logger := utils.GetLogger().Sugar()
+
+	quiz, err := s.getQuiz(attempt.QuizID)
+	if err != nil {
+		logger.Error("Quiz of attempt not found", zap.String("quizID", attempt.QuizID), zap.Error(err))
+		return nil, fmt.Errorf("quiz not found: %w", err)
+	}
+
+	index := strategyFor(quiz).next(quiz, *attempt)
+	if index < 0 {
+		logger.Warn("No more questions available for user", zap.String("username", user.Username))
+		completeAttempt(attempt, s.now())
+		stopTimer(user.Username)
+		if attempt.Mode == models.QuizModeExam && attempt.Ranked {
+			user.Score += attempt.Score
+		}
+		// The XP and streak are stored together with the completed attempt
+		attempt.XPEarned = s.Progression.award(&user.Profile, models.Attempt(*attempt))
+		if err := s.DB.CompleteAttempt(*attempt, *user); err != nil {
+			logger.Error("Failed to complete attempt", zap.String("username", user.Username), zap.Error(err))
+			return nil, fmt.Errorf("failed to update attempt: %w", err)
+		}
+		s.notifyAttemptCompleted(models.Attempt(*attempt))
+		return nil, ErrQuizComplete
+	}
+
+	// Retrieve the next question
+	question := servedQuestion(questions[index])
+	logger.Info("Next question retrieved", zap.String("username", user.Username), zap.Int("progress", index))
+
+	attempt.Answers = append(attempt.Answers, models.AnswerRecord{
+		QuestionIndex: index,
+		QuestionID:    question.QuestionID,
+		ServedAt:      s.now(),
+	})
+	if err := s.DB.UpdateAttempt(*attempt); err != nil {
+		logger.Error("Failed to update attempt progress", zap.String("username", user.Username), zap.Error(err))
+		return nil, fmt.Errorf("failed to update attempt: %w", err)
+	}
+
+	// Update user's progress
+	user.Progress = append(user.Progress, question.QuestionID)
+	if err := s.DB.UpdateUser(*user); err != nil {
+		logger.Error("Failed to update user progress in database", zap.String("username", user.Username), zap.Error(err))
+		return nil, fmt.Errorf("failed to update user progress: %w", err)
+	}
+
+	return question, nil
+}
+
+// servedQuestion copies a question for the player, keeping the hint behind
+// the lifeline and the explanation until the question is answered.
+func servedQuestion(question models.Question) *models.Question {
+	question.Hint = ""
+	question.Explanation = ""
+	return &question
+}
+
+func completeAttempt(attempt *database.Attempt, now time.Time) {
+	for i := range attempt.Answers {
+		if attempt.Answers[i].Pending() {
+			attempt.Answers[i].Skipped = true
+		}
+	}
+	attempt.Status = models.AttemptCompleted
+	attempt.CompletedAt = now
+}
+
+func findRecord(attempt *database.Attempt, questionIndex int) *models.AnswerRecord {
+	for i := range attempt.Answers {
+		if attempt.Answers[i].QuestionIndex == questionIndex {
+			return &attempt.Answers[i]
+		}
+	}
+	return nil
+}
+
+// pendingRecord returns the most recently served question that is still waiting for an answer.
+func pendingRecord(attempt *database.Attempt) *models.AnswerRecord {
+	for i := len(attempt.Answers) - 1; i >= 0; i-- {
+		if attempt.Answers[i].Pending() {
+			return &attempt.Answers[i]
+		}
+	}
+	return nil
+}
+
+// startTimer (re)arms the time limit of an attempt. Callers must hold quizMu.
+func (s *QuizService) startTimer(username, attemptID string, limit time.Duration) {
+	if stopTimer(username) {
+		utils.GetLogger().Sugar().Warn("Existing quiz session timer stopped", zap.String("username", username))
+	}
+	if limit <= 0 {
+		return
+	}
+	quizTimers[username] = time.AfterFunc(limit, func() {
+		quizMu.Lock()
+		defer quizMu.Unlock()
+		s.expireAttempt(username, attemptID)
+	})
+}
+
+// stopTimer disarms the time limit of the user's attempt. Callers must hold quizMu.
+func stopTimer(username string) bool {
+	timer, exists := quizTimers[username]
+	if !exists {
+		return false
+	}
+	timer.Stop()
+	delete(quizTimers, username)
+	return true
+}
+
+// expireAttempt closes an attempt whose time limit ran out. Callers must hold quizMu.
+func (s *QuizService) expireAttempt(username, attemptID string) {
+	logger := utils.GetLogger().Sugar()
+
+	attempt, err := s.DB.GetAttempt(attemptID)
+	if err != nil || (attempt.Status != models.AttemptActive && attempt.Status != models.AttemptPaused) {
+		return
+	}
+
+	// Cleanup expired quiz session
+	logger.Info("Quiz session expired", zap.String("username", username))
+	attempt.Status = models.AttemptExpired
+	if err := s.DB.UpdateAttempt(attempt); err != nil {
+		logger.Error("Failed to expire attempt", zap.String("username", username), zap.Error(err))
+	}
+
+	user, err := s.DB.GetUser(username)
+	if err != nil || user.ActiveAttemptID != attemptID {
+		return
+	}
+	stopTimer(username)
+	user.Progress = []int{}
+	user.Score = 0
+	if err := s.DB.UpdateUser(user); err != nil {
+		logger.Error("Failed to reset user progress on quiz expiry", zap.String("username", username), zap.Error(err))
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizServicePauseBlocksPlay(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(DefaultQuiz()))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+
+	_, err := s.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+
+	attempt, err := s.PauseQuiz("testuser")
+	assert.NoError(t, err, "expected no error when pausing")
+	assert.Equal(t, models.AttemptPaused, attempt.Status)
+	assert.Greater(t, attempt.TimeRemaining, time.Duration(0), "expected remaining time to be kept")
+
+	_, err = s.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrAttemptPaused)
+	_, err = s.SubmitAnswer("testuser", 0, 2)
+	assert.ErrorIs(t, err, ErrAttemptPaused)
+	_, err = s.PauseQuiz("testuser")
+	assert.ErrorIs(t, err, ErrAttemptPaused)
+}
+
+func TestQuizServiceResumeRepeatsUnansweredQuestion(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(DefaultQuiz()))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	db := s.DB
+
+	_, _ = s.GetNextQuestion("testuser")
+	_, err := s.SubmitAnswer("testuser", 0, 2)
+	assert.NoError(t, err)
+	served, _ := s.GetNextQuestion("testuser")
+	_, err = s.PauseQuiz("testuser")
+	assert.NoError(t, err)
+
+	question, err := s.ResumeQuiz("testuser")
+	assert.NoError(t, err, "expected no error when resuming")
+	assert.Equal(t, served.QuestionID, question.QuestionID, "expected the unanswered question to be served again")
+
+	user, _ := db.GetUser("testuser")
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	assert.Equal(t, models.AttemptActive, attempt.Status)
+	assert.Len(t, attempt.Answers, 2)
+	assert.Equal(t, CorrectAnswerPoints, attempt.Score)
+}
+
+func TestQuizServiceResumeSkipsUnansweredQuestion(t *testing.T) {
+	quiz := DefaultQuiz()
+	quiz.ResumePolicy = models.ResumeSkip
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(quiz))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	db := s.DB
+
+	_, _ = s.GetNextQuestion("testuser")
+	_, err := s.PauseQuiz("testuser")
+	assert.NoError(t, err)
+
+	question, err := s.ResumeQuiz("testuser")
+	assert.NoError(t, err, "expected no error when resuming")
+	assert.Equal(t, 2, question.QuestionID, "expected the unanswered question to be skipped")
+
+	user, _ := db.GetUser("testuser")
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	assert.True(t, attempt.Answers[0].Skipped)
+}
+
+func TestQuizServiceResumeAfterTimeLimit(t *testing.T) {
+	quiz := DefaultQuiz()
+	quiz.PauseTimer = false
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(quiz))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	db := s.DB
+
+	_, _ = s.GetNextQuestion("testuser")
+	_, err := s.PauseQuiz("testuser")
+	assert.NoError(t, err)
+
+	user, _ := db.GetUser("testuser")
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	attempt.Deadline = time.Now().Add(-time.Second)
+	assert.NoError(t, db.UpdateAttempt(attempt))
+
+	_, err = s.ResumeQuiz("testuser")
+	assert.ErrorIs(t, err, ErrAttemptExpired, "expected the running time limit to expire the attempt")
+}
+
+func TestQuizServiceResumeFromNewSession(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(DefaultQuiz()))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+
+	_, err := s.ResumeQuiz("testuser")
+	assert.NoError(t, err, "expected an attempt in progress to be resumable without pausing")
+
+	db := database.NewMemoryDB()
+	other := NewQuizService(db)
+	db.AddUser(database.User{Username: "newuser"})
+	_, err = other.ResumeQuiz("newuser")
+	assert.ErrorIs(t, err, ErrNothingToResume)
+}
+
+func TestQuizServiceCompletesAttempt(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(DefaultQuiz()))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	db := s.DB
+
+	for i := 0; i < 3; i++ {
+		_, err := s.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+	}
+	_, err := s.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+
+	user, _ := db.GetUser("testuser")
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	assert.Equal(t, models.AttemptCompleted, attempt.Status)
+	assert.False(t, attempt.CompletedAt.IsZero())
+
+	_, err = s.PauseQuiz("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"net/http"
 
 	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
 	"github.com/Dzsodie/quiz_app/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/sessions"
@@ -18,14 +20,29 @@ var (
 	authMu sync.Mutex
 )
 
+var (
+	ErrWrongPassword   = errors.New("current password is incorrect")
+	ErrInvalidPassword = errors.New("invalid password")
+)
+
 type AuthService struct {
-	DB *database.MemoryDB
+	DB    *database.MemoryDB
+	Clock func() time.Time
+	// Limiter throttles Login and ChangePassword; without one neither is limited
+	Limiter *LoginLimiter
 }
 
 func NewAuthService(db *database.MemoryDB) *AuthService {
 	return &AuthService{DB: db}
 }
 
+func (s *AuthService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
 func (s *AuthService) RegisterUser(username, password string) error {
 	logger := utils.GetLogger().Sugar()
 
@@ -35,7 +52,7 @@ func (s *AuthService) RegisterUser(username, password string) error {
 		return fmt.Errorf("invalid username: %w", err)
 	}
 
-	if err := utils.ValidatePassword(password, "Password must contain at least one uppercase letter"); err != nil {
+	if err := utils.ValidatePassword(password, ""); err != nil {
 		logger.Warn("Invalid password", zap.String("username", username), zap.Error(err))
 		return fmt.Errorf("invalid password: %w", err)
 	}
@@ -94,6 +111,92 @@ func (s *AuthService) AuthenticateUser(username, password string) error {
 	return nil
 }
 
+// Login checks the credentials of a login attempt from ip. Attempts over the
+// limits of the limiter are refused with a *LoginLimitError before the
+// password is checked. Every attempt ends up in the audit log.
+func (s *AuthService) Login(username, password, ip string) error {
+	logger := utils.GetLogger().Sugar()
+
+	if s.Limiter != nil {
+		if err := s.Limiter.Allow(username, ip); err != nil {
+			logger.Warn("Login throttled", zap.String("username", username), zap.String("ip", ip), zap.Error(err))
+			s.audit(models.AuditLoginThrottled, username, ip, "", err.Error())
+			return err
+		}
+	}
+
+	if err := s.AuthenticateUser(username, password); err != nil {
+		s.audit(models.AuditLoginFailed, username, ip, "", "")
+		if s.Limiter != nil {
+			if lockedUntil, locked := s.Limiter.Fail(username); locked {
+				logger.Warn("Account locked", zap.String("username", username), zap.Time("until", lockedUntil))
+				s.audit(models.AuditAccountLocked, username, ip, "", "locked until "+lockedUntil.Format(time.RFC3339))
+			}
+		}
+		return err
+	}
+
+	// With two-factor authentication on, the failures are only forgiven once
+	// the second step passes too, or the password would reset the lockout
+	// of someone guessing codes
+	if user, err := s.DB.GetUser(username); err == nil && user.TwoFactor.Enabled {
+		s.audit(models.AuditLoginSucceeded, username, ip, "", "password, two-factor code pending")
+		return nil
+	}
+	if s.Limiter != nil {
+		s.Limiter.Succeed(username)
+	}
+	s.audit(models.AuditLoginSucceeded, username, ip, "", "")
+	return nil
+}
+
+// UnlockUser lifts the login lockout and delays of a user on behalf of an
+// admin. It reports whether the user was locked.
+func (s *AuthService) UnlockUser(username, admin string) (bool, error) {
+	if _, err := s.DB.GetUser(username); err != nil {
+		return false, fmt.Errorf("user not found: %w", err)
+	}
+	locked := false
+	if s.Limiter != nil {
+		locked = s.Limiter.Unlock(username)
+	}
+	s.audit(models.AuditAccountUnlocked, username, "", admin, "")
+
+	utils.GetLogger().Sugar().Info("User unlocked", zap.String("username", username), zap.String("admin", admin), zap.Bool("wasLocked", locked))
+	return locked, nil
+}
+
+// ListAuditEvents returns the latest audit events, the newest first, about a
+// user or about everyone when username is empty. limit caps how many.
+func (s *AuthService) ListAuditEvents(username string, limit int) []models.AuditEvent {
+	stored := s.DB.ListAuditEvents(username)
+	events := []models.AuditEvent{}
+	for i := len(stored) - 1; i >= 0 && (limit <= 0 || len(events) < limit); i-- {
+		events = append(events, models.AuditEvent(stored[i]))
+	}
+	return events
+}
+
+func (s *AuthService) audit(eventType, username, ip, actor, detail string) {
+	recordAudit(s.DB, s.now(), eventType, username, ip, actor, detail)
+}
+
+// recordAudit records an event in the audit log. A failure is only logged, so
+// that it never decides a login.
+func recordAudit(db *database.MemoryDB, now time.Time, eventType, username, ip, actor, detail string) {
+	if err := db.AddAuditEvent(database.AuditEvent{
+		EventID:  uuid.NewString(),
+		Type:     eventType,
+		Username: username,
+		IP:       ip,
+		Actor:    actor,
+		Detail:   detail,
+		Time:     now,
+	}); err != nil {
+		utils.GetLogger().Sugar().Error("Failed to record audit event", zap.String("type", eventType), zap.Error(err))
+	}
+}
+
 func (s *AuthService) GetUserID(username string) (string, error) {
 	authMu.Lock()
 	defer authMu.Unlock()
@@ -105,6 +208,138 @@ func (s *AuthService) GetUserID(username string) (string, error) {
 	return user.UserID, nil
 }
 
+// SetRole changes the role of a user, for example to make them an admin.
+func (s *AuthService) SetRole(username, role string) error {
+	logger := utils.GetLogger().Sugar()
+	authMu.Lock()
+	defer authMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	user.Role = role
+	if err := s.DB.UpdateUser(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	logger.Info("User role changed", zap.String("username", username), zap.String("role", role))
+	return nil
+}
+
+// ChangePassword replaces the password of the user after checking the current
+// one. The new password must follow the password policy and differ from the
+// current one. Every other session of the user and every bearer token are
+// revoked; keepSessionToken is the session that stays logged in. Returns how
+// many sessions and token logins were revoked.
+// Checking the current password counts against the login limits like a login
+// from ip, so a stolen session cannot guess it faster.
+func (s *AuthService) ChangePassword(username, currentPassword, newPassword, keepSessionToken, ip string) (int, error) {
+	logger := utils.GetLogger().Sugar()
+
+	if s.Limiter != nil {
+		if err := s.Limiter.Allow(username, ip); err != nil {
+			logger.Warn("Password change throttled", zap.String("username", username), zap.String("ip", ip), zap.Error(err))
+			s.audit(models.AuditPasswordChangeFailed, username, ip, "", err.Error())
+			return 0, err
+		}
+	}
+
+	authMu.Lock()
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		authMu.Unlock()
+		return 0, fmt.Errorf("user not found: %w", err)
+	}
+	if !utils.ComparePassword(user.Password, currentPassword) {
+		authMu.Unlock()
+		logger.Warn("Password change refused: wrong current password", zap.String("username", username))
+		s.audit(models.AuditPasswordChangeFailed, username, ip, "", "wrong current password")
+		if s.Limiter != nil {
+			if lockedUntil, locked := s.Limiter.Fail(username); locked {
+				logger.Warn("Account locked", zap.String("username", username), zap.Time("until", lockedUntil))
+				s.audit(models.AuditAccountLocked, username, ip, "", "locked until "+lockedUntil.Format(time.RFC3339))
+			}
+		}
+		return 0, ErrWrongPassword
+	}
+	if err := utils.ValidatePassword(newPassword, currentPassword); err != nil {
+		authMu.Unlock()
+		return 0, fmt.Errorf("%w: %v", ErrInvalidPassword, err)
+	}
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		authMu.Unlock()
+		return 0, fmt.Errorf("error hashing password: %w", err)
+	}
+	user.Password = hashedPassword
+	err = s.DB.UpdateUser(user)
+	authMu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	revoked, err := utils.RevokeOtherSessions(username, keepSessionToken)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	families, err := revokeUserTokens(s.DB, username, s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	s.audit(models.AuditPasswordChanged, username, ip, "", "")
+	logger.Info("Password changed", zap.String("username", username), zap.Int("revoked", revoked+families))
+	return revoked + families, nil
+}
+
+// Logout revokes the session token of a single login.
+func (s *AuthService) Logout(sessionToken string) {
+	logger := utils.GetLogger().Sugar()
+
+	if err := utils.RevokeSession(sessionToken); err != nil {
+		logger.Error("Failed to revoke session", zap.Error(err))
+		return
+	}
+	logger.Info("Session revoked")
+}
+
+// RevokeSessions logs the user out everywhere, bearer tokens included, and
+// returns how many sessions and token logins were still valid.
+func (s *AuthService) RevokeSessions(username string) (int, error) {
+	logger := utils.GetLogger().Sugar()
+
+	if _, err := s.DB.GetUser(username); err != nil {
+		return 0, fmt.Errorf("user not found: %w", err)
+	}
+	revoked, err := utils.RevokeUserSessions(username)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	// API clients are logged out too
+	families, err := revokeUserTokens(s.DB, username, s.now())
+	if err != nil {
+		return 0, err
+	}
+	revoked += families
+
+	logger.Info("User sessions revoked", zap.String("username", username), zap.Int("revoked", revoked))
+	return revoked, nil
+}
+
+// ListSessions returns the sessions of the user that are still valid, the
+// oldest first.
+func (s *AuthService) ListSessions(username string) ([]models.Session, error) {
+	if _, err := s.DB.GetUser(username); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	sessions, err := utils.UserSessions(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
 func (s *AuthService) GetSession(r *http.Request) (*sessions.Session, error) {
 	logger := utils.GetLogger().Sugar()
 
@@ -3,8 +3,11 @@ package services
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -104,3 +107,96 @@ func TestAuthServiceConcurrency(t *testing.T) {
 		assert.Equal(t, username, user.Username)
 	}
 }
+
+func TestAuthServiceSetRole(t *testing.T) {
+	db := database.NewMemoryDB()
+	authService := NewAuthService(db)
+	assert.NoError(t, authService.RegisterUser("testuser", "Password123!"))
+
+	assert.NoError(t, authService.SetRole("testuser", models.RoleAdmin))
+	user, _ := db.GetUser("testuser")
+	assert.Equal(t, models.RoleAdmin, user.Role)
+
+	assert.Error(t, authService.SetRole("nobody", models.RoleAdmin))
+}
+
+func TestAuthServiceRevokeSessions(t *testing.T) {
+	db := database.NewMemoryDB()
+	authService := NewAuthService(db)
+	assert.NoError(t, authService.RegisterUser("sessionuser", "Password123!"))
+
+	assert.NoError(t, utils.SaveSession("phone", "sessionuser", "10.0.0.1", "Phone"))
+	assert.NoError(t, utils.SaveSession("laptop", "sessionuser", "10.0.0.2", "Laptop"))
+	authService.Logout("phone")
+	sessions, err := authService.ListSessions("sessionuser")
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, "Laptop", sessions[0].UserAgent)
+	}
+	_, ok := utils.LookupSession("phone")
+	assert.False(t, ok)
+
+	revoked, err := authService.RevokeSessions("sessionuser")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+	_, ok = utils.LookupSession("laptop")
+	assert.False(t, ok)
+
+	_, err = authService.RevokeSessions("nobody")
+	assert.ErrorIs(t, err, database.ErrUserNotFound)
+}
+
+func TestAuthServiceChangePassword(t *testing.T) {
+	assert.NoError(t, utils.SetJWTKeys([]utils.JWTKey{{ID: "test", Secret: []byte("test-secret")}}))
+	authService := newTestAuthService(t, "tokenuser")
+	clock, _ := newTestClock()
+	authService.Clock = clock
+	tokens := NewTokenService(authService.DB, authService)
+	tokens.Clock = clock
+	assert.NoError(t, utils.SaveSession("this-device", "tokenuser", "", ""))
+	assert.NoError(t, utils.SaveSession("other-device", "tokenuser", "", ""))
+	pair, _, err := tokens.Login("tokenuser", "Password123!", "")
+	assert.NoError(t, err)
+
+	_, err = authService.ChangePassword("tokenuser", "wrong", "Changed@456", "this-device", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+	_, err = authService.ChangePassword("tokenuser", "Password123!", "Password123?", "this-device", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidPassword, "expected the new password to differ by 2 characters")
+	_, err = authService.ChangePassword("tokenuser", "Password123!", "weak", "this-device", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+
+	revoked, err := authService.ChangePassword("tokenuser", "Password123!", "Changed@456", "this-device", "192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, revoked, "expected the other session and the token login to go")
+	_, ok := utils.LookupSession("this-device")
+	assert.True(t, ok, "expected the session that changed the password to stay")
+	_, ok = utils.LookupSession("other-device")
+	assert.False(t, ok)
+	_, err = tokens.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	assert.Error(t, authService.AuthenticateUser("tokenuser", "Password123!"))
+	assert.NoError(t, authService.AuthenticateUser("tokenuser", "Changed@456"))
+
+	// Guessing the current password counts toward the login lockout
+	limiter, _ := newTestLimiter(LoginLimitConfig{LockoutThreshold: 2, LockoutDuration: 10 * time.Minute})
+	authService.Limiter = limiter
+	for range 2 {
+		_, err = authService.ChangePassword("tokenuser", "wrong", "Changed@789", "this-device", "192.0.2.1")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	}
+	_, err = authService.ChangePassword("tokenuser", "Changed@456", "Changed@789", "this-device", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+
+	var types []string
+	for _, event := range authService.ListAuditEvents("tokenuser", 5) {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{
+		models.AuditPasswordChangeFailed,
+		models.AuditAccountLocked,
+		models.AuditPasswordChangeFailed,
+		models.AuditPasswordChangeFailed,
+		models.AuditPasswordChanged,
+	}, types)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const DefaultDailyQuestions = 5
+
+var ErrNoQuestionsForDaily = errors.New("no questions available for the daily challenge")
+
+// DailyService generates one short quiz a day, picked from the question bank
+// with the date as the seed so that every player gets the same questions. A
+// day starts at GenerateAt after midnight in Location. Each challenge is
+// registered as a quiz that can be played once while its day lasts.
+type DailyService struct {
+	Quiz       *QuizService
+	DB         *database.MemoryDB
+	Clock      func() time.Time
+	Location   *time.Location
+	Questions  int
+	GenerateAt time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func NewDailyService(quizService *QuizService) *DailyService {
+	return &DailyService{Quiz: quizService, DB: quizService.DB, Location: time.UTC, Questions: DefaultDailyQuestions}
+}
+
+func (s *DailyService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// dayStart returns when the challenge day that contains t started.
+func (s *DailyService) dayStart(t time.Time) time.Time {
+	local := t.In(s.Location)
+	year, month, day := local.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, s.Location).Add(s.GenerateAt)
+	if local.Before(start) {
+		start = time.Date(year, month, day-1, 0, 0, 0, 0, s.Location).Add(s.GenerateAt)
+	}
+	return start
+}
+
+// Today returns the challenge of the current day, generating it when needed.
+func (s *DailyService) Today() (*models.DailyChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generate(s.dayStart(s.now()))
+}
+
+// History returns the challenges generated so far, the latest first.
+func (s *DailyService) History() []models.DailyChallenge {
+	stored := s.DB.ListDailyChallenges()
+	challenges := make([]models.DailyChallenge, 0, len(stored))
+	for _, challenge := range stored {
+		challenges = append(challenges, models.DailyChallenge(challenge))
+	}
+	return challenges
+}
+
+// Schedule generates the current challenge and keeps generating the next one
+// when its day starts, until Stop is called.
+func (s *DailyService) Schedule() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduleNext()
+}
+
+// scheduleNext generates the current challenge and arms the timer for the
+// next one. Callers must hold s.mu.
+func (s *DailyService) scheduleNext() {
+	now := s.now()
+	start := s.dayStart(now)
+	if _, err := s.generate(start); err != nil {
+		utils.GetLogger().Sugar().Error("Failed to generate daily challenge", zap.Error(err))
+	}
+	s.timer = time.AfterFunc(s.nextDayStart(start).Sub(now), func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.timer != nil {
+			s.scheduleNext()
+		}
+	})
+}
+
+// Stop ends the scheduling of daily challenges.
+func (s *DailyService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+func (s *DailyService) nextDayStart(start time.Time) time.Time {
+	year, month, day := start.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, s.Location).Add(s.GenerateAt)
+}
+
+// generate returns the stored challenge of the day starting at start, or
+// picks and stores a new one. Callers must hold s.mu.
+func (s *DailyService) generate(start time.Time) (*models.DailyChallenge, error) {
+	logger := utils.GetLogger().Sugar()
+	date := start.Format("2006-01-02")
+	if challenge, err := s.DB.GetDailyChallenge(date); err == nil {
+		result := models.DailyChallenge(challenge)
+		return &result, nil
+	}
+
+	questionIDs := s.pick(date)
+	if len(questionIDs) == 0 {
+		logger.Warn("No questions to generate the daily challenge from", zap.String("date", date))
+		return nil, ErrNoQuestionsForDaily
+	}
+
+	closesAt := s.nextDayStart(start)
+	challenge := models.DailyChallenge{
+		Date:        date,
+		QuizID:      "daily-" + date,
+		QuestionIDs: questionIDs,
+		OpensAt:     start,
+		ClosesAt:    closesAt,
+	}
+	quiz := DefaultQuiz()
+	quiz.QuizID = challenge.QuizID
+	quiz.Title = "Daily challenge " + date
+	quiz.MaxAttempts = 1
+	quiz.OpensAt = &challenge.OpensAt
+	quiz.ClosesAt = &challenge.ClosesAt
+	quiz.ScoringPolicy = models.ScoreBest
+	quiz.QuestionIDs = questionIDs
+	quiz.Daily = date
+	quiz.Unlisted = true
+	if err := s.Quiz.RegisterQuiz(quiz); err != nil {
+		return nil, err
+	}
+	if err := s.DB.AddDailyChallenge(database.DailyChallenge(challenge)); err != nil {
+		logger.Error("Failed to store daily challenge", zap.String("date", date), zap.Error(err))
+		return nil, fmt.Errorf("failed to store daily challenge: %w", err)
+	}
+
+	logger.Info("Daily challenge generated", zap.String("date", date), zap.Ints("questionIDs", questionIDs))
+	return &challenge, nil
+}
+
+// pick chooses the questions of a date. The bank is ordered by question ID and
+// shuffled with a seed taken from the date, so the pick only depends on the
+// date and the bank.
+func (s *DailyService) pick(date string) []int {
+	quizMu.Lock()
+	ids := make([]int, 0, len(questions))
+	for _, question := range questions {
+		ids = append(ids, question.QuestionID)
+	}
+	quizMu.Unlock()
+	sort.Ints(ids)
+
+	hash := fnv.New64a()
+	hash.Write([]byte(date))
+	seed := hash.Sum64()
+	random := rand.New(rand.NewPCG(seed, seed))
+	random.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+
+	if len(ids) > s.Questions && s.Questions > 0 {
+		ids = ids[:s.Questions]
+	}
+	return ids
+}
+
+var _ IDailyService = &DailyService{}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// tenQuestions returns a bank of ten questions to pick challenges from.
+func tenQuestions() []models.Question {
+	var bank []models.Question
+	for id := 1; id <= 10; id++ {
+		bank = append(bank, models.Question{QuestionID: id, Question: "Question?", Options: []string{"a", "b", "c"}, Answer: 1})
+	}
+	return bank
+}
+
+func TestDailyChallengeGeneration(t *testing.T) {
+	quizService := newTestQuizService(t, tenQuestions(), "testuser")
+	clock, now := newTestClock()
+	quizService.Clock = clock
+	s := NewDailyService(quizService)
+	s.Clock = clock
+	s.Questions = 3
+	s.GenerateAt = 13 * time.Hour
+
+	challenge, err := s.Today()
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-01-09", challenge.Date, "expected the previous day before the generation time")
+	assert.Len(t, challenge.QuestionIDs, 3)
+	assert.Equal(t, time.Date(2025, 1, 10, 13, 0, 0, 0, time.UTC), challenge.ClosesAt)
+
+	*now = now.Add(2 * time.Hour)
+	today, err := s.Today()
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-01-10", today.Date)
+	again, _ := s.Today()
+	assert.Equal(t, today.QuestionIDs, again.QuestionIDs, "expected the stored challenge to be returned")
+	assert.Equal(t, today.QuestionIDs, s.pick("2025-01-10"), "expected the pick to depend on the date only")
+
+	history := s.History()
+	assert.Len(t, history, 2)
+	assert.Equal(t, "2025-01-10", history[0].Date)
+
+	for _, quiz := range quizService.ListQuizzes() {
+		assert.Empty(t, quiz.Daily, "expected daily challenges to stay off the quiz list")
+	}
+}
+
+func TestDailyChallengePlayedOnce(t *testing.T) {
+	quizService := newTestQuizService(t, tenQuestions(), "testuser")
+	clock, _ := newTestClock()
+	quizService.Clock = clock
+	s := NewDailyService(quizService)
+	s.Clock = clock
+	s.Questions = 3
+	s.GenerateAt = 13 * time.Hour
+	challenge, err := s.Today()
+	assert.NoError(t, err)
+
+	assert.NoError(t, quizService.StartQuiz("testuser", challenge.QuizID))
+	for _, questionID := range challenge.QuestionIDs {
+		question, err := quizService.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+		assert.Equal(t, questionID, question.QuestionID, "expected the questions of the challenge in order")
+		_, err = quizService.SubmitAnswer("testuser", questionID-1, 1)
+		assert.NoError(t, err)
+	}
+	_, err = quizService.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+
+	assert.Error(t, quizService.StartQuiz("testuser", challenge.QuizID), "expected one attempt per player")
+}
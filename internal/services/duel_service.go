@@ -0,0 +1,367 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultDuelQuestions = 5
+	DefaultEloKFactor    = 32
+	duelQuizPrefix       = "duel-"
+)
+
+var (
+	ErrDuelSelf          = errors.New("players cannot duel themselves")
+	ErrInvalidDuelMode   = errors.New("duel mode must be sync or async")
+	ErrDuelNotPending    = errors.New("duel is no longer waiting for an answer")
+	ErrNotDuelOpponent   = errors.New("only the challenged player can answer the duel")
+	ErrNoQuestionsToDuel = errors.New("no questions available for the duel")
+)
+
+// DuelService runs head-to-head duels. Both players get the same randomly
+// picked questions as a quiz only they can start, once each. The higher score
+// wins and the faster player wins a tie. Results update the Elo ratings.
+//
+// Duels are settled as their attempts complete, and expiries are applied
+// whenever a duel is read.
+type DuelService struct {
+	DB    *database.MemoryDB
+	Quiz  *QuizService
+	Clock func() time.Time
+
+	// InviteTTL is how long an invitation can be accepted
+	InviteTTL time.Duration
+	// SyncWindow and AsyncWindow are how long the players have to play once
+	// the duel is accepted
+	SyncWindow  time.Duration
+	AsyncWindow time.Duration
+	KFactor     float64
+}
+
+func NewDuelService(quizService *QuizService) *DuelService {
+	return &DuelService{
+		DB:          quizService.DB,
+		Quiz:        quizService,
+		InviteTTL:   24 * time.Hour,
+		SyncWindow:  15 * time.Minute,
+		AsyncWindow: 48 * time.Hour,
+		KFactor:     DefaultEloKFactor,
+	}
+}
+
+func (s *DuelService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// Challenge invites another player to a duel on a fresh random question set.
+func (s *DuelService) Challenge(challenger string, payload models.DuelChallengePayload) (*models.Duel, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	mode := payload.Mode
+	if mode == "" {
+		mode = models.DuelAsync
+	}
+	if mode != models.DuelSync && mode != models.DuelAsync {
+		return nil, ErrInvalidDuelMode
+	}
+	if payload.Opponent == challenger {
+		return nil, ErrDuelSelf
+	}
+	for _, username := range []string{challenger, payload.Opponent} {
+		if _, err := s.DB.GetUser(username); err != nil {
+			return nil, fmt.Errorf("user %s not found: %w", username, err)
+		}
+	}
+
+	count := payload.Questions
+	if count <= 0 {
+		count = DefaultDuelQuestions
+	}
+	count = min(count, len(questions))
+	if count == 0 {
+		return nil, ErrNoQuestionsToDuel
+	}
+	questionIDs := make([]int, 0, count)
+	for _, i := range rand.Perm(len(questions))[:count] {
+		questionIDs = append(questionIDs, questions[i].QuestionID)
+	}
+
+	now := s.now()
+	duelID := uuid.NewString()
+	duel := database.Duel{
+		DuelID:      duelID,
+		Challenger:  challenger,
+		Opponent:    payload.Opponent,
+		Mode:        mode,
+		Status:      models.DuelPending,
+		QuizID:      duelQuizPrefix + duelID,
+		QuestionIDs: questionIDs,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.InviteTTL),
+	}
+	if err := s.DB.AddDuel(duel); err != nil {
+		logger.Error("Failed to store duel", zap.String("challenger", challenger), zap.Error(err))
+		return nil, fmt.Errorf("failed to store duel: %w", err)
+	}
+
+	logger.Info("Duel challenge sent", zap.String("duelID", duelID), zap.String("challenger", challenger), zap.String("opponent", payload.Opponent))
+	result := models.Duel(duel)
+	return &result, nil
+}
+
+// Respond accepts or declines a pending duel. Accepting opens its quiz.
+func (s *DuelService) Respond(duelID, username string, accept bool) (*models.Duel, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	duel, err := s.load(duelID)
+	if err != nil {
+		return nil, err
+	}
+	if duel.Challenger != username && duel.Opponent != username {
+		return nil, database.ErrDuelNotFound
+	}
+	if duel.Opponent != username {
+		return nil, ErrNotDuelOpponent
+	}
+	if duel.Status != models.DuelPending {
+		return nil, ErrDuelNotPending
+	}
+
+	if !accept {
+		duel.Status = models.DuelDeclined
+	} else {
+		now := s.now()
+		window := s.AsyncWindow
+		if duel.Mode == models.DuelSync {
+			window = s.SyncWindow
+		}
+		duel.Status = models.DuelActive
+		duel.AcceptedAt = now
+		duel.DeadlineAt = now.Add(window)
+
+		quiz := DefaultQuiz()
+		quiz.QuizID = duel.QuizID
+		quiz.Title = fmt.Sprintf("Duel: %s vs %s", duel.Challenger, duel.Opponent)
+		quiz.MaxAttempts = 1
+		quiz.OpensAt = &duel.AcceptedAt
+		quiz.ClosesAt = &duel.DeadlineAt
+		quiz.ScoringPolicy = models.ScoreBest
+		quiz.QuestionIDs = duel.QuestionIDs
+		quiz.Players = []string{duel.Challenger, duel.Opponent}
+		quiz.Unlisted = true
+		if err := s.Quiz.RegisterQuiz(quiz); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.DB.UpdateDuel(duel); err != nil {
+		logger.Error("Failed to update duel", zap.String("duelID", duelID), zap.Error(err))
+		return nil, fmt.Errorf("failed to update duel: %w", err)
+	}
+
+	logger.Info("Duel answered", zap.String("duelID", duelID), zap.String("status", string(duel.Status)))
+	result := models.Duel(duel)
+	return &result, nil
+}
+
+// GetDuel returns a duel the user takes part in.
+func (s *DuelService) GetDuel(duelID, username string) (*models.Duel, error) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	duel, err := s.load(duelID)
+	if err != nil {
+		return nil, err
+	}
+	if duel.Challenger != username && duel.Opponent != username {
+		return nil, database.ErrDuelNotFound
+	}
+	result := models.Duel(duel)
+	return &result, nil
+}
+
+// ListDuels returns the duels of the user, the latest first.
+func (s *DuelService) ListDuels(username string) ([]models.Duel, error) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	if _, err := s.DB.GetUser(username); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	duels := []models.Duel{}
+	for _, stored := range s.DB.ListDuels(username) {
+		duel, err := s.load(stored.DuelID)
+		if err != nil {
+			return nil, err
+		}
+		duels = append(duels, models.Duel(duel))
+	}
+	return duels, nil
+}
+
+// AttemptCompleted settles the duel of a completed attempt.
+func (s *DuelService) AttemptCompleted(attempt models.Attempt) {
+	if !strings.HasPrefix(attempt.QuizID, duelQuizPrefix) {
+		return
+	}
+	if _, err := s.load(strings.TrimPrefix(attempt.QuizID, duelQuizPrefix)); err != nil {
+		utils.GetLogger().Sugar().Error("Failed to settle duel", zap.String("quizID", attempt.QuizID), zap.Error(err))
+	}
+}
+
+// load returns a duel after applying what happened since it was stored.
+// Callers must hold quizMu.
+func (s *DuelService) load(duelID string) (database.Duel, error) {
+	duel, err := s.DB.GetDuel(duelID)
+	if err != nil {
+		return database.Duel{}, err
+	}
+	if !s.settle(&duel, s.now()) {
+		return duel, nil
+	}
+	if err := s.DB.UpdateDuel(duel); err != nil {
+		return database.Duel{}, fmt.Errorf("failed to update duel: %w", err)
+	}
+	return duel, nil
+}
+
+// settle expires a pending duel past its invitation and decides an active one
+// once both players are done or the deadline passed. It reports whether the
+// duel changed.
+func (s *DuelService) settle(duel *database.Duel, now time.Time) bool {
+	switch duel.Status {
+	case models.DuelPending:
+		if now.Before(duel.ExpiresAt) {
+			return false
+		}
+		duel.Status = models.DuelExpired
+		return true
+	case models.DuelActive:
+	default:
+		return false
+	}
+
+	challenger, challengerDone := matchResult(s.DB, duel.QuizID, duel.Challenger)
+	opponent, opponentDone := matchResult(s.DB, duel.QuizID, duel.Opponent)
+	if !(challengerDone && opponentDone) && now.Before(duel.DeadlineAt) {
+		return false
+	}
+
+	duel.CompletedAt = now
+	if !challenger.Finished && !opponent.Finished {
+		duel.Status = models.DuelExpired
+		return true
+	}
+	duel.Status = models.DuelCompleted
+
+	outcome := duelOutcome(challenger, opponent)
+	switch outcome {
+	case 1:
+		duel.Winner = duel.Challenger
+	case 0:
+		duel.Winner = duel.Opponent
+	}
+	s.rate(&challenger, &opponent, outcome)
+	duel.Results = []models.DuelResult{challenger, opponent}
+	utils.GetLogger().Sugar().Info("Duel settled", zap.String("duelID", duel.DuelID), zap.String("winner", duel.Winner))
+	return true
+}
+
+// matchResult sums up the attempt of a player in a duel or a tournament match
+// and reports whether the player is done with it. Their quizzes allow a single
+// attempt.
+func matchResult(db *database.MemoryDB, quizID, username string) (models.DuelResult, bool) {
+	result := models.DuelResult{Username: username}
+	for _, attempt := range db.ListAttempts(username) {
+		if attempt.QuizID != quizID {
+			continue
+		}
+		if attempt.Status == models.AttemptActive || attempt.Status == models.AttemptPaused {
+			return result, false
+		}
+		if attempt.Status == models.AttemptCompleted {
+			result.Finished = true
+			result.Score = attempt.Score
+			for _, record := range attempt.Answers {
+				if !record.AnsweredAt.IsZero() {
+					result.Seconds += record.AnsweredAt.Sub(record.ServedAt).Seconds()
+				}
+			}
+		}
+		return result, true
+	}
+	return result, false
+}
+
+// duelOutcome is 1 when a wins, 0 when b wins and 0.5 for a draw. Finishing
+// beats not finishing, then the score counts and the time breaks ties.
+func duelOutcome(a, b models.DuelResult) float64 {
+	switch {
+	case a.Finished != b.Finished:
+		if a.Finished {
+			return 1
+		}
+		return 0
+	case a.Score != b.Score:
+		if a.Score > b.Score {
+			return 1
+		}
+		return 0
+	case a.Seconds != b.Seconds:
+		if a.Seconds < b.Seconds {
+			return 1
+		}
+		return 0
+	}
+	return 0.5
+}
+
+// rate applies the Elo update of a duel to both players.
+func (s *DuelService) rate(a, b *models.DuelResult, outcome float64) {
+	logger := utils.GetLogger().Sugar()
+	userA, errA := s.DB.GetUser(a.Username)
+	userB, errB := s.DB.GetUser(b.Username)
+	if errA != nil || errB != nil {
+		logger.Error("Duel players not found", zap.String("a", a.Username), zap.String("b", b.Username))
+		return
+	}
+
+	a.RatingBefore, b.RatingBefore = userA.Profile.DuelRating(), userB.Profile.DuelRating()
+	a.RatingAfter, b.RatingAfter = eloUpdate(a.RatingBefore, b.RatingBefore, outcome, s.KFactor)
+	userA.Profile.Rating, userB.Profile.Rating = a.RatingAfter, b.RatingAfter
+	for _, user := range []database.User{userA, userB} {
+		if err := s.DB.UpdateUser(user); err != nil {
+			logger.Error("Failed to update duel rating", zap.String("username", user.Username), zap.Error(err))
+		}
+	}
+}
+
+// eloUpdate returns the new ratings of a and b after a game in which a scored
+// outcome: 1 for a win, 0.5 for a draw and 0 for a loss.
+func eloUpdate(a, b int, outcome, k float64) (int, int) {
+	expected := 1 / (1 + math.Pow(10, float64(b-a)/400))
+	change := int(math.Round(k * (outcome - expected)))
+	return a + change, b - change
+}
+
+var (
+	_ IDuelService    = &DuelService{}
+	_ AttemptListener = &DuelService{}
+)
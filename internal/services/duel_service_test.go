@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// playDuel answers every question of the duel, right when correct is set.
+func playDuel(t *testing.T, quizService *QuizService, username, quizID string, correct bool) {
+	t.Helper()
+	assert.NoError(t, quizService.StartQuiz(username, quizID))
+	for {
+		question, err := quizService.GetNextQuestion(username)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrQuizComplete)
+			return
+		}
+		answer := questions[questionIndex(question.QuestionID)].Answer
+		if !correct {
+			answer = answer%3 + 1
+		}
+		_, err = quizService.SubmitAnswer(username, questionIndex(question.QuestionID), answer)
+		assert.NoError(t, err)
+	}
+}
+
+func TestDuelChallengeAndRatings(t *testing.T) {
+	quizService := newTestQuizService(t, testQuestions, "alice", "bob", "carol")
+	clock, _ := newTestClock()
+	quizService.Clock = clock
+	s := NewDuelService(quizService)
+	s.Clock = clock
+	quizService.AddAttemptListener(s)
+	db := quizService.DB
+
+	_, err := s.Challenge("alice", models.DuelChallengePayload{Opponent: "alice"})
+	assert.ErrorIs(t, err, ErrDuelSelf)
+	_, err = s.Challenge("alice", models.DuelChallengePayload{Opponent: "bob", Mode: "chess"})
+	assert.ErrorIs(t, err, ErrInvalidDuelMode)
+	_, err = s.Challenge("alice", models.DuelChallengePayload{Opponent: "nobody"})
+	assert.ErrorIs(t, err, database.ErrUserNotFound)
+
+	duel, err := s.Challenge("alice", models.DuelChallengePayload{Opponent: "bob", Questions: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, models.DuelPending, duel.Status)
+	assert.Len(t, duel.QuestionIDs, 2)
+
+	_, err = s.Respond(duel.DuelID, "alice", true)
+	assert.ErrorIs(t, err, ErrNotDuelOpponent)
+	_, err = s.GetDuel(duel.DuelID, "carol")
+	assert.ErrorIs(t, err, database.ErrDuelNotFound)
+	assert.Error(t, quizService.StartQuiz("alice", duel.QuizID), "expected the duel quiz to open once accepted")
+
+	duel, err = s.Respond(duel.DuelID, "bob", true)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DuelActive, duel.Status)
+	_, err = s.Respond(duel.DuelID, "bob", true)
+	assert.ErrorIs(t, err, ErrDuelNotPending)
+	assert.ErrorIs(t, quizService.StartQuiz("carol", duel.QuizID), ErrNotInvited)
+	for _, quiz := range quizService.ListQuizzes() {
+		assert.NotEqual(t, duel.QuizID, quiz.QuizID, "expected duels to stay off the quiz list")
+	}
+
+	playDuel(t, quizService, "alice", duel.QuizID, false)
+	settled, _ := s.GetDuel(duel.DuelID, "alice")
+	assert.Equal(t, models.DuelActive, settled.Status, "expected the duel to wait for bob")
+
+	playDuel(t, quizService, "bob", duel.QuizID, true)
+	settled, _ = s.GetDuel(duel.DuelID, "alice")
+	assert.Equal(t, models.DuelCompleted, settled.Status)
+	assert.Equal(t, "bob", settled.Winner)
+	assert.Equal(t, 1184, settled.Results[0].RatingAfter)
+	assert.Equal(t, 1216, settled.Results[1].RatingAfter)
+	bob, _ := db.GetUser("bob")
+	assert.Equal(t, 1216, bob.Profile.Rating)
+}
+
+func TestDuelExpiry(t *testing.T) {
+	quizService := newTestQuizService(t, testQuestions, "alice", "bob", "carol")
+	clock, now := newTestClock()
+	quizService.Clock = clock
+	s := NewDuelService(quizService)
+	s.Clock = clock
+	quizService.AddAttemptListener(s)
+
+	unanswered, _ := s.Challenge("alice", models.DuelChallengePayload{Opponent: "bob"})
+	declined, _ := s.Challenge("alice", models.DuelChallengePayload{Opponent: "bob"})
+	_, err := s.Respond(declined.DuelID, "bob", false)
+	assert.NoError(t, err)
+	forfeited, _ := s.Challenge("bob", models.DuelChallengePayload{Opponent: "alice", Mode: models.DuelSync})
+	forfeited, _ = s.Respond(forfeited.DuelID, "alice", true)
+	playDuel(t, quizService, "alice", forfeited.QuizID, false)
+
+	*now = now.Add(25 * time.Hour)
+	duels, err := s.ListDuels("alice")
+	assert.NoError(t, err)
+	byID := make(map[string]models.Duel)
+	for _, duel := range duels {
+		byID[duel.DuelID] = duel
+	}
+	assert.Equal(t, models.DuelExpired, byID[unanswered.DuelID].Status)
+	assert.Equal(t, models.DuelDeclined, byID[declined.DuelID].Status)
+	assert.Equal(t, models.DuelCompleted, byID[forfeited.DuelID].Status)
+	assert.Equal(t, "alice", byID[forfeited.DuelID].Winner, "expected the player who finished to win")
+}
+
+func TestDuelOutcome(t *testing.T) {
+	fast := models.DuelResult{Finished: true, Score: 3, Seconds: 20}
+	slow := models.DuelResult{Finished: true, Score: 3, Seconds: 40}
+	assert.Equal(t, 1.0, duelOutcome(fast, slow), "expected time to break the tie")
+	assert.Equal(t, 0.0, duelOutcome(models.DuelResult{Finished: true, Score: 2}, slow))
+	assert.Equal(t, 0.5, duelOutcome(fast, fast))
+
+	a, b := eloUpdate(1400, 1200, 0, DefaultEloKFactor)
+	assert.Equal(t, []int{1376, 1224}, []int{a, b}, "expected an upset to move the ratings more")
+}
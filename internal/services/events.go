@@ -0,0 +1,42 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+// AttemptListener is told about every attempt that is completed. Listeners are
+// called while the quiz lock is held, so they must not call back into the
+// QuizService.
+type AttemptListener interface {
+	AttemptCompleted(attempt models.Attempt)
+}
+
+// AnswerListener is told about every answer given within an attempt, with the
+// attempt as it is after the answer. The same locking rules apply.
+type AnswerListener interface {
+	AnswerSubmitted(attempt models.Attempt, record models.AnswerRecord)
+}
+
+// AddAttemptListener subscribes a listener to completed attempts.
+func (s *QuizService) AddAttemptListener(listener AttemptListener) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// AddAnswerListener subscribes a listener to submitted answers.
+func (s *QuizService) AddAnswerListener(listener AnswerListener) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+	s.answerListeners = append(s.answerListeners, listener)
+}
+
+func (s *QuizService) notifyAttemptCompleted(attempt models.Attempt) {
+	for _, listener := range s.listeners {
+		listener.AttemptCompleted(attempt)
+	}
+}
+
+func (s *QuizService) notifyAnswerSubmitted(attempt models.Attempt, record models.AnswerRecord) {
+	for _, listener := range s.answerListeners {
+		listener.AnswerSubmitted(attempt, record)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// testQuestions is the question bank most quiz tests play through.
+var testQuestions = []models.Question{
+	{QuestionID: 1, Question: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 2, Hint: "Two pairs", Explanation: "Two plus two is four"},
+	{QuestionID: 2, Question: "What is 3+3?", Options: []string{"6", "7", "8"}, Answer: 1},
+	{QuestionID: 3, Question: "What is 4+4?", Options: []string{"7", "8", "9"}, Answer: 2},
+}
+
+// newTestQuizService returns a quiz service on a new database with the
+// questions loaded and the users added. The quiz timers of the users are
+// stopped when the test ends.
+func newTestQuizService(t *testing.T, questions []models.Question, usernames ...string) *QuizService {
+	t.Helper()
+	db := database.NewMemoryDB()
+	s := NewQuizService(db)
+	s.LoadQuestions(questions)
+	for _, username := range usernames {
+		assert.NoError(t, db.AddUser(database.User{Username: username}))
+	}
+	t.Cleanup(func() {
+		quizMu.Lock()
+		defer quizMu.Unlock()
+		for _, username := range usernames {
+			stopTimer(username)
+		}
+	})
+	return s
+}
+
+// newTestAuthService returns an auth service on a new database with the users
+// registered with the password "Password123!". Their sessions are revoked
+// when the test ends.
+func newTestAuthService(t *testing.T, usernames ...string) *AuthService {
+	t.Helper()
+	s := NewAuthService(database.NewMemoryDB())
+	for _, username := range usernames {
+		assert.NoError(t, s.RegisterUser(username, "Password123!"))
+	}
+	t.Cleanup(func() {
+		for _, username := range usernames {
+			utils.RevokeUserSessions(username)
+		}
+	})
+	return s
+}
+
+// newTestClock returns a clock that stands still until the test moves the
+// returned time.
+func newTestClock() (func() time.Time, *time.Time) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, &now
+}
+
+func newTestLimiter(config LoginLimitConfig) (*LoginLimiter, *time.Time) {
+	limiter := NewLoginLimiter(config)
+	clock, now := newTestClock()
+	limiter.Clock = clock
+	return limiter, now
+}
+
+func assertLimited(t *testing.T, err, rule error, retryAt time.Time) {
+	t.Helper()
+	var limitErr *LoginLimitError
+	if assert.True(t, errors.As(err, &limitErr), "expected a login limit error, got %v", err) {
+		assert.ErrorIs(t, err, rule)
+		assert.Equal(t, retryAt, limitErr.RetryAt)
+	}
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultLeaderboardLimit = 10
+	MaxLeaderboardLimit     = 100
+)
+
+var (
+	ErrInvalidPeriod = errors.New("unknown leaderboard period")
+	ErrInvalidCursor = errors.New("invalid leaderboard cursor")
+	ErrInvalidLimit  = errors.New("leaderboard limit must be between 1 and 100")
+)
+
+// calendarPeriods are kept up to date with every completed attempt.
+var calendarPeriods = []models.LeaderboardPeriod{models.PeriodAllTime, models.PeriodToday, models.PeriodThisWeek, models.PeriodThisMonth}
+
+// boardKey identifies one window of a leaderboard, for example this week of
+// the exam. An empty quiz ID ranks all quizzes together.
+type boardKey struct {
+	QuizID string
+	Period models.LeaderboardPeriod
+	Window string
+}
+
+// board holds the scores of the players in one window. The ranking is kept
+// until the next score arrives.
+type board struct {
+	scores map[string]map[string][]int
+	ranked []models.LeaderboardEntry
+}
+
+func newBoard() *board {
+	return &board{scores: make(map[string]map[string][]int)}
+}
+
+func (b *board) add(username, quizID string, score int) {
+	if b.scores[username] == nil {
+		b.scores[username] = make(map[string][]int)
+	}
+	b.scores[username][quizID] = append(b.scores[username][quizID], score)
+	b.ranked = nil
+}
+
+// LeaderboardService ranks players by their completed ranked attempts. The
+// score of a player on a quiz follows the scoring policy of that quiz, and
+// the scores of all quizzes add up when no quiz is selected.
+//
+// Calendar and all-time leaderboards are updated as attempts complete, rolling
+// ones are built from the attempts completed within the window.
+type LeaderboardService struct {
+	DB       *database.MemoryDB
+	Clock    func() time.Time
+	Location *time.Location
+
+	mu        sync.RWMutex
+	boards    map[boardKey]*board
+	completed []database.Attempt
+}
+
+// NewLeaderboardService builds the leaderboards from the attempts already in
+// the database. Calendar windows reset in the given location, UTC when nil.
+func NewLeaderboardService(db *database.MemoryDB, location *time.Location) *LeaderboardService {
+	if location == nil {
+		location = time.UTC
+	}
+	s := &LeaderboardService{DB: db, Location: location, boards: make(map[boardKey]*board)}
+	for _, attempt := range db.ListAllAttempts() {
+		s.record(models.Attempt(attempt))
+	}
+	return s
+}
+
+func (s *LeaderboardService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// AttemptCompleted adds the score of a completed attempt to the leaderboards.
+func (s *LeaderboardService) AttemptCompleted(attempt models.Attempt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(attempt)
+}
+
+func (s *LeaderboardService) record(attempt models.Attempt) {
+	if attempt.Status != models.AttemptCompleted || !attempt.Ranked {
+		return
+	}
+	for _, quizID := range []string{attempt.QuizID, ""} {
+		for _, period := range calendarPeriods {
+			key := boardKey{QuizID: quizID, Period: period, Window: s.window(period, attempt.CompletedAt)}
+			if s.boards[key] == nil {
+				s.boards[key] = newBoard()
+			}
+			s.boards[key].add(attempt.Username, attempt.QuizID, attempt.Score)
+		}
+	}
+
+	// Keep the completed attempts in completion order for the rolling windows
+	i := sort.Search(len(s.completed), func(i int) bool {
+		return s.completed[i].CompletedAt.After(attempt.CompletedAt)
+	})
+	s.completed = append(s.completed, database.Attempt{})
+	copy(s.completed[i+1:], s.completed[i:])
+	s.completed[i] = database.Attempt(attempt)
+}
+
+// window names the calendar window of a period that contains t.
+func (s *LeaderboardService) window(period models.LeaderboardPeriod, t time.Time) string {
+	t = t.In(s.Location)
+	switch period {
+	case models.PeriodToday:
+		return t.Format("2006-01-02")
+	case models.PeriodThisWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case models.PeriodThisMonth:
+		return t.Format("2006-01")
+	}
+	return ""
+}
+
+// resetsAt returns when the calendar window of a period that contains t ends.
+func (s *LeaderboardService) resetsAt(period models.LeaderboardPeriod, t time.Time) *time.Time {
+	t = t.In(s.Location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.Location)
+	var end time.Time
+	switch period {
+	case models.PeriodToday:
+		end = midnight.AddDate(0, 0, 1)
+	case models.PeriodThisWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		end = midnight.AddDate(0, 0, 7-daysSinceMonday)
+	case models.PeriodThisMonth:
+		end = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.Location)
+	default:
+		return nil
+	}
+	return &end
+}
+
+// GetLeaderboard returns one page of the leaderboard selected by the query,
+// together with the standing of the calling user when they are ranked.
+func (s *LeaderboardService) GetLeaderboard(username string, query models.LeaderboardQuery) (*models.Leaderboard, error) {
+	logger := utils.GetLogger().Sugar()
+
+	offset, err := s.checkQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	entries := s.entries(query.QuizID, query.Period, now)
+
+	leaderboard := &models.Leaderboard{
+		QuizID:   query.QuizID,
+		Period:   query.Period,
+		Total:    len(entries),
+		Entries:  []models.LeaderboardEntry{},
+		ResetsAt: s.resetsAt(query.Period, now),
+	}
+	if offset < len(entries) {
+		end := min(offset+query.Limit, len(entries))
+		leaderboard.Entries = entries[offset:end]
+		if end < len(entries) {
+			leaderboard.NextCursor = strconv.Itoa(end)
+		}
+	}
+	for i, entry := range entries {
+		if entry.Username == username {
+			leaderboard.Me = &models.PlayerStanding{
+				Rank:       entry.Rank,
+				Score:      entry.Score,
+				Percentile: percentileOf(entries, i),
+			}
+			break
+		}
+	}
+
+	logger.Info("Leaderboard served", zap.String("quizID", query.QuizID), zap.String("period", string(query.Period)), zap.Int("players", len(entries)))
+	return leaderboard, nil
+}
+
+// checkQuery fills in the defaults of a leaderboard query and returns the
+// offset its cursor points to.
+func (s *LeaderboardService) checkQuery(query *models.LeaderboardQuery) (int, error) {
+	if query.Period == "" {
+		query.Period = models.PeriodAllTime
+	}
+	switch query.Period {
+	case models.PeriodAllTime, models.PeriodToday, models.PeriodThisWeek, models.PeriodThisMonth,
+		models.PeriodLast24Hours, models.PeriodLast7Days, models.PeriodLast30Days:
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrInvalidPeriod, query.Period)
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultLeaderboardLimit
+	}
+	if query.Limit < 1 || query.Limit > MaxLeaderboardLimit {
+		return 0, ErrInvalidLimit
+	}
+	offset := 0
+	if query.Cursor != "" {
+		parsed, err := strconv.Atoi(query.Cursor)
+		if err != nil || parsed < 0 {
+			return 0, ErrInvalidCursor
+		}
+		offset = parsed
+	}
+	if query.QuizID != "" {
+		if _, err := s.scoringPolicy(query.QuizID); err != nil {
+			utils.GetLogger().Sugar().Warn("Leaderboard requested for unknown quiz", zap.String("quizID", query.QuizID))
+			return 0, err
+		}
+	}
+	return offset, nil
+}
+
+// entries returns the ranked entries of a leaderboard at the given moment.
+// The returned slice must not be modified.
+func (s *LeaderboardService) entries(quizID string, period models.LeaderboardPeriod, now time.Time) []models.LeaderboardEntry {
+	if window := period.Window(); window > 0 {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		since := now.Add(-window)
+		start := sort.Search(len(s.completed), func(i int) bool {
+			return !s.completed[i].CompletedAt.Before(since)
+		})
+		rolling := newBoard()
+		for _, attempt := range s.completed[start:] {
+			if quizID == "" || attempt.QuizID == quizID {
+				rolling.add(attempt.Username, attempt.QuizID, attempt.Score)
+			}
+		}
+		return s.rank(rolling)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, exists := s.boards[boardKey{QuizID: quizID, Period: period, Window: s.window(period, now)}]
+	if !exists {
+		return nil
+	}
+	if b.ranked == nil {
+		b.ranked = s.rank(b)
+	}
+	return b.ranked
+}
+
+func (s *LeaderboardService) rank(b *board) []models.LeaderboardEntry {
+	entries := make([]models.LeaderboardEntry, 0, len(b.scores))
+	for player, quizzes := range b.scores {
+		entry := models.LeaderboardEntry{Username: player}
+		for quizID, quizScores := range quizzes {
+			policy, _ := s.scoringPolicy(quizID)
+			entry.Score += countedScore(policy, quizScores)
+			entry.Attempts += len(quizScores)
+		}
+		entries = append(entries, entry)
+	}
+	rankEntries(entries)
+	return entries
+}
+
+// scoringPolicy looks up the scoring policy of a quiz. The general quiz is
+// known even when it was never registered.
+func (s *LeaderboardService) scoringPolicy(quizID string) (models.ScoringPolicy, error) {
+	quiz, err := s.DB.GetQuiz(quizID)
+	if err != nil {
+		if quizID == models.DefaultQuizID {
+			return DefaultQuiz().ScoringPolicy, nil
+		}
+		return "", err
+	}
+	return quiz.ScoringPolicy, nil
+}
+
+// rankEntries orders the entries by score and gives them competition ranks:
+// tied players share a rank and the next rank is skipped ("1224").
+func rankEntries(entries []models.LeaderboardEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].Username < entries[j].Username
+	})
+	for i := range entries {
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+}
+
+// percentileOf returns the share of the other players on a ranked leaderboard
+// that scored lower than the entry at index i. A player without rivals is on top.
+func percentileOf(entries []models.LeaderboardEntry, i int) float64 {
+	if len(entries) < 2 {
+		return 100
+	}
+	lower := len(entries) - sort.Search(len(entries), func(j int) bool {
+		return entries[j].Score < entries[i].Score
+	})
+	return float64(lower) / float64(len(entries)-1) * 100
+}
+
+var (
+	_ ILeaderboardService = &LeaderboardService{}
+	_ AttemptListener     = &LeaderboardService{}
+)
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func addCompletedAttempt(db *database.MemoryDB, id, username, quizID string, score int, completedAt time.Time) {
+	db.AddAttempt(database.Attempt{
+		AttemptID:   id,
+		Username:    username,
+		QuizID:      quizID,
+		Ranked:      true,
+		Status:      models.AttemptCompleted,
+		Score:       score,
+		StartedAt:   completedAt.Add(-time.Minute),
+		CompletedAt: completedAt,
+	})
+}
+
+// addLeaderboardAttempts adds the exam attempts the leaderboard tests rank,
+// finished up to now.
+func addLeaderboardAttempts(db *database.MemoryDB, now time.Time) {
+	db.AddQuiz(database.Quiz{QuizID: "exam", ScoringPolicy: models.ScoreBest})
+
+	addCompletedAttempt(db, "a1", "alice", "exam", 5, now.Add(-time.Hour))
+	addCompletedAttempt(db, "a2", "alice", "exam", 3, now.Add(-30*time.Minute))
+	addCompletedAttempt(db, "b1", "bob", "exam", 5, now.Add(-10*24*time.Hour))
+	addCompletedAttempt(db, "c1", "carol", "exam", 4, now.Add(-time.Hour))
+	addCompletedAttempt(db, "d1", "dave", "exam", 2, now.Add(-time.Hour))
+	addCompletedAttempt(db, "d2", "dave", models.DefaultQuizID, 1, now.Add(-time.Hour))
+	// Attempts that are not completed or not ranked never count
+	db.AddAttempt(database.Attempt{AttemptID: "e1", Username: "erin", QuizID: "exam", Ranked: true, Status: models.AttemptActive, Score: 9})
+	db.AddAttempt(database.Attempt{AttemptID: "f1", Username: "frank", QuizID: "exam", Status: models.AttemptCompleted, Score: 9, CompletedAt: now})
+}
+
+func TestLeaderboardRanksTiesAndCaller(t *testing.T) {
+	clock, now := newTestClock()
+	db := database.NewMemoryDB()
+	addLeaderboardAttempts(db, *now)
+	s := NewLeaderboardService(db, nil)
+	s.Clock = clock
+
+	leaderboard, err := s.GetLeaderboard("carol", models.LeaderboardQuery{QuizID: "exam"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, leaderboard.Total, "expected only players with completed ranked attempts")
+	assert.Equal(t, []models.LeaderboardEntry{
+		{Rank: 1, Username: "alice", Score: 5, Attempts: 2},
+		{Rank: 1, Username: "bob", Score: 5, Attempts: 1},
+		{Rank: 3, Username: "carol", Score: 4, Attempts: 1},
+		{Rank: 4, Username: "dave", Score: 2, Attempts: 1},
+	}, leaderboard.Entries)
+	if assert.NotNil(t, leaderboard.Me) {
+		assert.Equal(t, 3, leaderboard.Me.Rank)
+		assert.InDelta(t, 33.33, leaderboard.Me.Percentile, 0.01, "expected one of the three other players to be lower")
+	}
+
+	leaderboard, err = s.GetLeaderboard("erin", models.LeaderboardQuery{})
+	assert.NoError(t, err)
+	assert.Nil(t, leaderboard.Me, "expected no standing for a player without completed attempts")
+	assert.Equal(t, 3.0, leaderboard.Entries[3].Score, "expected the scores of all quizzes to add up")
+}
+
+func TestLeaderboardPeriodAndPaging(t *testing.T) {
+	clock, now := newTestClock()
+	db := database.NewMemoryDB()
+	addLeaderboardAttempts(db, *now)
+	s := NewLeaderboardService(db, nil)
+	s.Clock = clock
+
+	leaderboard, err := s.GetLeaderboard("alice", models.LeaderboardQuery{QuizID: "exam", Period: models.PeriodLast7Days, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, leaderboard.Total, "expected attempts before the window to be left out")
+	assert.Len(t, leaderboard.Entries, 2)
+	assert.Equal(t, "2", leaderboard.NextCursor)
+
+	leaderboard, err = s.GetLeaderboard("alice", models.LeaderboardQuery{QuizID: "exam", Period: models.PeriodLast7Days, Limit: 2, Cursor: leaderboard.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.LeaderboardEntry{{Rank: 3, Username: "dave", Score: 2, Attempts: 1}}, leaderboard.Entries)
+	assert.Empty(t, leaderboard.NextCursor)
+
+	_, err = s.GetLeaderboard("alice", models.LeaderboardQuery{Period: "forever"})
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = s.GetLeaderboard("alice", models.LeaderboardQuery{Cursor: "abc"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = s.GetLeaderboard("alice", models.LeaderboardQuery{Limit: 1000})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+	_, err = s.GetLeaderboard("alice", models.LeaderboardQuery{QuizID: "unknown"})
+	assert.ErrorIs(t, err, database.ErrQuizNotFound)
+}
+
+func TestGetStatsPercentile(t *testing.T) {
+	db := database.NewMemoryDB()
+	s := NewQuizService(db)
+	db.AddUser(database.User{Username: "caller", Score: 5, QuizTaken: 1})
+	db.AddUser(database.User{Username: "lower", Score: 3, QuizTaken: 1})
+	db.AddUser(database.User{Username: "tied", Score: 5, QuizTaken: 1})
+	db.AddUser(database.User{Username: "higher", Score: 8, QuizTaken: 2})
+	db.AddUser(database.User{Username: "newcomer"})
+
+	users, message, err := s.GetStats("caller")
+	assert.NoError(t, err)
+	assert.Equal(t, "Your score is 5 and that is 33.33% better than other users' scores.", message)
+	assert.Len(t, users, 4, "expected users who never played to be left out")
+	assert.Equal(t, "higher", users[0].Username)
+
+	_, _, err = s.GetStats("newcomer")
+	assert.ErrorIs(t, err, ErrNoStatsForUser)
+}
+
+func TestLeaderboardCalendarWindows(t *testing.T) {
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+	db := database.NewMemoryDB()
+	// Friday 2025-01-10 12:00 UTC is 13:00 in Budapest
+	s := NewLeaderboardService(db, budapest)
+	s.Clock, _ = newTestClock()
+
+	// 23:30 UTC on Thursday is already Friday in Budapest
+	s.AttemptCompleted(models.Attempt{Username: "alice", QuizID: "exam", Ranked: true, Status: models.AttemptCompleted, Score: 4, CompletedAt: time.Date(2025, 1, 9, 23, 30, 0, 0, time.UTC)})
+	s.AttemptCompleted(models.Attempt{Username: "bob", QuizID: "exam", Ranked: true, Status: models.AttemptCompleted, Score: 6, CompletedAt: time.Date(2025, 1, 9, 22, 30, 0, 0, time.UTC)})
+	s.AttemptCompleted(models.Attempt{Username: "carol", QuizID: "exam", Ranked: true, Status: models.AttemptCompleted, Score: 9, CompletedAt: time.Date(2024, 12, 30, 10, 0, 0, 0, time.UTC)})
+	db.AddQuiz(database.Quiz{QuizID: "exam", ScoringPolicy: models.ScoreBest})
+
+	tests := []struct {
+		period   models.LeaderboardPeriod
+		players  []string
+		resetsAt time.Time
+	}{
+		{models.PeriodToday, []string{"alice"}, time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC)},
+		{models.PeriodThisWeek, []string{"bob", "alice"}, time.Date(2025, 1, 12, 23, 0, 0, 0, time.UTC)},
+		{models.PeriodThisMonth, []string{"bob", "alice"}, time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)},
+		{models.PeriodAllTime, []string{"carol", "bob", "alice"}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			leaderboard, err := s.GetLeaderboard("alice", models.LeaderboardQuery{QuizID: "exam", Period: tt.period})
+			assert.NoError(t, err)
+
+			var players []string
+			for _, entry := range leaderboard.Entries {
+				players = append(players, entry.Username)
+			}
+			assert.Equal(t, tt.players, players)
+			if tt.resetsAt.IsZero() {
+				assert.Nil(t, leaderboard.ResetsAt)
+			} else if assert.NotNil(t, leaderboard.ResetsAt) {
+				assert.True(t, tt.resetsAt.Equal(*leaderboard.ResetsAt), "expected reset at %s, got %s", tt.resetsAt, leaderboard.ResetsAt)
+			}
+		})
+	}
+}
+
+func TestLeaderboardUpdatesOnCompletedAttempt(t *testing.T) {
+	db := database.NewMemoryDB()
+	quizService := NewQuizService(db)
+	quizService.LoadQuestions([]models.Question{{QuestionID: 1, Question: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 2}})
+	db.AddUser(database.User{Username: "testuser"})
+	t.Cleanup(func() {
+		quizMu.Lock()
+		defer quizMu.Unlock()
+		stopTimer("testuser")
+	})
+
+	s := NewLeaderboardService(db, nil)
+	quizService.AddAttemptListener(s)
+
+	assert.NoError(t, quizService.StartQuiz("testuser", ""))
+	_, err := quizService.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+	_, err = quizService.SubmitAnswer("testuser", 0, 2)
+	assert.NoError(t, err)
+
+	leaderboard, err := s.GetLeaderboard("testuser", models.LeaderboardQuery{Period: models.PeriodToday})
+	assert.NoError(t, err)
+	assert.Empty(t, leaderboard.Entries, "expected an attempt in progress not to count")
+
+	_, err = quizService.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+
+	leaderboard, err = s.GetLeaderboard("testuser", models.LeaderboardQuery{Period: models.PeriodToday})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.LeaderboardEntry{{Rank: 1, Username: "testuser", Score: CorrectAnswerPoints, Attempts: 1}}, leaderboard.Entries)
+}
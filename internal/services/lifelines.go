@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+// LifelineConfig controls how many lifelines a player gets per attempt and
+// how many points using one costs. By default a lifeline costs half of the
+// CorrectAnswerPoints, so a correct answer after one still scores.
+type LifelineConfig struct {
+	HintsPerAttempt      int
+	FiftyFiftyPerAttempt int
+	HintPenalty          int
+	FiftyFiftyPenalty    int
+}
+
+func DefaultLifelineConfig() LifelineConfig {
+	return LifelineConfig{
+		HintsPerAttempt:      1,
+		FiftyFiftyPerAttempt: 1,
+		HintPenalty:          CorrectAnswerPoints / 2,
+		FiftyFiftyPenalty:    CorrectAnswerPoints / 2,
+	}
+}
+
+var (
+	ErrQuestionNotServed    = errors.New("question has not been served yet")
+	ErrUnknownLifeline      = errors.New("unknown lifeline")
+	ErrNoLifelinesLeft      = errors.New("no lifelines of this type left")
+	ErrLifelineAlreadyUsed  = errors.New("lifeline already used on this question")
+	ErrLifelineNotAvailable = errors.New("lifeline not available for this question")
+)
+
+func (c LifelineConfig) allowance(lifeline models.LifelineType) (limit, penalty int, err error) {
+	switch lifeline {
+	case models.LifelineHint:
+		return c.HintsPerAttempt, c.HintPenalty, nil
+	case models.LifelineFiftyFifty:
+		return c.FiftyFiftyPerAttempt, c.FiftyFiftyPenalty, nil
+	}
+	return 0, 0, ErrUnknownLifeline
+}
+
+func (s *QuizService) UseLifeline(username string, questionIndex int, lifeline models.LifelineType) (*models.LifelineResult, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	limit, penalty, err := s.Lifelines.allowance(lifeline)
+	if err != nil {
+		logger.Warn("Unknown lifeline requested", zap.String("username", username), zap.String("lifeline", string(lifeline)))
+		return nil, err
+	}
+
+	if questionIndex < 0 || questionIndex >= len(questions) {
+		logger.Error("Invalid question index", zap.Int("questionIndex", questionIndex))
+		return nil, errors.New("question index is out of range")
+	}
+	question := questions[questionIndex]
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	attempt, err := s.activeAttempt(user)
+	if err != nil {
+		logger.Warn("No quiz in progress", zap.String("username", username))
+		return nil, err
+	}
+	if err := attemptPlayable(attempt); err != nil {
+		return nil, err
+	}
+	if findRecord(&attempt, questionIndex) == nil {
+		return nil, ErrQuestionNotServed
+	}
+
+	used := 0
+	for _, use := range attempt.Lifelines {
+		if use.Type != lifeline {
+			continue
+		}
+		if use.QuestionIndex == questionIndex {
+			return nil, ErrLifelineAlreadyUsed
+		}
+		used++
+	}
+	if used >= limit {
+		logger.Info("Lifeline limit reached", zap.String("username", username), zap.String("lifeline", string(lifeline)))
+		return nil, ErrNoLifelinesLeft
+	}
+
+	result := &models.LifelineResult{
+		Type:          lifeline,
+		QuestionIndex: questionIndex,
+		Penalty:       penalty,
+		Remaining:     limit - used - 1,
+	}
+	switch lifeline {
+	case models.LifelineHint:
+		if question.Hint == "" {
+			return nil, ErrLifelineNotAvailable
+		}
+		result.Hint = question.Hint
+	case models.LifelineFiftyFifty:
+		result.RemovedOptions = removeWrongOptions(question)
+		if len(result.RemovedOptions) == 0 {
+			return nil, ErrLifelineNotAvailable
+		}
+	}
+
+	attempt.Lifelines = append(attempt.Lifelines, models.LifelineUse{
+		Type:          lifeline,
+		QuestionIndex: questionIndex,
+		Penalty:       penalty,
+		UsedAt:        s.now(),
+	})
+	if err := s.DB.UpdateAttempt(attempt); err != nil {
+		logger.Error("Failed to record lifeline on attempt", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("failed to record lifeline: %w", err)
+	}
+
+	logger.Info("Lifeline used",
+		zap.String("username", username),
+		zap.String("lifeline", string(lifeline)),
+		zap.Int("questionIndex", questionIndex),
+		zap.Int("penalty", penalty))
+	return result, nil
+}
+
+// removeWrongOptions picks wrong options to hide so that only the correct
+// answer and a single wrong option remain. Options are numbered from 1, the
+// same way as Question.Answer.
+func removeWrongOptions(question models.Question) []int {
+	var wrong []int
+	for i := range question.Options {
+		if i+1 != question.Answer {
+			wrong = append(wrong, i+1)
+		}
+	}
+	if len(wrong) < 2 {
+		return nil
+	}
+	rand.Shuffle(len(wrong), func(i, j int) { wrong[i], wrong[j] = wrong[j], wrong[i] })
+	removed := wrong[:len(wrong)-1]
+	slices.Sort(removed)
+	return removed
+}
+
+// lifelinesFor returns the lifelines used on a question during an attempt.
+func lifelinesFor(attempt database.Attempt, questionIndex int) []models.LifelineUse {
+	var uses []models.LifelineUse
+	for _, use := range attempt.Lifelines {
+		if use.QuestionIndex == questionIndex {
+			uses = append(uses, use)
+		}
+	}
+	return uses
+}
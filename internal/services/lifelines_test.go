@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizServiceUseLifelineHint(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	db := s.DB
+
+	_, err := s.UseLifeline("testuser", 0, models.LifelineHint)
+	assert.ErrorIs(t, err, ErrQuestionNotServed, "expected hint to require a served question")
+
+	question, err := s.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+	assert.Empty(t, question.Hint, "expected hint to be hidden from the served question")
+
+	result, err := s.UseLifeline("testuser", 0, models.LifelineHint)
+	assert.NoError(t, err, "expected no error when using a hint")
+	assert.Equal(t, "Two pairs", result.Hint)
+	assert.Equal(t, 0, result.Remaining)
+
+	_, err = s.UseLifeline("testuser", 0, models.LifelineHint)
+	assert.ErrorIs(t, err, ErrLifelineAlreadyUsed)
+
+	user, _ := db.GetUser("testuser")
+	attempt, err := db.GetAttempt(user.ActiveAttemptID)
+	assert.NoError(t, err)
+	assert.Len(t, attempt.Lifelines, 1, "expected the lifeline to be recorded on the attempt")
+	assert.Equal(t, models.LifelineHint, attempt.Lifelines[0].Type)
+}
+
+func TestQuizServiceUseLifelineLimits(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	_, _ = s.GetNextQuestion("testuser")
+	_, _ = s.GetNextQuestion("testuser")
+
+	_, err := s.UseLifeline("testuser", 1, models.LifelineHint)
+	assert.ErrorIs(t, err, ErrLifelineNotAvailable, "expected error for a question without a hint")
+
+	_, err = s.UseLifeline("testuser", 0, models.LifelineFiftyFifty)
+	assert.NoError(t, err)
+
+	_, err = s.UseLifeline("testuser", 1, models.LifelineFiftyFifty)
+	assert.ErrorIs(t, err, ErrNoLifelinesLeft)
+
+	_, err = s.UseLifeline("testuser", 1, "skip")
+	assert.ErrorIs(t, err, ErrUnknownLifeline)
+}
+
+func TestQuizServiceUseLifelineFiftyFifty(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	_, _ = s.GetNextQuestion("testuser")
+
+	result, err := s.UseLifeline("testuser", 0, models.LifelineFiftyFifty)
+	assert.NoError(t, err)
+	assert.Len(t, result.RemovedOptions, 1, "expected one of three options to be removed")
+	assert.NotContains(t, result.RemovedOptions, 2, "expected the correct answer to be kept")
+}
+
+func TestQuizServiceLifelinePenalty(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	db := s.DB
+	_, _ = s.GetNextQuestion("testuser")
+	_, _ = s.GetNextQuestion("testuser")
+
+	_, err := s.UseLifeline("testuser", 0, models.LifelineHint)
+	assert.NoError(t, err)
+
+	result, err := s.SubmitAnswer("testuser", 0, 2)
+	assert.NoError(t, err)
+	assert.True(t, result.Correct)
+	assert.Equal(t, CorrectAnswerPoints/2, result.Points, "expected the default penalty to cost half of the answer")
+
+	result, err = s.SubmitAnswer("testuser", 1, 1)
+	assert.NoError(t, err)
+	assert.True(t, result.Correct)
+
+	user, _ := db.GetUser("testuser")
+	assert.Equal(t, CorrectAnswerPoints+CorrectAnswerPoints/2, user.Score, "expected the hinted answer to be worth half")
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	assert.Equal(t, CorrectAnswerPoints+CorrectAnswerPoints/2, attempt.Score)
+}
+
+func TestScoreAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      scoreContext
+		expected int
+	}{
+		{"Correct without lifelines", scoreContext{Correct: true}, 10},
+		{"Wrong without lifelines", scoreContext{Correct: false}, 0},
+		{"Correct with free lifeline", scoreContext{Correct: true, Lifelines: []models.LifelineUse{{Penalty: 0}}}, 10},
+		{"Correct with penalty", scoreContext{Correct: true, Lifelines: []models.LifelineUse{{Penalty: 5}}}, 5},
+		{"Correct with both lifelines", scoreContext{Correct: true, Lifelines: []models.LifelineUse{{Penalty: 5}, {Penalty: 5}}}, 0},
+		{"Wrong with penalty is clamped", scoreContext{Correct: false, Lifelines: []models.LifelineUse{{Penalty: 5}}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, scoreAnswer(tt.ctx))
+		})
+	}
+}
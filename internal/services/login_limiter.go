@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLoginRateLimited = errors.New("too many login attempts")
+	ErrLoginDelayed     = errors.New("login is slowed down after failed attempts")
+	ErrAccountLocked    = errors.New("account is locked after too many failed logins")
+)
+
+// LoginLimitConfig controls how often logins may be tried. A zero limit
+// turns its rule off.
+type LoginLimitConfig struct {
+	// IPAttempts logins may be tried from one address per IPWindow
+	IPAttempts int
+	IPWindow   time.Duration
+	// UserAttempts logins may be tried for one username per UserWindow
+	UserAttempts int
+	UserWindow   time.Duration
+	// After DelayAfter failures in a row every further attempt has to wait,
+	// BaseDelay at first and twice as long after each failure, up to MaxDelay
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	// LockoutThreshold failures in a row lock the username for LockoutDuration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+func DefaultLoginLimitConfig() LoginLimitConfig {
+	return LoginLimitConfig{
+		IPAttempts:       20,
+		IPWindow:         time.Minute,
+		UserAttempts:     10,
+		UserWindow:       time.Minute,
+		DelayAfter:       3,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+	}
+}
+
+// LoginLimitError is returned when a login may not be tried now. It unwraps
+// to one of the login limit errors above.
+type LoginLimitError struct {
+	Rule    error
+	RetryAt time.Time
+}
+
+func (e *LoginLimitError) Error() string {
+	return fmt.Sprintf("%s, try again at %s", e.Rule.Error(), e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *LoginLimitError) Unwrap() error {
+	return e.Rule
+}
+
+// maxTrackedLogins is how many addresses and usernames are tracked before
+// the ones without recent attempts are forgotten.
+const maxTrackedLogins = 10000
+
+// loginFailures tracks the failed logins in a row of a username.
+type loginFailures struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginLimiter throttles logins per address and per username, slows down
+// repeated failures and locks usernames after too many of them. Usernames
+// are tracked whether or not the account exists, so that the limits do not
+// tell which accounts do.
+type LoginLimiter struct {
+	Config LoginLimitConfig
+	Clock  func() time.Time
+
+	mu           sync.Mutex
+	ipAttempts   map[string][]time.Time
+	userAttempts map[string][]time.Time
+	failures     map[string]*loginFailures
+}
+
+func NewLoginLimiter(config LoginLimitConfig) *LoginLimiter {
+	return &LoginLimiter{
+		Config:       config,
+		ipAttempts:   make(map[string][]time.Time),
+		userAttempts: make(map[string][]time.Time),
+		failures:     make(map[string]*loginFailures),
+	}
+}
+
+func (l *LoginLimiter) now() time.Time {
+	if l.Clock != nil {
+		return l.Clock()
+	}
+	return time.Now()
+}
+
+// Allow decides whether a login may be tried now and counts it if so.
+func (l *LoginLimiter) Allow(username, ip string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.ipAttempts)+len(l.userAttempts)+len(l.failures) > maxTrackedLogins {
+		l.prune(now)
+	}
+
+	if failures := l.currentFailures(username, now); failures != nil {
+		if now.Before(failures.lockedUntil) {
+			return &LoginLimitError{Rule: ErrAccountLocked, RetryAt: failures.lockedUntil}
+		}
+		if delay := l.delay(failures.count); delay > 0 && now.Before(failures.lastFailure.Add(delay)) {
+			return &LoginLimitError{Rule: ErrLoginDelayed, RetryAt: failures.lastFailure.Add(delay)}
+		}
+	}
+
+	ipAttempts := recentAttempts(l.ipAttempts[ip], now, l.Config.IPWindow)
+	if l.Config.IPAttempts > 0 && len(ipAttempts) >= l.Config.IPAttempts {
+		return &LoginLimitError{Rule: ErrLoginRateLimited, RetryAt: ipAttempts[len(ipAttempts)-l.Config.IPAttempts].Add(l.Config.IPWindow)}
+	}
+	userAttempts := recentAttempts(l.userAttempts[username], now, l.Config.UserWindow)
+	if l.Config.UserAttempts > 0 && len(userAttempts) >= l.Config.UserAttempts {
+		return &LoginLimitError{Rule: ErrLoginRateLimited, RetryAt: userAttempts[len(userAttempts)-l.Config.UserAttempts].Add(l.Config.UserWindow)}
+	}
+
+	if l.Config.IPAttempts > 0 {
+		l.ipAttempts[ip] = append(ipAttempts, now)
+	}
+	if l.Config.UserAttempts > 0 {
+		l.userAttempts[username] = append(userAttempts, now)
+	}
+	return nil
+}
+
+// Fail records a failed login. It returns the end of the lockout when this
+// failure locked the username.
+func (l *LoginLimiter) Fail(username string) (lockedUntil time.Time, locked bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	failures := l.currentFailures(username, now)
+	if failures == nil {
+		failures = &loginFailures{}
+		l.failures[username] = failures
+	}
+	failures.count++
+	failures.lastFailure = now
+
+	if l.Config.LockoutThreshold > 0 && failures.count >= l.Config.LockoutThreshold {
+		// The count starts over, so that the lockout does not repeat on the
+		// first failure after it ends
+		failures.count = 0
+		failures.lockedUntil = now.Add(l.Config.LockoutDuration)
+		return failures.lockedUntil, true
+	}
+	return time.Time{}, false
+}
+
+// Succeed forgets the failures of a username after a successful login.
+func (l *LoginLimiter) Succeed(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, username)
+}
+
+// Unlock lifts the lockout and the delays of a username. It reports whether
+// the username was locked.
+func (l *LoginLimiter) Unlock(username string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failures, exists := l.failures[username]
+	locked := exists && l.now().Before(failures.lockedUntil)
+	delete(l.failures, username)
+	delete(l.userAttempts, username)
+	return locked
+}
+
+// currentFailures returns the failures of a username. Failures are forgotten
+// once the username is not locked and has gone forgetAfter without one.
+func (l *LoginLimiter) currentFailures(username string, now time.Time) *loginFailures {
+	failures, exists := l.failures[username]
+	if !exists {
+		return nil
+	}
+	if now.Before(failures.lockedUntil) || now.Before(failures.lastFailure.Add(l.forgetAfter())) {
+		return failures
+	}
+	delete(l.failures, username)
+	return nil
+}
+
+// delay is how long to wait after the last of count failures in a row.
+func (l *LoginLimiter) delay(count int) time.Duration {
+	if l.Config.DelayAfter <= 0 || count < l.Config.DelayAfter || l.Config.BaseDelay <= 0 {
+		return 0
+	}
+	delay := l.Config.BaseDelay
+	for i := l.Config.DelayAfter; i < count && (l.Config.MaxDelay <= 0 || delay < l.Config.MaxDelay); i++ {
+		delay *= 2
+	}
+	if l.Config.MaxDelay > 0 && delay > l.Config.MaxDelay {
+		return l.Config.MaxDelay
+	}
+	return delay
+}
+
+// forgetAfter is how long failures are remembered: a lockout duration, or an
+// hour without lockouts, and always well past the longest delay.
+func (l *LoginLimiter) forgetAfter() time.Duration {
+	forget := l.Config.LockoutDuration
+	if forget <= 0 {
+		forget = time.Hour
+	}
+	if forget < 2*l.Config.MaxDelay {
+		forget = 2 * l.Config.MaxDelay
+	}
+	return forget
+}
+
+// prune forgets the addresses and usernames without recent attempts.
+func (l *LoginLimiter) prune(now time.Time) {
+	for ip, attempts := range l.ipAttempts {
+		if len(recentAttempts(attempts, now, l.Config.IPWindow)) == 0 {
+			delete(l.ipAttempts, ip)
+		}
+	}
+	for username, attempts := range l.userAttempts {
+		if len(recentAttempts(attempts, now, l.Config.UserWindow)) == 0 {
+			delete(l.userAttempts, username)
+		}
+	}
+	for username := range l.failures {
+		l.currentFailures(username, now)
+	}
+}
+
+// recentAttempts drops the attempts that are older than the window.
+func recentAttempts(attempts []time.Time, now time.Time, window time.Duration) []time.Time {
+	for len(attempts) > 0 && !now.Before(attempts[0].Add(window)) {
+		attempts = attempts[1:]
+	}
+	return attempts
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLimiterRateLimits(t *testing.T) {
+	limiter, now := newTestLimiter(LoginLimitConfig{IPAttempts: 3, IPWindow: time.Minute, UserAttempts: 2, UserWindow: time.Minute})
+	start := *now
+
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+	*now = now.Add(10 * time.Second)
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+	assertLimited(t, limiter.Allow("alice", "192.0.2.2"), ErrLoginRateLimited, start.Add(time.Minute))
+
+	// Another username from the same address until the address is used up
+	assert.NoError(t, limiter.Allow("bob", "192.0.2.1"))
+	assertLimited(t, limiter.Allow("carol", "192.0.2.1"), ErrLoginRateLimited, start.Add(time.Minute))
+	assert.NoError(t, limiter.Allow("carol", "192.0.2.3"))
+
+	// The window slides
+	*now = start.Add(time.Minute)
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.2"))
+	assertLimited(t, limiter.Allow("alice", "192.0.2.2"), ErrLoginRateLimited, start.Add(70*time.Second))
+}
+
+func TestLoginLimiterProgressiveDelay(t *testing.T) {
+	limiter, now := newTestLimiter(LoginLimitConfig{DelayAfter: 2, BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+		limiter.Fail("alice")
+	}
+	assertLimited(t, limiter.Allow("alice", "192.0.2.1"), ErrLoginDelayed, now.Add(time.Second))
+
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		*now = now.Add(delay - time.Millisecond)
+		assert.Error(t, limiter.Allow("alice", "192.0.2.1"), "expected a delay of %s", delay)
+		*now = now.Add(time.Millisecond)
+		assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+		limiter.Fail("alice")
+	}
+
+	// Other usernames are not slowed down
+	assert.NoError(t, limiter.Allow("bob", "192.0.2.1"))
+
+	limiter.Succeed("alice")
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+}
+
+func TestLoginLimiterLockout(t *testing.T) {
+	limiter, now := newTestLimiter(LoginLimitConfig{LockoutThreshold: 3, LockoutDuration: 15 * time.Minute})
+
+	for i := 0; i < 2; i++ {
+		_, locked := limiter.Fail("alice")
+		assert.False(t, locked)
+	}
+	lockedUntil, locked := limiter.Fail("alice")
+	assert.True(t, locked)
+	assert.Equal(t, now.Add(15*time.Minute), lockedUntil)
+	assertLimited(t, limiter.Allow("alice", "192.0.2.1"), ErrAccountLocked, lockedUntil)
+
+	*now = lockedUntil
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+	_, locked = limiter.Fail("alice")
+	assert.False(t, locked, "expected the failures to start over after a lockout")
+
+	// Failures are forgotten after a lockout duration without one
+	limiter.Fail("alice")
+	*now = now.Add(15 * time.Minute)
+	_, locked = limiter.Fail("alice")
+	assert.False(t, locked)
+}
+
+func TestLoginLimiterUnlock(t *testing.T) {
+	limiter, _ := newTestLimiter(LoginLimitConfig{UserAttempts: 1, UserWindow: time.Minute, LockoutThreshold: 1, LockoutDuration: time.Hour})
+
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+	limiter.Fail("alice")
+	assert.ErrorIs(t, limiter.Allow("alice", "192.0.2.1"), ErrAccountLocked)
+
+	assert.True(t, limiter.Unlock("alice"))
+	assert.NoError(t, limiter.Allow("alice", "192.0.2.1"))
+	assert.False(t, limiter.Unlock("bob"))
+}
+
+func TestAuthServiceLoginLimits(t *testing.T) {
+	authService := newTestAuthService(t, "alice")
+	limiter, now := newTestLimiter(LoginLimitConfig{LockoutThreshold: 2, LockoutDuration: 10 * time.Minute})
+	authService.Limiter = limiter
+	authService.Clock = limiter.Clock
+
+	assert.Error(t, authService.Login("alice", "wrong", "192.0.2.1"))
+	assert.Error(t, authService.Login("alice", "wrong", "192.0.2.1"))
+	// The right password does not help while locked
+	assert.ErrorIs(t, authService.Login("alice", "Password123!", "192.0.2.1"), ErrAccountLocked)
+
+	wasLocked, err := authService.UnlockUser("alice", "admin")
+	assert.NoError(t, err)
+	assert.True(t, wasLocked)
+	assert.NoError(t, authService.Login("alice", "Password123!", "192.0.2.1"))
+	_, err = authService.UnlockUser("nobody", "admin")
+	assert.ErrorIs(t, err, database.ErrUserNotFound)
+
+	var types []string
+	for _, event := range authService.ListAuditEvents("alice", 0) {
+		types = append(types, event.Type)
+		assert.Equal(t, *now, event.Time)
+	}
+	assert.Equal(t, []string{
+		models.AuditLoginSucceeded,
+		models.AuditAccountUnlocked,
+		models.AuditLoginThrottled,
+		models.AuditAccountLocked,
+		models.AuditLoginFailed,
+		models.AuditLoginFailed,
+	}, types)
+
+	events := authService.ListAuditEvents("", 2)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "admin", events[1].Actor)
+		assert.Equal(t, "192.0.2.1", events[0].IP)
+	}
+}
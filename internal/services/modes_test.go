@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizServicePracticeMode(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.DB.UpdateUser(database.User{Username: "testuser", Score: 7, QuizTaken: 1}))
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "practice", Mode: models.QuizModePractice}))
+	db := s.DB
+	assert.NoError(t, s.StartQuiz("testuser", "practice"))
+
+	question, err := s.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+	assert.Empty(t, question.Explanation, "expected the explanation to be hidden until answered")
+
+	result, err := s.SubmitAnswer("testuser", 0, 1)
+	assert.NoError(t, err)
+	assert.False(t, result.Correct)
+	assert.True(t, result.CanRetry, "expected a wrong practice answer to allow a retry")
+	assert.Equal(t, "Two plus two is four", result.Explanation)
+
+	result, err = s.SubmitAnswer("testuser", 0, 2)
+	assert.NoError(t, err, "expected a retry to be accepted")
+	assert.True(t, result.Correct)
+	assert.False(t, result.CanRetry)
+
+	score, err := s.GetResults("testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, CorrectAnswerPoints, score, "expected the practice score to come from the attempt")
+
+	user, _ := db.GetUser("testuser")
+	assert.Equal(t, 7, user.Score, "expected practice not to touch the ranked score")
+	assert.Equal(t, 1, user.QuizTaken, "expected practice not to count as a quiz taken")
+
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	assert.False(t, attempt.Ranked)
+	assert.Equal(t, 2, attempt.Answers[0].Tries)
+}
+
+func TestQuizServiceExamMode(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.DB.UpdateUser(database.User{Username: "testuser", Score: 7, QuizTaken: 1}))
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "exam", Mode: models.QuizModeExam, MaxAttempts: 1}))
+	db := s.DB
+	assert.NoError(t, s.StartQuiz("testuser", "exam"))
+
+	_, err := s.SubmitAnswer("testuser", 0, 2)
+	assert.ErrorIs(t, err, ErrQuestionNotServed, "expected exam answers to require a served question")
+
+	_, _ = s.GetNextQuestion("testuser")
+	result, err := s.SubmitAnswer("testuser", 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.QuizModeExam, result.Mode)
+	assert.False(t, result.CanRetry)
+
+	_, err = s.SubmitAnswer("testuser", 0, 1)
+	assert.ErrorIs(t, err, ErrAnswerLocked, "expected exam answers to be final")
+
+	user, _ := db.GetUser("testuser")
+	assert.Equal(t, 0, user.Score, "expected exam points to stay hidden while the exam runs")
+	assert.Equal(t, 2, user.QuizTaken)
+
+	for index := 1; index < len(testQuestions); index++ {
+		_, _ = s.GetNextQuestion("testuser")
+		_, err = s.SubmitAnswer("testuser", index, 3)
+		assert.NoError(t, err)
+	}
+	_, err = s.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+
+	user, _ = db.GetUser("testuser")
+	assert.Equal(t, CorrectAnswerPoints, user.Score, "expected exam points to count once the exam completes")
+
+	err = s.StartQuiz("testuser", "exam")
+	assert.ErrorIs(t, err, ErrMaxAttemptsReached)
+}
+
+func TestQuizServiceExamNoRevisiting(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.DB.UpdateUser(database.User{Username: "testuser", Score: 7, QuizTaken: 1}))
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "exam", Mode: models.QuizModeExam}))
+	assert.NoError(t, s.StartQuiz("testuser", "exam"))
+
+	_, _ = s.GetNextQuestion("testuser")
+	_, _ = s.GetNextQuestion("testuser")
+
+	_, err := s.SubmitAnswer("testuser", 0, 2)
+	assert.ErrorIs(t, err, ErrAnswerLocked, "expected earlier questions to be closed once the next one is served")
+
+	_, err = s.SubmitAnswer("testuser", 1, 1)
+	assert.NoError(t, err)
+}
+
+func TestQuizServiceListQuizzes(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.DB.UpdateUser(database.User{Username: "testuser", Score: 7, QuizTaken: 1}))
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "exam", Mode: models.QuizModeExam}))
+
+	quizzes := s.ListQuizzes()
+	assert.Len(t, quizzes, 2)
+	assert.Equal(t, models.DefaultQuizID, quizzes[0].QuizID, "expected the general quiz to always be listed")
+
+	err := s.StartQuiz("testuser", "unknown")
+	assert.ErrorIs(t, err, database.ErrQuizNotFound)
+}
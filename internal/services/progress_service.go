@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// weakTopicMinAnswers keeps topics answered once or twice out of the weakest topics.
+	weakTopicMinAnswers = 3
+	weakestTopicsLimit  = 3
+)
+
+// ProgressService collects the dashboard data of a user from their attempts.
+type ProgressService struct {
+	DB       *database.MemoryDB
+	Clock    func() time.Time
+	Location *time.Location
+	// Progression is the level curve, it should match the one of the QuizService
+	Progression ProgressionConfig
+}
+
+func NewProgressService(db *database.MemoryDB) *ProgressService {
+	return &ProgressService{DB: db, Location: time.UTC, Progression: DefaultProgressionConfig()}
+}
+
+func (s *ProgressService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// GetProgress summarizes the attempts and answers of a user.
+func (s *ProgressService) GetProgress(username string) (*models.Progress, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	byID := make(map[int]models.Question, len(questions))
+	for _, question := range questions {
+		byID[question.QuestionID] = question
+	}
+
+	progress := &models.Progress{
+		Username:      username,
+		Attempts:      []models.AttemptSummary{},
+		WeakestTopics: []models.TopicAccuracy{},
+		Profile:       user.Profile,
+	}
+	// Players who never completed an attempt are on the first level too
+	progress.Profile.Level, progress.Profile.NextLevelXP = s.Progression.level(user.Profile.XP)
+	categories := make(map[string]*models.TopicAccuracy)
+	tags := make(map[string]*models.TopicAccuracy)
+	var answers []models.AnswerRecord
+	var answerSeconds float64
+	timed := 0
+	daysPlayed := make(map[string]bool)
+
+	for _, attempt := range s.DB.ListAttempts(username) {
+		summary := models.AttemptSummary{
+			AttemptID:   attempt.AttemptID,
+			QuizID:      attempt.QuizID,
+			Mode:        attempt.Mode,
+			Status:      attempt.Status,
+			StartedAt:   attempt.StartedAt,
+			CompletedAt: attempt.CompletedAt,
+			Score:       attempt.Score,
+		}
+		for _, record := range attempt.Answers {
+			if record.AnsweredAt.IsZero() {
+				continue
+			}
+			answers = append(answers, record)
+			daysPlayed[record.AnsweredAt.In(s.Location).Format("2006-01-02")] = true
+			summary.Answered++
+			if record.Correct {
+				summary.Correct++
+			}
+			if !record.ServedAt.IsZero() {
+				answerSeconds += record.AnsweredAt.Sub(record.ServedAt).Seconds()
+				timed++
+			}
+
+			question, known := byID[record.QuestionID]
+			if !known {
+				continue
+			}
+			if question.Category != "" {
+				countTopic(categories, question.Category, record.Correct)
+			}
+			for _, tag := range question.Tags {
+				countTopic(tags, tag, record.Correct)
+			}
+		}
+		summary.Accuracy = accuracy(summary.Correct, summary.Answered)
+		progress.Attempts = append(progress.Attempts, summary)
+	}
+
+	progress.Categories = sortedTopics(categories)
+	progress.Tags = sortedTopics(tags)
+	if timed > 0 {
+		progress.AverageAnswerSeconds = answerSeconds / float64(timed)
+	}
+	progress.Streaks = s.streaks(answers, daysPlayed)
+
+	for _, topic := range append(append([]models.TopicAccuracy{}, progress.Categories...), progress.Tags...) {
+		if topic.Answered >= weakTopicMinAnswers && topic.Correct < topic.Answered {
+			progress.WeakestTopics = append(progress.WeakestTopics, topic)
+		}
+	}
+	sort.SliceStable(progress.WeakestTopics, func(i, j int) bool {
+		return progress.WeakestTopics[i].Accuracy < progress.WeakestTopics[j].Accuracy
+	})
+	if len(progress.WeakestTopics) > weakestTopicsLimit {
+		progress.WeakestTopics = progress.WeakestTopics[:weakestTopicsLimit]
+	}
+
+	logger.Info("Progress computed", zap.String("username", username), zap.Int("attempts", len(progress.Attempts)), zap.Int("answers", len(answers)))
+	return progress, nil
+}
+
+// streaks counts the correct answers in a row up to the latest answer, the
+// best such run, and the days in a row with answers up to today or yesterday.
+func (s *ProgressService) streaks(answers []models.AnswerRecord, daysPlayed map[string]bool) models.Streaks {
+	sort.SliceStable(answers, func(i, j int) bool {
+		return answers[i].AnsweredAt.Before(answers[j].AnsweredAt)
+	})
+
+	var streaks models.Streaks
+	run := 0
+	for _, record := range answers {
+		if record.Correct {
+			run++
+			streaks.BestCorrectAnswers = max(streaks.BestCorrectAnswers, run)
+		} else {
+			run = 0
+		}
+	}
+	streaks.CorrectAnswers = run
+
+	streaks.DaysPlayed = dayStreak(daysPlayed, s.now().In(s.Location))
+	return streaks
+}
+
+// dayStreak counts the days in a row, keyed as 2006-01-02, up to today or
+// yesterday: a streak is not broken before the end of today.
+func dayStreak(daysPlayed map[string]bool, today time.Time) int {
+	day := today
+	if !daysPlayed[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for daysPlayed[day.Format("2006-01-02")] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+func countTopic(topics map[string]*models.TopicAccuracy, name string, correct bool) {
+	topic, exists := topics[name]
+	if !exists {
+		topic = &models.TopicAccuracy{Topic: name}
+		topics[name] = topic
+	}
+	topic.Answered++
+	if correct {
+		topic.Correct++
+	}
+}
+
+func sortedTopics(topics map[string]*models.TopicAccuracy) []models.TopicAccuracy {
+	sorted := make([]models.TopicAccuracy, 0, len(topics))
+	for _, topic := range topics {
+		topic.Accuracy = accuracy(topic.Correct, topic.Answered)
+		sorted = append(sorted, *topic)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Topic < sorted[j].Topic
+	})
+	return sorted
+}
+
+func accuracy(correct, answered int) float64 {
+	if answered == 0 {
+		return 0
+	}
+	return float64(correct) / float64(answered)
+}
+
+var _ IProgressService = &ProgressService{}
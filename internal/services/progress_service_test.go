@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProgress(t *testing.T) {
+	db := database.NewMemoryDB()
+	NewQuizService(db).LoadQuestions([]models.Question{
+		{QuestionID: 1, Question: "Go?", Options: []string{"a", "b", "c"}, Answer: 1, Category: "technology", Tags: []string{"go"}},
+		{QuestionID: 2, Question: "Orwell?", Options: []string{"a", "b", "c"}, Answer: 2, Category: "literature", Tags: []string{"novels"}},
+	})
+	db.AddUser(database.User{Username: "testuser"})
+
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	s := NewProgressService(db)
+	s.Clock = func() time.Time { return now }
+
+	answer := func(questionID int, correct bool, answeredAt time.Time) models.AnswerRecord {
+		return models.AnswerRecord{QuestionIndex: questionID - 1, QuestionID: questionID, Correct: correct, ServedAt: answeredAt.Add(-10 * time.Second), AnsweredAt: answeredAt}
+	}
+	twoDaysAgo, yesterday := now.AddDate(0, 0, -2), now.AddDate(0, 0, -1)
+	db.AddAttempt(database.Attempt{
+		AttemptID: "first", Username: "testuser", QuizID: "general", Status: models.AttemptCompleted, Score: 1, StartedAt: twoDaysAgo,
+		Answers: []models.AnswerRecord{answer(1, true, twoDaysAgo), answer(2, false, twoDaysAgo.Add(time.Minute))},
+	})
+	db.AddAttempt(database.Attempt{
+		AttemptID: "second", Username: "testuser", QuizID: "general", Status: models.AttemptActive, StartedAt: yesterday,
+		Answers: []models.AnswerRecord{
+			answer(2, false, yesterday),
+			answer(1, true, yesterday.Add(time.Minute)),
+			answer(2, true, yesterday.Add(2*time.Minute)),
+			{QuestionIndex: 0, QuestionID: 1, ServedAt: yesterday.Add(3 * time.Minute)},
+		},
+	})
+
+	progress, err := s.GetProgress("testuser")
+	assert.NoError(t, err)
+
+	assert.Len(t, progress.Attempts, 2)
+	assert.Equal(t, "first", progress.Attempts[0].AttemptID)
+	assert.Equal(t, 3, progress.Attempts[1].Answered, "expected the pending question not to count")
+	assert.InDelta(t, 2.0/3, progress.Attempts[1].Accuracy, 1e-9)
+
+	assert.Equal(t, []models.TopicAccuracy{
+		{Topic: "literature", Answered: 3, Correct: 1, Accuracy: 1.0 / 3},
+		{Topic: "technology", Answered: 2, Correct: 2, Accuracy: 1},
+	}, progress.Categories)
+	assert.Len(t, progress.Tags, 2)
+	assert.Equal(t, 10.0, progress.AverageAnswerSeconds)
+
+	assert.Equal(t, models.Streaks{CorrectAnswers: 2, BestCorrectAnswers: 2, DaysPlayed: 2}, progress.Streaks)
+	assert.Equal(t, []string{"literature", "novels"}, []string{progress.WeakestTopics[0].Topic, progress.WeakestTopics[1].Topic})
+
+	assert.Equal(t, models.PlayerProfile{Level: 1, NextLevelXP: 100}, progress.Profile, "expected new players on the first level")
+
+	_, err = s.GetProgress("nobody")
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+)
+
+// ProgressionConfig controls the experience points a completed attempt earns,
+// the curve of levels and the freeze tokens of the daily-play streak.
+type ProgressionConfig struct {
+	XPPerAttempt       int
+	XPPerCorrectAnswer int
+	// LevelBaseXP is the XP needed to go from level 1 to 2, every next level
+	// needs LevelGrowth times as much as the previous one
+	LevelBaseXP int
+	LevelGrowth float64
+	// A freeze token is earned every FreezeTokenEvery days of the streak, up to MaxFreezeTokens
+	FreezeTokenEvery int
+	MaxFreezeTokens  int
+	// Location is where the days of the streak start
+	Location *time.Location
+}
+
+func DefaultProgressionConfig() ProgressionConfig {
+	return ProgressionConfig{
+		XPPerAttempt:       20,
+		XPPerCorrectAnswer: 10,
+		LevelBaseXP:        100,
+		LevelGrowth:        1.5,
+		FreezeTokenEvery:   7,
+		MaxFreezeTokens:    2,
+		Location:           time.UTC,
+	}
+}
+
+// level returns the level reached with the given XP and the total XP at
+// which the next level starts.
+func (c ProgressionConfig) level(xp int) (level, nextLevelXP int) {
+	level, threshold, step := 1, 0, float64(c.LevelBaseXP)
+	if step < 1 {
+		step = 1
+	}
+	for {
+		next := threshold + int(math.Round(step))
+		if xp < next {
+			return level, next
+		}
+		level, threshold = level+1, next
+		step *= math.Max(c.LevelGrowth, 1)
+	}
+}
+
+// award adds the XP of a completed attempt to the profile and moves the
+// daily-play streak to the day the attempt was completed. It returns the XP
+// earned.
+func (c ProgressionConfig) award(profile *models.PlayerProfile, attempt models.Attempt) int {
+	xp := c.XPPerAttempt
+	for _, record := range attempt.Answers {
+		if record.Correct {
+			xp += c.XPPerCorrectAnswer
+		}
+	}
+	profile.XP += xp
+	profile.Level, profile.NextLevelXP = c.level(profile.XP)
+
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+	c.extendStreak(profile, attempt.CompletedAt.In(location))
+	return xp
+}
+
+// extendStreak counts the day into the streak. Missed days are covered by
+// freeze tokens when there are enough of them, otherwise the streak restarts.
+func (c ProgressionConfig) extendStreak(profile *models.PlayerProfile, day time.Time) {
+	today := day.Format("2006-01-02")
+	if profile.LastPlayedDay == today {
+		return
+	}
+
+	missed := 0
+	if last, err := time.Parse("2006-01-02", profile.LastPlayedDay); err == nil {
+		current, _ := time.Parse("2006-01-02", today)
+		missed = int(current.Sub(last).Hours()/24) - 1
+	}
+	if missed < 0 {
+		// An attempt completed before the last day played does not move the streak
+		return
+	}
+	if missed > 0 && missed <= profile.FreezeTokens {
+		profile.FreezeTokens -= missed
+	} else if missed > 0 {
+		profile.DayStreak = 0
+	}
+
+	profile.DayStreak++
+	profile.LastPlayedDay = today
+	if profile.DayStreak > profile.BestDayStreak {
+		profile.BestDayStreak = profile.DayStreak
+	}
+	if c.FreezeTokenEvery > 0 && profile.DayStreak%c.FreezeTokenEvery == 0 && profile.FreezeTokens < c.MaxFreezeTokens {
+		profile.FreezeTokens++
+	}
+}
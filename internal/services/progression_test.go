@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressionLevels(t *testing.T) {
+	c := DefaultProgressionConfig()
+	tests := []struct {
+		xp, level, nextLevelXP int
+	}{
+		{0, 1, 100},
+		{99, 1, 100},
+		{100, 2, 250},
+		{250, 3, 475},
+		{474, 3, 475},
+	}
+	for _, tt := range tests {
+		level, next := c.level(tt.xp)
+		assert.Equal(t, tt.level, level, "level at %d XP", tt.xp)
+		assert.Equal(t, tt.nextLevelXP, next, "next level at %d XP", tt.xp)
+	}
+}
+
+func TestProgressionStreakWithFreezeTokens(t *testing.T) {
+	c := DefaultProgressionConfig()
+	c.FreezeTokenEvery = 2
+	c.MaxFreezeTokens = 1
+	day := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	play := func(profile *models.PlayerProfile, offset int) {
+		c.award(profile, models.Attempt{CompletedAt: day.AddDate(0, 0, offset)})
+	}
+
+	profile := &models.PlayerProfile{}
+	play(profile, 0)
+	play(profile, 0)
+	play(profile, 1)
+	assert.Equal(t, 2, profile.DayStreak, "expected one count per day")
+	assert.Equal(t, 1, profile.FreezeTokens)
+
+	play(profile, 3)
+	assert.Equal(t, 3, profile.DayStreak, "expected a freeze token to cover the missed day")
+	assert.Equal(t, 0, profile.FreezeTokens)
+
+	play(profile, 5)
+	assert.Equal(t, 1, profile.DayStreak, "expected the streak to restart without freeze tokens")
+	assert.Equal(t, 3, profile.BestDayStreak)
+	assert.Equal(t, "2025-01-15", profile.LastPlayedDay)
+}
+
+func TestQuizServiceCompletionAwardsXP(t *testing.T) {
+	s := newTestQuizService(t, testQuestions, "testuser")
+	assert.NoError(t, s.RegisterQuiz(DefaultQuiz()))
+	assert.NoError(t, s.StartQuiz("testuser", ""))
+	s.Clock, _ = newTestClock()
+	db := s.DB
+
+	for _, answer := range []int{2, 2, 2} {
+		question, err := s.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+		_, err = s.SubmitAnswer("testuser", question.QuestionID-1, answer)
+		assert.NoError(t, err)
+	}
+	_, err := s.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+
+	user, _ := db.GetUser("testuser")
+	assert.Equal(t, models.PlayerProfile{XP: 40, Level: 1, NextLevelXP: 100, DayStreak: 1, BestDayStreak: 1, LastPlayedDay: "2025-01-10"}, user.Profile)
+	attempt, _ := db.GetAttempt(user.ActiveAttemptID)
+	assert.Equal(t, 40, attempt.XPEarned)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrMaxAttemptsReached = errors.New("maximum number of attempts reached")
+	ErrCooldownActive     = errors.New("cooldown between attempts is active")
+	ErrQuizNotOpen        = errors.New("quiz is not open yet")
+	ErrQuizClosed         = errors.New("quiz is closed")
+	ErrNotInvited         = errors.New("quiz is only open to invited players")
+)
+
+// QuizRuleError is returned by StartQuiz when a rule of the quiz blocks a new
+// attempt. It unwraps to one of the rule errors above.
+type QuizRuleError struct {
+	Rule    error
+	QuizID  string
+	RetryAt time.Time
+}
+
+func (e *QuizRuleError) Error() string {
+	if e.RetryAt.IsZero() {
+		return e.Rule.Error()
+	}
+	return fmt.Sprintf("%s, try again at %s", e.Rule.Error(), e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *QuizRuleError) Unwrap() error {
+	return e.Rule
+}
+
+// checkQuizRules decides whether a new attempt of the quiz may start now.
+func checkQuizRules(quiz models.Quiz, username string, attempts []database.Attempt, now time.Time) error {
+	if !quiz.Allows(username) {
+		return &QuizRuleError{Rule: ErrNotInvited, QuizID: quiz.QuizID}
+	}
+	if quiz.OpensAt != nil && now.Before(*quiz.OpensAt) {
+		return &QuizRuleError{Rule: ErrQuizNotOpen, QuizID: quiz.QuizID, RetryAt: *quiz.OpensAt}
+	}
+	if quiz.ClosesAt != nil && !now.Before(*quiz.ClosesAt) {
+		return &QuizRuleError{Rule: ErrQuizClosed, QuizID: quiz.QuizID}
+	}
+
+	var taken []database.Attempt
+	for _, attempt := range attempts {
+		if attempt.QuizID == quiz.QuizID {
+			taken = append(taken, attempt)
+		}
+	}
+
+	if quiz.MaxAttempts > 0 && len(taken) >= quiz.MaxAttempts {
+		return &QuizRuleError{Rule: ErrMaxAttemptsReached, QuizID: quiz.QuizID}
+	}
+
+	if quiz.Cooldown() > 0 && len(taken) > 0 {
+		retryAt := attemptEndedAt(taken[len(taken)-1]).Add(quiz.Cooldown())
+		if now.Before(retryAt) {
+			return &QuizRuleError{Rule: ErrCooldownActive, QuizID: quiz.QuizID, RetryAt: retryAt}
+		}
+	}
+	return nil
+}
+
+// attemptEndedAt is the moment an attempt stopped, used to measure cooldowns.
+// Attempts that never finished count from their start.
+func attemptEndedAt(attempt database.Attempt) time.Time {
+	switch {
+	case !attempt.CompletedAt.IsZero():
+		return attempt.CompletedAt
+	case attempt.Status == models.AttemptExpired && !attempt.Deadline.IsZero():
+		return attempt.Deadline
+	}
+	return attempt.StartedAt
+}
+
+// countedScore applies the scoring policy of a quiz to the scores of its
+// completed attempts, given in the order they were played.
+func countedScore(policy models.ScoringPolicy, scores []int) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	switch policy {
+	case models.ScoreLast:
+		return float64(scores[len(scores)-1])
+	case models.ScoreAverage:
+		total := 0
+		for _, score := range scores {
+			total += score
+		}
+		return float64(total) / float64(len(scores))
+	}
+
+	best := scores[0]
+	for _, score := range scores[1:] {
+		best = max(best, score)
+	}
+	return float64(best)
+}
+
+// GetCountedScore returns the score that counts for the user on a quiz,
+// according to the quiz's scoring policy.
+func (s *QuizService) GetCountedScore(username, quizID string) (float64, error) {
+	logger := utils.GetLogger().Sugar()
+
+	quiz, err := s.getQuiz(quizID)
+	if err != nil {
+		return 0, fmt.Errorf("quiz not found: %w", err)
+	}
+
+	var scores []int
+	for _, attempt := range s.DB.ListAttempts(username) {
+		if attempt.QuizID == quiz.QuizID && attempt.Status == models.AttemptCompleted {
+			scores = append(scores, attempt.Score)
+		}
+	}
+	if len(scores) == 0 {
+		return 0, ErrNoStatsForUser
+	}
+
+	score := countedScore(quiz.ScoringPolicy, scores)
+	logger.Info("Counted score calculated",
+		zap.String("username", username),
+		zap.String("quizID", quiz.QuizID),
+		zap.String("policy", string(quiz.ScoringPolicy)),
+		zap.Float64("score", score))
+	return score, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizServiceStartQuizRules(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	opens := now.Add(time.Hour)
+	closes := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		quiz     models.Quiz
+		expected error
+		retryAt  time.Time
+	}{
+		{"Open quiz", models.Quiz{QuizID: "quiz"}, nil, time.Time{}},
+		{"Not open yet", models.Quiz{QuizID: "quiz", OpensAt: &opens}, ErrQuizNotOpen, opens},
+		{"Closed", models.Quiz{QuizID: "quiz", ClosesAt: &closes}, ErrQuizClosed, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewMemoryDB()
+			s := NewQuizService(db)
+			s.Clock = func() time.Time { return now }
+			assert.NoError(t, s.RegisterQuiz(tt.quiz))
+			db.AddUser(database.User{Username: "testuser"})
+
+			err := s.StartQuiz("testuser", "quiz")
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+			var ruleErr *QuizRuleError
+			assert.True(t, errors.As(err, &ruleErr), "expected a typed rule error")
+			assert.Equal(t, tt.retryAt, ruleErr.RetryAt)
+		})
+	}
+}
+
+func TestQuizServiceCooldownAndMaxAttempts(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	db := database.NewMemoryDB()
+	s := NewQuizService(db)
+	s.Clock = func() time.Time { return now }
+	s.LoadQuestions([]models.Question{{QuestionID: 1, Question: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 2}})
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "quiz", MaxAttempts: 2, CooldownSeconds: 3600}))
+	db.AddUser(database.User{Username: "testuser"})
+
+	assert.NoError(t, s.StartQuiz("testuser", "quiz"))
+	_, _ = s.GetNextQuestion("testuser")
+	_, _ = s.SubmitAnswer("testuser", 0, 2)
+	_, err := s.GetNextQuestion("testuser")
+	assert.ErrorIs(t, err, ErrQuizComplete)
+
+	now = now.Add(30 * time.Minute)
+	err = s.StartQuiz("testuser", "quiz")
+	assert.ErrorIs(t, err, ErrCooldownActive)
+	var ruleErr *QuizRuleError
+	assert.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, now.Add(30*time.Minute), ruleErr.RetryAt, "expected the cooldown to run from the end of the last attempt")
+
+	now = now.Add(time.Hour)
+	assert.NoError(t, s.StartQuiz("testuser", "quiz"))
+
+	now = now.Add(2 * time.Hour)
+	err = s.StartQuiz("testuser", "quiz")
+	assert.ErrorIs(t, err, ErrMaxAttemptsReached)
+}
+
+func TestCountedScore(t *testing.T) {
+	scores := []int{3, 5, 1}
+
+	assert.Equal(t, 5.0, countedScore(models.ScoreBest, scores))
+	assert.Equal(t, 1.0, countedScore(models.ScoreLast, scores))
+	assert.Equal(t, 3.0, countedScore(models.ScoreAverage, scores))
+	assert.Equal(t, 0.0, countedScore(models.ScoreBest, nil))
+}
+
+func TestQuizServiceDefaultScoringPolicy(t *testing.T) {
+	db := database.NewMemoryDB()
+	s := NewQuizService(db)
+	assert.NoError(t, s.RegisterQuiz(DefaultQuiz()))
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "quiz"}))
+
+	for _, quizID := range []string{models.DefaultQuizID, "quiz"} {
+		quiz, err := db.GetQuiz(quizID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScoreBest, quiz.ScoringPolicy, quizID)
+	}
+}
+
+func TestQuizServiceGetCountedScore(t *testing.T) {
+	db := database.NewMemoryDB()
+	s := NewQuizService(db)
+	assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "quiz", ScoringPolicy: models.ScoreAverage}))
+
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i, score := range []int{2, 4} {
+		assert.NoError(t, db.AddAttempt(database.Attempt{
+			AttemptID: string(rune('a' + i)),
+			Username:  "testuser",
+			QuizID:    "quiz",
+			Status:    models.AttemptCompleted,
+			StartedAt: start.Add(time.Duration(i) * time.Hour),
+			Score:     score,
+		}))
+	}
+	assert.NoError(t, db.AddAttempt(database.Attempt{AttemptID: "c", Username: "testuser", QuizID: "quiz", Status: models.AttemptAbandoned, Score: 9}))
+
+	score, err := s.GetCountedScore("testuser", "quiz")
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, score, "expected only completed attempts to be averaged")
+
+	_, err = s.GetCountedScore("nobody", "quiz")
+	assert.ErrorIs(t, err, ErrNoStatsForUser)
+}
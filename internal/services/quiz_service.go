@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -10,15 +11,29 @@ import (
 	"github.com/Dzsodie/quiz_app/internal/database"
 	"github.com/Dzsodie/quiz_app/internal/models"
 	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type QuizService struct {
-	DB *database.MemoryDB
+	DB          *database.MemoryDB
+	Lifelines   LifelineConfig
+	Progression ProgressionConfig
+	Clock       func() time.Time
+
+	listeners       []AttemptListener
+	answerListeners []AnswerListener
 }
 
 func NewQuizService(db *database.MemoryDB) *QuizService {
-	return &QuizService{DB: db}
+	return &QuizService{DB: db, Lifelines: DefaultLifelineConfig(), Progression: DefaultProgressionConfig()}
+}
+
+func (s *QuizService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
 }
 
 var (
@@ -28,6 +43,18 @@ var (
 )
 var ErrNoStatsForUser = errors.New("no stats available for user")
 
+var (
+	ErrNoActiveAttempt = errors.New("quiz not started")
+	ErrQuizComplete    = errors.New("quiz complete")
+	ErrAttemptPaused   = errors.New("quiz is paused")
+	ErrAttemptExpired  = errors.New("quiz time limit expired")
+
+	ErrAnswerLocked      = errors.New("answer cannot be changed in exam mode")
+	ErrQuestionNotInQuiz = errors.New("question is not part of this quiz")
+)
+
+// GetQuestions lists the questions the way they are served, without the hints
+// and explanations.
 func (s *QuizService) GetQuestions() ([]models.Question, error) {
 	logger := utils.GetLogger().Sugar()
 	quizMu.Lock()
@@ -37,8 +64,12 @@ func (s *QuizService) GetQuestions() ([]models.Question, error) {
 		logger.Warn("Attempted to get questions but none are available")
 		return nil, errors.New("no questions available")
 	}
+	listed := make([]models.Question, len(questions))
+	for i, question := range questions {
+		listed[i] = *servedQuestion(question)
+	}
 	logger.Info("Questions retrieved successfully", zap.Int("count", len(questions)))
-	return questions, nil
+	return listed, nil
 }
 
 func (s *QuizService) LoadQuestions(qs []models.Question) {
@@ -52,7 +83,7 @@ func (s *QuizService) LoadQuestions(qs []models.Question) {
 	logger.Info("Questions loaded successfully", zap.Int("count", len(qs)))
 }
 
-func (s *QuizService) StartQuiz(username string) error {
+func (s *QuizService) StartQuiz(username, quizID string) error {
 	logger := utils.GetLogger().Sugar()
 	quizMu.Lock()
 	defer quizMu.Unlock()
@@ -64,10 +95,54 @@ func (s *QuizService) StartQuiz(username string) error {
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	// Reset user progress and score for the new quiz
+	quiz, err := s.getQuiz(quizID)
+	if err != nil {
+		logger.Warn("Quiz not found", zap.String("quizID", quizID), zap.Error(err))
+		return fmt.Errorf("quiz not found: %w", err)
+	}
+
+	// Check the rules of the quiz against the user's previous attempts
+	now := s.now()
+	if err := checkQuizRules(quiz, username, s.DB.ListAttempts(username), now); err != nil {
+		logger.Warn("Quiz start blocked by rule", zap.String("username", username), zap.String("quizID", quiz.QuizID), zap.Error(err))
+		return err
+	}
+
+	// Abandon an unfinished previous attempt, a new start always begins from scratch
+	if previous, err := s.activeAttempt(user); err == nil &&
+		(previous.Status == models.AttemptActive || previous.Status == models.AttemptPaused) {
+		previous.Status = models.AttemptAbandoned
+		if err := s.DB.UpdateAttempt(previous); err != nil {
+			logger.Warn("Failed to abandon previous attempt", zap.String("username", username), zap.Error(err))
+		}
+	}
+
+	// Open a new attempt to record answers, lifelines and scoring for this quiz
+	attempt := database.Attempt{
+		AttemptID: uuid.NewString(),
+		Username:  username,
+		QuizID:    quiz.QuizID,
+		Mode:      quiz.Mode,
+		Ranked:    quiz.Ranked(),
+		Strategy:  quiz.Strategy,
+		Status:    models.AttemptActive,
+		StartedAt: now,
+	}
+	if quiz.TimeLimit() > 0 {
+		attempt.Deadline = now.Add(quiz.TimeLimit())
+	}
+	if err := s.DB.AddAttempt(attempt); err != nil {
+		logger.Error("Failed to create attempt", zap.String("username", username), zap.Error(err))
+		return fmt.Errorf("failed to create attempt: %w", err)
+	}
+
+	// Reset user progress, and the score for quizzes that count on the leaderboards
 	user.Progress = []int{}
-	user.Score = 0
-	user.QuizTaken++
+	if attempt.Ranked {
+		user.Score = 0
+		user.QuizTaken++
+	}
+	user.ActiveAttemptID = attempt.AttemptID
 
 	// Save the updated user data back to the database
 	if err := s.DB.UpdateUser(user); err != nil {
@@ -76,23 +151,7 @@ func (s *QuizService) StartQuiz(username string) error {
 	}
 
 	// Set or reset the quiz session timer for the user
-	if timer, exists := quizTimers[username]; exists {
-		timer.Stop()
-		logger.Warn("Existing quiz session timer stopped", zap.String("username", username))
-	}
-	quizTimers[username] = time.AfterFunc(10*time.Minute, func() {
-		quizMu.Lock()
-		defer quizMu.Unlock()
-
-		// Cleanup expired quiz session
-		logger.Info("Quiz session expired", zap.String("username", username))
-		user.Progress = []int{}
-		user.Score = 0
-		if err := s.DB.UpdateUser(user); err != nil {
-			logger.Error("Failed to reset user progress on quiz expiry", zap.String("username", username), zap.Error(err))
-		}
-		delete(quizTimers, username)
-	})
+	s.startTimer(username, attempt.AttemptID, quiz.TimeLimit())
 
 	logger.Info("Quiz session started successfully", zap.String("username", username))
 	return nil
@@ -110,30 +169,20 @@ func (s *QuizService) GetNextQuestion(username string) (*models.Question, error)
 		return nil, fmt.Errorf("quiz not started: %w", err)
 	}
 
-	// Get user's current progress
-	progress := len(user.Progress)
-
-	// Check if there are remaining questions
-	if progress >= len(questions) {
-		logger.Warn("No more questions available for user", zap.String("username", username))
-		return nil, errors.New("quiz complete")
+	attempt, err := s.activeAttempt(user)
+	if err != nil {
+		logger.Warn("No quiz in progress", zap.String("username", username))
+		return nil, err
 	}
-
-	// Retrieve the next question
-	question := questions[progress]
-	logger.Info("Next question retrieved", zap.String("username", username), zap.Int("progress", progress))
-
-	// Update user's progress
-	user.Progress = append(user.Progress, question.QuestionID)
-	if err := s.DB.UpdateUser(user); err != nil {
-		logger.Error("Failed to update user progress in database", zap.String("username", username), zap.Error(err))
-		return nil, fmt.Errorf("failed to update user progress: %w", err)
+	if err := attemptPlayable(attempt); err != nil {
+		logger.Warn("Attempt cannot be played", zap.String("username", username), zap.Error(err))
+		return nil, err
 	}
 
-	return &question, nil
+	return s.serveNext(&user, &attempt)
 }
 
-func (s *QuizService) SubmitAnswer(username string, questionIndex, answer int) (bool, error) {
+func (s *QuizService) SubmitAnswer(username string, questionIndex, answer int) (*models.AnswerResult, error) {
 	logger := utils.GetLogger().Sugar()
 	quizMu.Lock()
 	defer quizMu.Unlock()
@@ -141,32 +190,124 @@ func (s *QuizService) SubmitAnswer(username string, questionIndex, answer int) (
 	// Validate question index
 	if questionIndex < 0 || questionIndex >= len(questions) {
 		logger.Error("Invalid question index", zap.Int("questionIndex", questionIndex))
-		return false, errors.New("question index is out of range")
+		return nil, errors.New("question index is out of range")
 	}
 
 	// Retrieve the user from the in-memory database
 	user, err := s.DB.GetUser(username)
 	if err != nil {
 		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
-		return false, fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	// Only questions served on the attempt can be answered
+	attempt, err := s.activeAttempt(user)
+	if err != nil {
+		logger.Warn("No quiz in progress", zap.String("username", username))
+		return nil, err
+	}
+	if err := attemptPlayable(attempt); err != nil {
+		logger.Warn("Attempt cannot be played", zap.String("username", username), zap.Error(err))
+		return nil, err
+	}
+	mode, ranked := models.QuizModeStandard, true
+	if attempt.Mode != "" {
+		mode, ranked = attempt.Mode, attempt.Ranked
+	}
+	quiz, err := s.getQuiz(attempt.QuizID)
+	if err != nil {
+		logger.Error("Quiz of attempt not found", zap.String("quizID", attempt.QuizID), zap.Error(err))
+		return nil, fmt.Errorf("quiz not found: %w", err)
+	}
+	if err := checkAnswer(quiz, mode, &attempt, questionIndex); err != nil {
+		logger.Warn("Answer rejected", zap.String("username", username), zap.Int("questionIndex", questionIndex), zap.Error(err))
+		return nil, err
 	}
 
-	// Validate the answer
-	correctAnswer := questions[questionIndex].Answer
-	if answer == correctAnswer {
-		user.Score++
-		logger.Info("Correct answer submitted", zap.String("username", username), zap.Int("score", user.Score))
+	// Validate the answer and run it through the scoring pipeline
+	question := questions[questionIndex]
+	scoring := scoreContext{
+		Correct:   answer == question.Answer,
+		Lifelines: lifelinesFor(attempt, questionIndex),
+	}
+
+	// Exam points stay on the attempt until it completes, so the stats
+	// give no feedback while the exam runs
+	points := scoreAnswer(scoring)
+	if ranked && mode != models.QuizModeExam {
+		user.Score += points
+	}
+	if scoring.Correct {
+		logger.Info("Correct answer submitted", zap.String("username", username), zap.Int("points", points), zap.Int("score", user.Score))
 	} else {
 		logger.Info("Incorrect answer submitted", zap.String("username", username), zap.Int("score", user.Score))
 	}
 
+	// A practice retry replaces the points of the previous try
+	record := findRecord(&attempt, questionIndex)
+	if mode == models.QuizModePractice {
+		attempt.Score -= record.Points
+	}
+	firstTry := record.Tries == 0
+	record.Answer = answer
+	record.Correct = scoring.Correct
+	record.Points = points
+	record.Tries++
+	record.AnsweredAt = s.now()
+
+	// Only the first try tells anything about the player's ability
+	if attempt.Strategy == models.StrategyAdaptive && firstTry {
+		updateAbility(&attempt, question.Difficulty, scoring.Correct)
+	}
+
+	attempt.Score += points
+	if err := s.DB.UpdateAttempt(attempt); err != nil {
+		logger.Error("Failed to update attempt score", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("failed to update attempt: %w", err)
+	}
+
 	// Save the updated user data back to the database
 	if err := s.DB.UpdateUser(user); err != nil {
 		logger.Error("Failed to update user score in database", zap.String("username", username), zap.Error(err))
-		return false, fmt.Errorf("failed to update user score: %w", err)
+		return nil, fmt.Errorf("failed to update user score: %w", err)
 	}
+	s.notifyAnswerSubmitted(models.Attempt(attempt), *record)
+
+	return &models.AnswerResult{
+		Mode:          mode,
+		Correct:       scoring.Correct,
+		CorrectAnswer: question.Answer,
+		Explanation:   question.Explanation,
+		Points:        points,
+		CanRetry:      mode == models.QuizModePractice && !scoring.Correct,
+	}, nil
+}
 
-	return answer == correctAnswer, nil
+// checkAnswer enforces that answers go to questions of the quiz that were
+// served on the attempt and are still waiting for an answer. Practice attempts
+// may retry an answered question, and exam answers go to the question on
+// screen only.
+func checkAnswer(quiz models.Quiz, mode models.QuizMode, attempt *database.Attempt, questionIndex int) error {
+	if len(quiz.QuestionIDs) > 0 && !slices.Contains(quiz.QuestionIDs, questions[questionIndex].QuestionID) {
+		return ErrQuestionNotInQuiz
+	}
+	record := findRecord(attempt, questionIndex)
+	if record == nil {
+		return ErrQuestionNotServed
+	}
+	switch {
+	case mode == models.QuizModeExam:
+		if !record.Pending() || record != &attempt.Answers[len(attempt.Answers)-1] {
+			return ErrAnswerLocked
+		}
+	case mode == models.QuizModePractice:
+		if record.Skipped {
+			return ErrAlreadyAnswered
+		}
+	case !record.Pending():
+		return ErrAlreadyAnswered
+	}
+	return nil
 }
 
 func (s *QuizService) GetResults(username string) (int, error) {
@@ -181,11 +322,34 @@ func (s *QuizService) GetResults(username string) (int, error) {
 		return 0, fmt.Errorf("user not found: %w", err)
 	}
 
+	// Practice attempts keep their score on the attempt only
+	if attempt, err := s.activeAttempt(user); err == nil && !attempt.Ranked && attempt.Mode != "" {
+		logger.Info("Practice score retrieved", zap.String("username", username), zap.Int("score", attempt.Score))
+		return attempt.Score, nil
+	}
+
 	// Return the user's score
 	logger.Info("Final score retrieved", zap.String("username", username), zap.Int("score", user.Score))
 	return user.Score, nil
 }
 
+// GetLatestAttempt returns the attempt the user played last.
+func (s *QuizService) GetLatestAttempt(username string) (*models.Attempt, error) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	attempt, err := s.activeAttempt(user)
+	if err != nil {
+		return nil, err
+	}
+	latest := models.Attempt(attempt)
+	return &latest, nil
+}
+
 func (s *QuizService) GetStats(username string) ([]models.User, string, error) {
 	logger := utils.GetLogger().Sugar()
 	logger.Info("Fetching stats for user", zap.String("username", username))
@@ -201,31 +365,37 @@ func (s *QuizService) GetStats(username string) ([]models.User, string, error) {
 		return nil, "", fmt.Errorf("error fetching user stats: %w", err)
 	}
 
-	// Retrieve all users from the database
-	allUsers := s.DB.GetAllUsers()
-	if len(allUsers) == 0 {
-		logger.Warn("No users found in database")
+	if user.QuizTaken == 0 {
+		logger.Warn("User has not played yet", zap.String("username", username))
 		return nil, "", ErrNoStatsForUser
 	}
 
-	// Collect all scores
-	allScores := make([]int, len(allUsers))
-	for i, u := range allUsers {
-		allScores[i] = u.Score
-	}
-
-	// Sort scores for ranking
-	sort.Ints(allScores)
-
-	// Calculate the percentage of users with lower scores
-	betterScores := 0
-	for _, score := range allScores {
-		if user.Score > score {
-			betterScores++
+	// Only players count, and the caller is not compared with themselves
+	var players []models.User
+	var otherScores []int
+	for _, u := range s.DB.GetAllUsers() {
+		if u.QuizTaken == 0 {
+			continue
+		}
+		players = append(players, models.User{Username: u.Username, Score: u.Score})
+		if u.Username != username {
+			otherScores = append(otherScores, u.Score)
 		}
 	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Score != players[j].Score {
+			return players[i].Score > players[j].Score
+		}
+		return players[i].Username < players[j].Username
+	})
 
-	percentage := (float64(betterScores) / float64(len(allScores))) * 100
+	// In the sorted scores the position of the caller's score is the number of lower scores
+	percentage := 100.0
+	if len(otherScores) > 0 {
+		sort.Ints(otherScores)
+		lowerScores := sort.SearchInts(otherScores, user.Score)
+		percentage = float64(lowerScores) / float64(len(otherScores)) * 100
+	}
 	message := fmt.Sprintf(
 		"Your score is %d and that is %.2f%% better than other users' scores.",
 		user.Score, percentage,
@@ -237,16 +407,7 @@ func (s *QuizService) GetStats(username string) ([]models.User, string, error) {
 		zap.Float64("better_than_percentage", percentage),
 	)
 
-	// Map all users for response
-	modelUsers := make([]models.User, len(allUsers))
-	for i, u := range allUsers {
-		modelUsers[i] = models.User{
-			Username: u.Username,
-			Score:    u.Score,
-		}
-	}
-
-	return modelUsers, message, nil
+	return players, message, nil
 }
 
 var _ IQuizService = &QuizService{}
@@ -22,6 +22,12 @@ func TestQuizServiceGetQuestions(t *testing.T) {
 	result, err := s.GetQuestions()
 	assert.NoError(t, err, "expected no error when getting questions")
 	assert.Equal(t, questions, result, "expected questions to match loaded questions")
+
+	s.LoadQuestions([]models.Question{{QuestionID: 1, Question: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 1, Hint: "Even", Explanation: "Two plus two is four"}})
+	result, err = s.GetQuestions()
+	assert.NoError(t, err)
+	assert.Empty(t, result[0].Hint, "expected the hint to stay behind the lifeline")
+	assert.Empty(t, result[0].Explanation, "expected the explanation to stay hidden")
 }
 
 func TestQuizServiceStartQuiz(t *testing.T) {
@@ -31,7 +37,7 @@ func TestQuizServiceStartQuiz(t *testing.T) {
 	// Add a user to the database
 	db.AddUser(database.User{Username: "testuser"})
 
-	err := s.StartQuiz("testuser")
+	err := s.StartQuiz("testuser", "")
 	assert.NoError(t, err, "expected no error when starting a quiz")
 
 	user, err := db.GetUser("testuser")
@@ -51,7 +57,7 @@ func TestQuizServiceGetNextQuestion(t *testing.T) {
 
 	db.AddUser(database.User{Username: "testuser"})
 
-	err := s.StartQuiz("testuser")
+	err := s.StartQuiz("testuser", "")
 	assert.NoError(t, err, "expected no error when starting a quiz")
 
 	question, err := s.GetNextQuestion("testuser")
@@ -69,27 +75,44 @@ func TestQuizServiceSubmitAnswer(t *testing.T) {
 
 	questions := []models.Question{
 		{QuestionID: 1, Question: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 1},
+		{QuestionID: 2, Question: "What is the capital of France?", Options: []string{"Paris", "Berlin", "Madrid"}, Answer: 0},
 	}
 	s.LoadQuestions(questions)
 
 	db.AddUser(database.User{Username: "testuser"})
 
-	err := s.StartQuiz("testuser")
+	_, err := s.SubmitAnswer("testuser", 0, 1)
+	assert.ErrorIs(t, err, ErrNoActiveAttempt, "expected answers to need a started quiz")
+
+	err = s.StartQuiz("testuser", "")
 	assert.NoError(t, err, "expected no error when starting a quiz")
 
+	_, err = s.SubmitAnswer("testuser", 0, 1)
+	assert.ErrorIs(t, err, ErrQuestionNotServed, "expected answers to need a served question")
+
 	// Test valid answer
-	correct, err := s.SubmitAnswer("testuser", 0, 1)
+	_, err = s.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+	result, err := s.SubmitAnswer("testuser", 0, 1)
 	assert.NoError(t, err, "expected no error when submitting a valid answer")
-	assert.True(t, correct, "expected answer to be marked as correct")
+	assert.True(t, result.Correct, "expected answer to be marked as correct")
+
+	// The same answer again does not score again
+	_, err = s.SubmitAnswer("testuser", 0, 1)
+	assert.ErrorIs(t, err, ErrAlreadyAnswered)
+	_, err = s.SubmitAnswer("testuser", 1, 0)
+	assert.ErrorIs(t, err, ErrQuestionNotServed)
 
 	user, err := db.GetUser("testuser")
 	assert.NoError(t, err, "expected no error when retrieving user")
-	assert.Equal(t, 1, user.Score, "expected score to be updated after correct answer")
+	assert.Equal(t, CorrectAnswerPoints, user.Score, "expected score to be updated once after correct answer")
 
 	// Test invalid answer
-	correct, err = s.SubmitAnswer("testuser", 0, 0)
+	_, err = s.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+	result, err = s.SubmitAnswer("testuser", 1, 1)
 	assert.NoError(t, err, "expected no error when submitting an incorrect answer")
-	assert.False(t, correct, "expected answer to be marked as incorrect")
+	assert.False(t, result.Correct, "expected answer to be marked as incorrect")
 
 	// Test invalid question index
 	_, err = s.SubmitAnswer("testuser", 10, 0)
@@ -97,13 +120,33 @@ func TestQuizServiceSubmitAnswer(t *testing.T) {
 	assert.Equal(t, "question index is out of range", err.Error(), "unexpected error message")
 }
 
+func TestQuizServiceSubmitAnswerOutsideQuiz(t *testing.T) {
+	db := database.NewMemoryDB()
+	s := NewQuizService(db)
+	s.LoadQuestions([]models.Question{
+		{QuestionID: 1, Question: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 1},
+		{QuestionID: 2, Question: "What is the capital of France?", Options: []string{"Paris", "Berlin", "Madrid"}, Answer: 0},
+	})
+	db.AddUser(database.User{Username: "testuser"})
+	db.AddQuiz(database.Quiz{QuizID: "second", QuestionIDs: []int{2}})
+
+	assert.NoError(t, s.StartQuiz("testuser", "second"))
+	_, err := s.GetNextQuestion("testuser")
+	assert.NoError(t, err)
+	_, err = s.SubmitAnswer("testuser", 0, 1)
+	assert.ErrorIs(t, err, ErrQuestionNotInQuiz)
+	result, err := s.SubmitAnswer("testuser", 1, 0)
+	assert.NoError(t, err)
+	assert.True(t, result.Correct)
+}
+
 func TestQuizServiceGetResults(t *testing.T) {
 	db := database.NewMemoryDB()
 	s := NewQuizService(db)
 
 	db.AddUser(database.User{Username: "testuser"})
 
-	err := s.StartQuiz("testuser")
+	err := s.StartQuiz("testuser", "")
 	assert.NoError(t, err, "expected no error when starting a quiz")
 
 	user, err := db.GetUser("testuser")
@@ -140,10 +183,14 @@ func TestQuizServiceConcurrency(t *testing.T) {
 			username := "user" + string(rune(i))
 			db.AddUser(database.User{Username: username})
 
-			if err := s.StartQuiz(username); err != nil {
+			if err := s.StartQuiz(username, ""); err != nil {
 				t.Errorf("Failed to start quiz for user '%s': %v", username, err)
 			}
 
+			if _, err := s.GetNextQuestion(username); err != nil {
+				t.Errorf("Failed to get question for user '%s': %v", username, err)
+			}
+
 			if _, err := s.SubmitAnswer(username, 0, 1); err != nil {
 				t.Errorf("Failed to submit answer for user '%s': %v", username, err)
 			}
@@ -156,6 +203,6 @@ func TestQuizServiceConcurrency(t *testing.T) {
 		username := "user" + string(rune(i))
 		score, err := s.GetResults(username)
 		assert.NoError(t, err, "expected no error for concurrent user")
-		assert.Equal(t, 1, score, "expected correct score for concurrent user")
+		assert.Equal(t, CorrectAnswerPoints, score, "expected correct score for concurrent user")
 	}
 }
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultRoomSeconds   = 20
+	DefaultRoomQuestions = 10
+	DefaultRoomIdleTTL   = time.Hour
+	roomCodeLength       = 6
+	// roomEventBuffer is how many events a slow connection may fall behind
+	// before events are dropped for it
+	roomEventBuffer = 16
+)
+
+// roomCodeAlphabet leaves out letters and digits that are easily mixed up.
+const roomCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+var (
+	ErrRoomNotFound     = errors.New("room not found")
+	ErrNotRoomHost      = errors.New("only the host can do this")
+	ErrRoomFinished     = errors.New("room is finished")
+	ErrNoQuestionOpen   = errors.New("no question is open")
+	ErrAlreadyAnswered  = errors.New("question already answered")
+	ErrHostCannotAnswer = errors.New("the host does not answer")
+	ErrInvalidAnswer    = errors.New("answer is not one of the options")
+)
+
+// RoomSubscription delivers the events of a room to one connection. Close
+// must be called when the connection ends.
+type RoomSubscription struct {
+	Events <-chan models.RoomEvent
+	Close  func()
+}
+
+type roomPlayer struct {
+	score       int
+	answers     map[int]int
+	connections int
+}
+
+type room struct {
+	code      string
+	host      string
+	state     models.RoomState
+	questions []models.Question
+	current   int
+	seconds   int
+	deadline  time.Time
+	timer     *time.Timer
+	players   map[string]*roomPlayer
+	// subscribers are the open connections of the room by username
+	subscribers map[string]map[chan models.RoomEvent]struct{}
+	// idle expires the room when nothing happens in it for the idle TTL;
+	// activity counts the events so that a timer armed earlier does nothing
+	idle     *time.Timer
+	activity int
+}
+
+// RoomService runs live quiz rooms. The host serves the questions one by one,
+// every player answers under the same countdown and the scoreboard is pushed
+// to everyone when a question closes. Players are known by username, so a
+// player who reconnects keeps their score and gets the current state.
+type RoomService struct {
+	DB    *database.MemoryDB
+	Quiz  *QuizService
+	Clock func() time.Time
+	// IdleTTL is how long a room is kept without anything happening in it
+	IdleTTL time.Duration
+
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+func NewRoomService(quizService *QuizService) *RoomService {
+	return &RoomService{DB: quizService.DB, Quiz: quizService, IdleTTL: DefaultRoomIdleTTL, rooms: make(map[string]*room)}
+}
+
+func (s *RoomService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// CreateRoom opens a room in the lobby with the questions of a quiz.
+func (s *RoomService) CreateRoom(host string, settings models.RoomSettings) (*models.Room, error) {
+	logger := utils.GetLogger().Sugar()
+	if _, err := s.DB.GetUser(host); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	quiz, err := s.Quiz.getQuiz(settings.QuizID)
+	if err != nil {
+		return nil, fmt.Errorf("quiz not found: %w", err)
+	}
+
+	roomQuestions := s.roomQuestions(quiz, settings.Questions)
+	if len(roomQuestions) == 0 {
+		return nil, errors.New("no questions available")
+	}
+	seconds := settings.SecondsPerQuestion
+	if seconds <= 0 {
+		seconds = DefaultRoomSeconds
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := &room{
+		code:        s.newCode(),
+		host:        host,
+		state:       models.RoomLobby,
+		questions:   roomQuestions,
+		current:     -1,
+		seconds:     seconds,
+		players:     make(map[string]*roomPlayer),
+		subscribers: make(map[string]map[chan models.RoomEvent]struct{}),
+	}
+	s.rooms[r.code] = r
+	s.touch(r)
+
+	logger.Info("Room created", zap.String("code", r.code), zap.String("host", host), zap.Int("questions", len(roomQuestions)))
+	return r.snapshot(), nil
+}
+
+// roomQuestions copies the questions of a quiz, the whole bank when the quiz
+// does not list its own.
+func (s *RoomService) roomQuestions(quiz models.Quiz, limit int) []models.Question {
+	if limit <= 0 {
+		limit = DefaultRoomQuestions
+	}
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	var picked []models.Question
+	if len(quiz.QuestionIDs) > 0 {
+		for _, questionID := range quiz.QuestionIDs {
+			if i := questionIndex(questionID); i >= 0 {
+				picked = append(picked, questions[i])
+			}
+		}
+	} else {
+		picked = append(picked, questions...)
+	}
+	if len(picked) > limit {
+		picked = picked[:limit]
+	}
+	return picked
+}
+
+// newCode returns a join code no open room uses. Callers must hold s.mu.
+func (s *RoomService) newCode() string {
+	for {
+		code := make([]byte, roomCodeLength)
+		for i := range code {
+			code[i] = roomCodeAlphabet[rand.IntN(len(roomCodeAlphabet))]
+		}
+		if _, exists := s.rooms[string(code)]; !exists {
+			return string(code)
+		}
+	}
+}
+
+func (s *RoomService) GetRoom(code string) (*models.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, exists := s.rooms[code]
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+	return r.snapshot(), nil
+}
+
+// Connect joins the user to the room, unless they are the host or already
+// in it, and subscribes the connection to its events. The current state is
+// the first event.
+func (s *RoomService) Connect(code, username string) (*RoomSubscription, error) {
+	logger := utils.GetLogger().Sugar()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, exists := s.rooms[code]
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+	player, joined := r.players[username]
+	if !joined && username != r.host {
+		if r.state == models.RoomFinished {
+			return nil, ErrRoomFinished
+		}
+		player = &roomPlayer{answers: make(map[int]int)}
+		r.players[username] = player
+		logger.Info("Player joined room", zap.String("code", code), zap.String("username", username))
+	}
+	if player != nil {
+		player.connections++
+	}
+
+	events := make(chan models.RoomEvent, roomEventBuffer)
+	if r.subscribers[username] == nil {
+		r.subscribers[username] = make(map[chan models.RoomEvent]struct{})
+	}
+	r.subscribers[username][events] = struct{}{}
+	s.touch(r)
+	r.broadcast(models.RoomEventState)
+
+	var once sync.Once
+	return &RoomSubscription{
+		Events: events,
+		Close:  func() { once.Do(func() { s.disconnect(r, username, events) }) },
+	}, nil
+}
+
+func (s *RoomService) disconnect(r *room, username string, events chan models.RoomEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// An expired room has closed the connection already
+	if _, open := r.subscribers[username][events]; !open {
+		return
+	}
+	delete(r.subscribers[username], events)
+	close(events)
+	if len(r.subscribers[username]) == 0 {
+		delete(r.subscribers, username)
+	}
+	if player, ok := r.players[username]; ok {
+		player.connections--
+	}
+
+	// A finished room is dropped once everyone has left
+	if r.state == models.RoomFinished && len(r.subscribers) == 0 {
+		s.remove(r)
+		return
+	}
+	s.touch(r)
+	r.broadcast(models.RoomEventState)
+}
+
+// Next serves the next question of the room, or finishes it after the last
+// one. An open question is closed early instead.
+func (s *RoomService) Next(code, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.hostRoom(code, username)
+	if err != nil {
+		return err
+	}
+	s.touch(r)
+	if r.state == models.RoomQuestion {
+		s.closeQuestion(r)
+		return nil
+	}
+	if r.current+1 >= len(r.questions) {
+		s.finish(r)
+		return nil
+	}
+
+	r.current++
+	r.state = models.RoomQuestion
+	r.deadline = s.now().Add(time.Duration(r.seconds) * time.Second)
+	current := r.current
+	r.timer = time.AfterFunc(time.Duration(r.seconds)*time.Second, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.state == models.RoomQuestion && r.current == current {
+			s.closeQuestion(r)
+		}
+	})
+	utils.GetLogger().Sugar().Info("Room question served", zap.String("code", code), zap.Int("question", current+1))
+	r.broadcast(models.RoomEventQuestion)
+	return nil
+}
+
+// Answer records the answer of a player to the open question. The question
+// closes early once every connected player has answered.
+func (s *RoomService) Answer(code, username string, answer int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, exists := s.rooms[code]
+	if !exists {
+		return ErrRoomNotFound
+	}
+	if username == r.host {
+		return ErrHostCannotAnswer
+	}
+	player, joined := r.players[username]
+	if !joined {
+		return ErrRoomNotFound
+	}
+	if r.state != models.RoomQuestion || !s.now().Before(r.deadline) {
+		return ErrNoQuestionOpen
+	}
+	if _, answered := player.answers[r.current]; answered {
+		return ErrAlreadyAnswered
+	}
+	if answer < 1 || answer > len(r.questions[r.current].Options) {
+		return ErrInvalidAnswer
+	}
+	player.answers[r.current] = answer
+	s.touch(r)
+
+	for _, other := range r.players {
+		if _, answered := other.answers[r.current]; other.connections > 0 && !answered {
+			r.broadcast(models.RoomEventAnswered)
+			return nil
+		}
+	}
+	s.closeQuestion(r)
+	return nil
+}
+
+// End finishes the room before its last question.
+func (s *RoomService) End(code, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.hostRoom(code, username)
+	if err != nil {
+		return err
+	}
+	s.finish(r)
+	return nil
+}
+
+// hostRoom returns a room that is still running when the user hosts it.
+// Callers must hold s.mu.
+func (s *RoomService) hostRoom(code, username string) (*room, error) {
+	r, exists := s.rooms[code]
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+	if r.host != username {
+		return nil, ErrNotRoomHost
+	}
+	if r.state == models.RoomFinished {
+		return nil, ErrRoomFinished
+	}
+	return r, nil
+}
+
+// closeQuestion scores the open question and pushes the scoreboard.
+// Callers must hold s.mu.
+func (s *RoomService) closeQuestion(r *room) {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	question := r.questions[r.current]
+	for _, player := range r.players {
+		if answer, answered := player.answers[r.current]; answered {
+			player.score += scoreAnswer(scoreContext{Correct: answer == question.Answer})
+		}
+	}
+	r.state = models.RoomReveal
+	r.broadcast(models.RoomEventScoreboard)
+}
+
+// finish ends the room, and drops it when nobody is connected to see the
+// result. Callers must hold s.mu.
+func (s *RoomService) finish(r *room) {
+	if r.state == models.RoomQuestion {
+		s.closeQuestion(r)
+	}
+	r.state = models.RoomFinished
+	utils.GetLogger().Sugar().Info("Room finished", zap.String("code", r.code))
+	r.broadcast(models.RoomEventFinished)
+	if len(r.subscribers) == 0 {
+		s.remove(r)
+	}
+}
+
+// touch records activity in the room and rearms its idle timer. Callers must
+// hold s.mu.
+func (s *RoomService) touch(r *room) {
+	if s.IdleTTL <= 0 {
+		return
+	}
+	if r.idle != nil {
+		r.idle.Stop()
+	}
+	r.activity++
+	activity := r.activity
+	r.idle = time.AfterFunc(s.IdleTTL, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.activity == activity && s.rooms[r.code] == r {
+			s.expire(r)
+		}
+	})
+}
+
+// expire finishes an idle room, closes the connections still open and drops
+// it. Callers must hold s.mu.
+func (s *RoomService) expire(r *room) {
+	utils.GetLogger().Sugar().Info("Room expired", zap.String("code", r.code))
+	if r.state != models.RoomFinished {
+		s.finish(r)
+	}
+	for username, connections := range r.subscribers {
+		for events := range connections {
+			close(events)
+		}
+		delete(r.subscribers, username)
+	}
+	for _, player := range r.players {
+		player.connections = 0
+	}
+	s.remove(r)
+}
+
+// remove drops the room and stops its timers. The code may already belong to
+// a newer room. Callers must hold s.mu.
+func (s *RoomService) remove(r *room) {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if r.idle != nil {
+		r.idle.Stop()
+	}
+	if s.rooms[r.code] == r {
+		delete(s.rooms, r.code)
+	}
+}
+
+// broadcast pushes the room to every connection. A connection whose buffer is
+// full misses the event, the next one brings it up to date.
+func (r *room) broadcast(eventType models.RoomEventType) {
+	event := models.RoomEvent{Type: eventType, Room: r.snapshot()}
+	for _, connections := range r.subscribers {
+		for events := range connections {
+			select {
+			case events <- event:
+			default:
+			}
+		}
+	}
+}
+
+// snapshot is the room as participants see it: the answer of the current
+// question is only there once the question is closed.
+func (r *room) snapshot() *models.Room {
+	snapshot := &models.Room{
+		Code:           r.code,
+		Host:           r.host,
+		State:          r.state,
+		QuestionNumber: r.current + 1,
+		QuestionCount:  len(r.questions),
+		Scoreboard:     []models.RoomScore{},
+	}
+	if r.current >= 0 {
+		question := r.questions[r.current]
+		switch r.state {
+		case models.RoomQuestion:
+			deadline := r.deadline
+			snapshot.Question = servedQuestion(question)
+			snapshot.Deadline = &deadline
+		case models.RoomReveal, models.RoomFinished:
+			snapshot.Question = &question
+			snapshot.CorrectAnswer = question.Answer
+		}
+	}
+
+	for username, player := range r.players {
+		_, answered := player.answers[r.current]
+		snapshot.Scoreboard = append(snapshot.Scoreboard, models.RoomScore{
+			Username:  username,
+			Score:     player.score,
+			Answered:  answered,
+			Connected: player.connections > 0,
+		})
+	}
+	sort.Slice(snapshot.Scoreboard, func(i, j int) bool {
+		a, b := snapshot.Scoreboard[i], snapshot.Scoreboard[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Username < b.Username
+	})
+	for i := range snapshot.Scoreboard {
+		snapshot.Scoreboard[i].Rank = i + 1
+		if i > 0 && snapshot.Scoreboard[i].Score == snapshot.Scoreboard[i-1].Score {
+			snapshot.Scoreboard[i].Rank = snapshot.Scoreboard[i-1].Rank
+		}
+	}
+	return snapshot
+}
+
+var _ IRoomService = &RoomService{}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// lastEvent drains the events delivered so far and returns the latest one.
+func lastEvent(t *testing.T, subscription *RoomSubscription) models.RoomEvent {
+	t.Helper()
+	var event models.RoomEvent
+	for {
+		select {
+		case event = <-subscription.Events:
+		default:
+			return event
+		}
+	}
+}
+
+func TestRoomPlaysInSync(t *testing.T) {
+	s := NewRoomService(newTestQuizService(t, testQuestions, "host", "alice", "bob"))
+	room, err := s.CreateRoom("host", models.RoomSettings{SecondsPerQuestion: 30})
+	assert.NoError(t, err)
+	code := room.Code
+	assert.Len(t, code, roomCodeLength)
+	assert.Equal(t, len(testQuestions), room.QuestionCount)
+	host, err := s.Connect(code, "host")
+	assert.NoError(t, err)
+	alice, _ := s.Connect(code, "alice")
+	bob, _ := s.Connect(code, "bob")
+	defer host.Close()
+	defer alice.Close()
+
+	assert.ErrorIs(t, s.Next(code, "alice"), ErrNotRoomHost)
+	assert.ErrorIs(t, s.Answer(code, "alice", 2), ErrNoQuestionOpen)
+
+	assert.NoError(t, s.Next(code, "host"))
+	event := lastEvent(t, alice)
+	assert.Equal(t, models.RoomEventQuestion, event.Type)
+	assert.Empty(t, event.Room.Question.Hint, "expected the question to be served without hint")
+	assert.Zero(t, event.Room.CorrectAnswer)
+
+	assert.ErrorIs(t, s.Answer(code, "host", 2), ErrHostCannotAnswer)
+	assert.NoError(t, s.Answer(code, "alice", 2))
+	assert.ErrorIs(t, s.Answer(code, "alice", 1), ErrAlreadyAnswered)
+	assert.Equal(t, models.RoomEventAnswered, lastEvent(t, host).Type)
+
+	// bob drops out and reconnects while the question is open
+	bob.Close()
+	bob, _ = s.Connect(code, "bob")
+	defer bob.Close()
+	reconnected := lastEvent(t, bob)
+	assert.Equal(t, models.RoomQuestion, reconnected.Room.State)
+	assert.NotNil(t, reconnected.Room.Deadline)
+
+	assert.NoError(t, s.Answer(code, "bob", 3))
+	event = lastEvent(t, host)
+	assert.Equal(t, models.RoomEventScoreboard, event.Type, "expected the question to close once everyone answered")
+	assert.Equal(t, 2, event.Room.CorrectAnswer)
+	assert.Equal(t, []models.RoomScore{
+		{Rank: 1, Username: "alice", Score: CorrectAnswerPoints, Answered: true, Connected: true},
+		{Rank: 2, Username: "bob", Score: 0, Answered: true, Connected: true},
+	}, event.Room.Scoreboard)
+
+	for range len(testQuestions) - 1 {
+		assert.NoError(t, s.Next(code, "host"))
+		assert.NoError(t, s.Next(code, "host"), "expected next to close the open question early")
+	}
+	assert.NoError(t, s.Next(code, "host"))
+	assert.Equal(t, models.RoomEventFinished, lastEvent(t, alice).Type)
+	assert.ErrorIs(t, s.Next(code, "host"), ErrRoomFinished)
+
+	_, err = s.Connect(code, "newcomer")
+	assert.ErrorIs(t, err, ErrRoomFinished)
+}
+
+func TestRoomCountdownClosesQuestion(t *testing.T) {
+	s := NewRoomService(newTestQuizService(t, testQuestions, "host", "alice"))
+	room, err := s.CreateRoom("host", models.RoomSettings{SecondsPerQuestion: 30})
+	assert.NoError(t, err)
+	code := room.Code
+	now := time.Now()
+	s.Clock = func() time.Time { return now }
+	alice, _ := s.Connect(code, "alice")
+	defer alice.Close()
+
+	s.mu.Lock()
+	s.rooms[code].seconds = 0
+	s.mu.Unlock()
+	assert.NoError(t, s.Next(code, "host"))
+
+	assert.Eventually(t, func() bool {
+		room, err := s.GetRoom(code)
+		return err == nil && room.State == models.RoomReveal
+	}, time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, s.Answer(code, "alice", 2), ErrNoQuestionOpen)
+}
+
+func TestCreateRoomUnknownQuiz(t *testing.T) {
+	s := NewRoomService(newTestQuizService(t, testQuestions, "host"))
+	_, err := s.CreateRoom("host", models.RoomSettings{QuizID: "missing"})
+	assert.ErrorIs(t, err, database.ErrQuizNotFound)
+	_, err = s.GetRoom("NOPE")
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+}
+
+func TestRoomCleanup(t *testing.T) {
+	s := NewRoomService(newTestQuizService(t, testQuestions, "host", "alice"))
+	room, err := s.CreateRoom("host", models.RoomSettings{SecondsPerQuestion: 30})
+	assert.NoError(t, err)
+
+	// A room ended with nobody connected is dropped right away
+	assert.NoError(t, s.End(room.Code, "host"))
+	_, err = s.GetRoom(room.Code)
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+
+	// An idle room expires and closes the connections left
+	s.IdleTTL = 20 * time.Millisecond
+	room, err = s.CreateRoom("host", models.RoomSettings{})
+	assert.NoError(t, err)
+	alice, err := s.Connect(room.Code, "alice")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, err := s.GetRoom(room.Code)
+		return errors.Is(err, ErrRoomNotFound)
+	}, time.Second, 5*time.Millisecond)
+
+	var last models.RoomEvent
+	for event := range alice.Events {
+		last = event
+	}
+	assert.Equal(t, models.RoomEventFinished, last.Type)
+	alice.Close()
+}
@@ -0,0 +1,53 @@
+package services
+
+import "github.com/Dzsodie/quiz_app/internal/models"
+
+// CorrectAnswerPoints is what a correct answer scores before penalties. It
+// leaves room for lifeline penalties that cost part of an answer.
+const CorrectAnswerPoints = 10
+
+// scoreContext carries everything a scoring step may need to know about an answer.
+type scoreContext struct {
+	Correct   bool
+	Lifelines []models.LifelineUse
+}
+
+// scoreStep transforms the points awarded for an answer.
+type scoreStep func(points int, ctx scoreContext) int
+
+// scoringPipeline is applied in order to every submitted answer.
+var scoringPipeline = []scoreStep{
+	basePoints,
+	lifelinePenalties,
+	clampPoints,
+}
+
+func scoreAnswer(ctx scoreContext) int {
+	points := 0
+	for _, step := range scoringPipeline {
+		points = step(points, ctx)
+	}
+	return points
+}
+
+func basePoints(points int, ctx scoreContext) int {
+	if ctx.Correct {
+		return points + CorrectAnswerPoints
+	}
+	return points
+}
+
+func lifelinePenalties(points int, ctx scoreContext) int {
+	for _, use := range ctx.Lifelines {
+		points -= use.Penalty
+	}
+	return points
+}
+
+// clampPoints makes sure a single answer never takes points away from the attempt.
+func clampPoints(points int, _ scoreContext) int {
+	if points < 0 {
+		return 0
+	}
+	return points
+}
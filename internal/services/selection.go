@@ -0,0 +1,136 @@
+package services
+
+import (
+	"math"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+)
+
+const (
+	// defaultAdaptiveMaxQuestions caps adaptive attempts that do not set MaxQuestions.
+	defaultAdaptiveMaxQuestions = 10
+	// defaultTargetPrecision is the standard error of the ability estimate at
+	// which an adaptive attempt is considered converged.
+	defaultTargetPrecision = 0.8
+	// minAdaptiveQuestions keeps a lucky first answer from ending the attempt.
+	minAdaptiveQuestions = 3
+)
+
+// questionStrategy decides which question an attempt is served next.
+type questionStrategy interface {
+	// next returns the index of the next question, or -1 when the attempt is over.
+	next(quiz models.Quiz, attempt database.Attempt) int
+}
+
+func strategyFor(quiz models.Quiz) questionStrategy {
+	if quiz.Strategy == models.StrategyAdaptive {
+		return adaptiveStrategy{}
+	}
+	return sequentialStrategy{}
+}
+
+type sequentialStrategy struct{}
+
+func (sequentialStrategy) next(quiz models.Quiz, attempt database.Attempt) int {
+	if len(quiz.QuestionIDs) > 0 {
+		for _, questionID := range quiz.QuestionIDs {
+			if i := questionIndex(questionID); i >= 0 && findRecord(&attempt, i) == nil {
+				return i
+			}
+		}
+		return -1
+	}
+	for i := range questions {
+		if findRecord(&attempt, i) == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// questionIndex returns the index of a question in the bank, or -1 when it is not there.
+func questionIndex(questionID int) int {
+	for i, question := range questions {
+		if question.QuestionID == questionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// adaptiveStrategy serves the unseen question whose difficulty is closest to
+// the current ability estimate, which is where a 1PL item is most informative.
+// The attempt ends once the estimate converges or the question cap is reached.
+type adaptiveStrategy struct{}
+
+func (adaptiveStrategy) next(quiz models.Quiz, attempt database.Attempt) int {
+	maxQuestions := quiz.MaxQuestions
+	if maxQuestions <= 0 {
+		maxQuestions = defaultAdaptiveMaxQuestions
+	}
+	target := quiz.TargetPrecision
+	if target <= 0 {
+		target = defaultTargetPrecision
+	}
+
+	if len(attempt.Answers) >= maxQuestions {
+		return -1
+	}
+	if answeredCount(attempt) >= minAdaptiveQuestions && attempt.AbilityError > 0 && attempt.AbilityError <= target {
+		return -1
+	}
+
+	best, bestDistance := -1, math.Inf(1)
+	for i, question := range questions {
+		if findRecord(&attempt, i) != nil {
+			continue
+		}
+		if distance := math.Abs(question.Difficulty - attempt.Ability); distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+// successProbability is the 1PL (Rasch) chance of answering an item of the
+// given difficulty correctly at the given ability.
+func successProbability(ability, difficulty float64) float64 {
+	return 1 / (1 + math.Exp(difficulty-ability))
+}
+
+// updateAbility moves the ability estimate of an adaptive attempt after an
+// answer with an Elo style step that shrinks as more answers come in, and
+// refreshes the standard error of the estimate from the test information.
+func updateAbility(attempt *database.Attempt, difficulty float64, correct bool) {
+	outcome := 0.0
+	if correct {
+		outcome = 1
+	}
+
+	answered := answeredCount(*attempt)
+	k := 1 / (1 + 0.5*float64(answered))
+	attempt.Ability += k * (outcome - successProbability(attempt.Ability, difficulty))
+
+	information := 0.0
+	for _, record := range attempt.Answers {
+		if record.Pending() || record.Skipped {
+			continue
+		}
+		p := successProbability(attempt.Ability, questions[record.QuestionIndex].Difficulty)
+		information += p * (1 - p)
+	}
+	if information > 0 {
+		attempt.AbilityError = 1 / math.Sqrt(information)
+	}
+}
+
+func answeredCount(attempt database.Attempt) int {
+	count := 0
+	for _, record := range attempt.Answers {
+		if !record.AnsweredAt.IsZero() {
+			count++
+		}
+	}
+	return count
+}
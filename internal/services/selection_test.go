@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// questionsByDifficulty is a bank with a question for each difficulty from
+// -2 to 2.
+var questionsByDifficulty = []models.Question{
+	{QuestionID: 1, Question: "Easy", Options: []string{"a", "b", "c"}, Answer: 1, Difficulty: -2},
+	{QuestionID: 2, Question: "Medium", Options: []string{"a", "b", "c"}, Answer: 1, Difficulty: 0},
+	{QuestionID: 3, Question: "Hard", Options: []string{"a", "b", "c"}, Answer: 1, Difficulty: 2},
+	{QuestionID: 4, Question: "Easier", Options: []string{"a", "b", "c"}, Answer: 1, Difficulty: -1},
+	{QuestionID: 5, Question: "Harder", Options: []string{"a", "b", "c"}, Answer: 1, Difficulty: 1},
+}
+
+func TestAdaptiveStrategyFollowsAnswers(t *testing.T) {
+	t.Run("harder after a correct answer", func(t *testing.T) {
+		s := newTestQuizService(t, questionsByDifficulty, "testuser")
+		assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "adaptive", Strategy: models.StrategyAdaptive}))
+		assert.NoError(t, s.StartQuiz("testuser", "adaptive"))
+
+		question, err := s.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+		assert.Equal(t, "Medium", question.Question, "expected the first question to match the starting ability")
+
+		_, err = s.SubmitAnswer("testuser", 1, 1)
+		assert.NoError(t, err)
+		question, err = s.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+		assert.Equal(t, "Harder", question.Question)
+	})
+
+	t.Run("easier after a wrong answer", func(t *testing.T) {
+		s := newTestQuizService(t, questionsByDifficulty, "testuser")
+		assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "adaptive", Strategy: models.StrategyAdaptive}))
+		assert.NoError(t, s.StartQuiz("testuser", "adaptive"))
+
+		_, err := s.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+		_, err = s.SubmitAnswer("testuser", 1, 2)
+		assert.NoError(t, err)
+		question, err := s.GetNextQuestion("testuser")
+		assert.NoError(t, err)
+		assert.Equal(t, "Easier", question.Question)
+
+		attempt, err := s.GetLatestAttempt("testuser")
+		assert.NoError(t, err)
+		assert.Less(t, attempt.Ability, 0.0)
+		assert.Greater(t, attempt.AbilityError, 0.0)
+	})
+}
+
+func TestAdaptiveStrategyStops(t *testing.T) {
+	t.Run("at the question cap", func(t *testing.T) {
+		s := newTestQuizService(t, questionsByDifficulty, "testuser")
+		assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "adaptive", Strategy: models.StrategyAdaptive, MaxQuestions: 2}))
+		assert.NoError(t, s.StartQuiz("testuser", "adaptive"))
+
+		for i := 0; i < 2; i++ {
+			question, err := s.GetNextQuestion("testuser")
+			assert.NoError(t, err)
+			_, err = s.SubmitAnswer("testuser", question.QuestionID-1, 1)
+			assert.NoError(t, err)
+		}
+		_, err := s.GetNextQuestion("testuser")
+		assert.ErrorIs(t, err, ErrQuizComplete)
+	})
+
+	t.Run("once the estimate converges", func(t *testing.T) {
+		s := newTestQuizService(t, questionsByDifficulty, "testuser")
+		assert.NoError(t, s.RegisterQuiz(models.Quiz{QuizID: "adaptive", Strategy: models.StrategyAdaptive, TargetPrecision: 1.5}))
+		assert.NoError(t, s.StartQuiz("testuser", "adaptive"))
+
+		for i := 0; i < minAdaptiveQuestions; i++ {
+			question, err := s.GetNextQuestion("testuser")
+			assert.NoError(t, err)
+			_, err = s.SubmitAnswer("testuser", question.QuestionID-1, 1)
+			assert.NoError(t, err)
+		}
+		_, err := s.GetNextQuestion("testuser")
+		assert.ErrorIs(t, err, ErrQuizComplete, "expected the attempt to end before all questions were served")
+
+		attempt, _ := s.GetLatestAttempt("testuser")
+		assert.Len(t, attempt.Answers, minAdaptiveQuestions)
+		assert.LessOrEqual(t, attempt.AbilityError, 1.5)
+	})
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	initialEaseFactor = 2.5
+	minEaseFactor     = 1.3
+)
+
+var (
+	ErrNothingDue         = errors.New("no study cards due")
+	ErrInvalidStudyAnswer = errors.New("invalid study answer")
+)
+
+// NothingDueError is returned by NextCard when every question has been
+// studied and none is due yet. It unwraps to ErrNothingDue.
+type NothingDueError struct {
+	NextDueAt time.Time
+}
+
+func (e *NothingDueError) Error() string {
+	if e.NextDueAt.IsZero() {
+		return ErrNothingDue.Error()
+	}
+	return fmt.Sprintf("%s, next review at %s", ErrNothingDue.Error(), e.NextDueAt.Format(time.RFC3339))
+}
+
+func (e *NothingDueError) Unwrap() error {
+	return ErrNothingDue
+}
+
+// StudyService schedules the questions of every user with SM-2. Answers given
+// in quizzes feed the same schedule as answers given while studying.
+type StudyService struct {
+	DB    *database.MemoryDB
+	Clock func() time.Time
+}
+
+func NewStudyService(db *database.MemoryDB) *StudyService {
+	return &StudyService{DB: db}
+}
+
+func (s *StudyService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// NextCard returns the question the user should study now. Cards that are due
+// come first, the most overdue one before the others, then questions that
+// were never answered.
+func (s *StudyService) NextCard(username string) (*models.StudyItem, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	if _, err := s.DB.GetUser(username); err != nil {
+		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if len(questions) == 0 {
+		logger.Warn("No questions available to study")
+		return nil, errors.New("no questions available")
+	}
+
+	cards, err := s.syncCards(username)
+	if err != nil {
+		logger.Error("Failed to update study cards", zap.String("username", username), zap.Error(err))
+		return nil, err
+	}
+
+	now := s.now()
+	var due, fresh []database.StudyCard
+	for _, card := range cards {
+		switch {
+		case card.LastReviewedAt.IsZero():
+			fresh = append(fresh, card)
+		case !card.DueAt.After(now):
+			due = append(due, card)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].DueAt.Before(due[j].DueAt)
+	})
+
+	var next database.StudyCard
+	switch {
+	case len(due) > 0:
+		next = due[0]
+	case len(fresh) > 0:
+		next = fresh[0]
+	default:
+		nextDueAt := cards[0].DueAt
+		for _, card := range cards[1:] {
+			if card.DueAt.Before(nextDueAt) {
+				nextDueAt = card.DueAt
+			}
+		}
+		logger.Info("No study cards due", zap.String("username", username), zap.Time("nextDueAt", nextDueAt))
+		return nil, &NothingDueError{NextDueAt: nextDueAt}
+	}
+
+	logger.Info("Study card served", zap.String("username", username), zap.Int("questionID", next.QuestionID))
+	return &models.StudyItem{
+		Question: servedQuestion(questions[next.QuestionIndex]),
+		Card:     models.StudyCard(next),
+	}, nil
+}
+
+// Review grades a study answer and schedules the question again. A wrong
+// answer always counts as a lapse, a correct one is graded by quality, which
+// defaults to 4 when not given.
+func (s *StudyService) Review(username string, questionIndex, answer, quality int) (*models.StudyReviewResult, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	if err := utils.ValidateAnswerPayload(questionIndex, answer, questions); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStudyAnswer, err)
+	}
+	if quality != 0 && (quality < 3 || quality > 5) {
+		return nil, fmt.Errorf("%w: quality must be between 3 and 5", ErrInvalidStudyAnswer)
+	}
+	if _, err := s.DB.GetUser(username); err != nil {
+		logger.Error("User not found in database", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	cards, err := s.syncCards(username)
+	if err != nil {
+		logger.Error("Failed to update study cards", zap.String("username", username), zap.Error(err))
+		return nil, err
+	}
+
+	question := questions[questionIndex]
+	var card database.StudyCard
+	for _, c := range cards {
+		if c.QuestionID == question.QuestionID {
+			card = c
+			break
+		}
+	}
+
+	correct := answer == question.Answer
+	grade := 1
+	if correct {
+		grade = 4
+		if quality != 0 {
+			grade = quality
+		}
+	}
+	scheduleReview(&card, grade, s.now())
+	if err := s.DB.SaveStudyCard(card); err != nil {
+		logger.Error("Failed to save study card", zap.String("username", username), zap.Error(err))
+		return nil, fmt.Errorf("failed to save study card: %w", err)
+	}
+
+	logger.Info("Study answer reviewed", zap.String("username", username), zap.Int("questionID", question.QuestionID), zap.Bool("correct", correct), zap.Int("intervalDays", card.IntervalDays))
+	return &models.StudyReviewResult{
+		Correct:       correct,
+		CorrectAnswer: question.Answer,
+		Explanation:   question.Explanation,
+		Quality:       grade,
+		Card:          models.StudyCard(card),
+	}, nil
+}
+
+// syncCards makes sure the user has a card for every question and replays
+// quiz answers given since each card was last reviewed. It returns the cards
+// in question order.
+func (s *StudyService) syncCards(username string) ([]database.StudyCard, error) {
+	existing := make(map[int]database.StudyCard)
+	for _, card := range s.DB.ListStudyCards(username) {
+		existing[card.QuestionID] = card
+	}
+
+	var history []models.AnswerRecord
+	for _, attempt := range s.DB.ListAttempts(username) {
+		for _, record := range attempt.Answers {
+			if !record.AnsweredAt.IsZero() {
+				history = append(history, record)
+			}
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].AnsweredAt.Before(history[j].AnsweredAt)
+	})
+
+	now := s.now()
+	cards := make([]database.StudyCard, 0, len(questions))
+	for i, question := range questions {
+		card, exists := existing[question.QuestionID]
+		if !exists {
+			card = database.StudyCard{
+				Username:   username,
+				QuestionID: question.QuestionID,
+				EaseFactor: initialEaseFactor,
+				DueAt:      now,
+			}
+		}
+		changed := !exists || card.QuestionIndex != i
+		card.QuestionIndex = i
+
+		for _, record := range history {
+			if record.QuestionID == question.QuestionID && record.AnsweredAt.After(card.LastReviewedAt) {
+				scheduleReview(&card, historyQuality(record), record.AnsweredAt)
+				changed = true
+			}
+		}
+		if changed {
+			if err := s.DB.SaveStudyCard(card); err != nil {
+				return nil, fmt.Errorf("failed to save study card: %w", err)
+			}
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// historyQuality grades a quiz answer on the SM-2 scale: a first-try correct
+// answer is good, one that needed retries is hard and a wrong one is a lapse.
+func historyQuality(record models.AnswerRecord) int {
+	switch {
+	case record.Correct && record.Tries <= 1:
+		return 4
+	case record.Correct:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// scheduleReview applies one SM-2 review of the given quality (0-5) to the card.
+func scheduleReview(card *database.StudyCard, quality int, reviewedAt time.Time) {
+	if quality < 3 {
+		card.Repetitions = 0
+		card.IntervalDays = 1
+	} else {
+		switch card.Repetitions {
+		case 0:
+			card.IntervalDays = 1
+		case 1:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.EaseFactor))
+		}
+		card.Repetitions++
+	}
+
+	q := float64(5 - quality)
+	card.EaseFactor = math.Max(minEaseFactor, card.EaseFactor+0.1-q*(0.08+q*0.02))
+	card.LastReviewedAt = reviewedAt
+	card.DueAt = reviewedAt.AddDate(0, 0, card.IntervalDays)
+}
+
+var _ IStudyService = &StudyService{}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleReview(t *testing.T) {
+	reviewedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	card := database.StudyCard{EaseFactor: initialEaseFactor}
+
+	for _, expected := range []int{1, 6, 15} {
+		scheduleReview(&card, 4, reviewedAt)
+		assert.Equal(t, expected, card.IntervalDays)
+	}
+	assert.Equal(t, 3, card.Repetitions)
+	assert.Equal(t, reviewedAt.AddDate(0, 0, 15), card.DueAt)
+
+	scheduleReview(&card, 1, reviewedAt)
+	assert.Equal(t, 1, card.IntervalDays, "expected a lapse to restart the schedule")
+	assert.Equal(t, 0, card.Repetitions)
+	assert.Less(t, card.EaseFactor, initialEaseFactor)
+
+	for i := 0; i < 10; i++ {
+		scheduleReview(&card, 0, reviewedAt)
+	}
+	assert.Equal(t, minEaseFactor, card.EaseFactor)
+}
+
+func TestStudyServiceServesDueCardsFirst(t *testing.T) {
+	clock, now := newTestClock()
+	// Two questions, so that both are scheduled after they are answered
+	s := NewStudyService(newTestQuizService(t, testQuestions[:2], "testuser").DB)
+	s.Clock = clock
+
+	item, err := s.NextCard("testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, item.Question.QuestionID, "expected new questions in order")
+	assert.Empty(t, item.Question.Explanation)
+
+	result, err := s.Review("testuser", 0, 1, 0)
+	assert.NoError(t, err)
+	assert.False(t, result.Correct)
+	assert.Equal(t, "Two plus two is four", result.Explanation)
+	assert.Equal(t, 1, result.Card.IntervalDays)
+
+	item, err = s.NextCard("testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, item.Question.QuestionID, "expected the missed question not to be due yet")
+
+	_, err = s.Review("testuser", 1, 1, 5)
+	assert.NoError(t, err)
+
+	_, err = s.NextCard("testuser")
+	var nothingDue *NothingDueError
+	assert.ErrorAs(t, err, &nothingDue)
+	assert.ErrorIs(t, err, ErrNothingDue)
+	assert.Equal(t, now.AddDate(0, 0, 1), nothingDue.NextDueAt)
+
+	*now = now.AddDate(0, 0, 2)
+	item, err = s.NextCard("testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, item.Question.QuestionID, "expected the missed question to be due again")
+}
+
+func TestStudyServiceSeedsFromAnswerHistory(t *testing.T) {
+	clock, now := newTestClock()
+	// Two questions, so that both are scheduled after they are answered
+	s := NewStudyService(newTestQuizService(t, testQuestions[:2], "testuser").DB)
+	s.Clock = clock
+	db := s.DB
+	answeredAt := now.Add(-time.Hour)
+	db.AddAttempt(database.Attempt{
+		AttemptID: "attempt-1",
+		Username:  "testuser",
+		StartedAt: answeredAt,
+		Answers: []models.AnswerRecord{
+			{QuestionIndex: 0, QuestionID: 1, Answer: 2, Correct: true, Tries: 1, AnsweredAt: answeredAt},
+			{QuestionIndex: 1, QuestionID: 2, Answer: 2, Tries: 1, AnsweredAt: answeredAt},
+		},
+	})
+	db.AddAttempt(database.Attempt{
+		AttemptID: "attempt-2",
+		Username:  "testuser",
+		StartedAt: answeredAt.Add(time.Minute),
+		Answers: []models.AnswerRecord{
+			{QuestionIndex: 0, QuestionID: 1, Answer: 2, Correct: true, Tries: 1, AnsweredAt: answeredAt.Add(time.Minute)},
+		},
+	})
+
+	_, err := s.NextCard("testuser")
+	var nothingDue *NothingDueError
+	assert.ErrorAs(t, err, &nothingDue, "expected both questions to be scheduled from the quiz")
+
+	cards := db.ListStudyCards("testuser")
+	assert.Len(t, cards, 2)
+	assert.Equal(t, answeredAt.AddDate(0, 0, 1), cards[1].DueAt)
+	assert.Equal(t, 2, cards[0].Repetitions)
+	assert.Equal(t, 6, cards[0].IntervalDays)
+	assert.Equal(t, 0, cards[1].Repetitions)
+
+	*now = now.AddDate(0, 0, 1)
+	item, err := s.NextCard("testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, item.Question.QuestionID, "expected the question missed in the quiz to be due first")
+}
+
+func TestStudyServiceRejectsInvalidReview(t *testing.T) {
+	s := NewStudyService(newTestQuizService(t, testQuestions, "testuser").DB)
+	s.Clock, _ = newTestClock()
+
+	_, err := s.Review("testuser", 5, 1, 0)
+	assert.ErrorIs(t, err, ErrInvalidStudyAnswer)
+
+	_, err = s.Review("testuser", 0, 2, 7)
+	assert.ErrorIs(t, err, ErrInvalidStudyAnswer)
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const DefaultTeamTopN = 3
+
+var (
+	ErrInvalidTeam     = errors.New("a team needs a name and an ID of letters, digits, - and _")
+	ErrAlreadyInTeam   = errors.New("user is already in a team")
+	ErrNotTeamMember   = errors.New("user is not a member of the team")
+	ErrInvalidTeamRule = errors.New("team rule must be sum, average or top")
+	ErrInvalidTopN     = errors.New("top must be a positive number")
+)
+
+var teamIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// TeamService manages the teams and ranks them by the scores of their members
+// on the player leaderboards. Players join and leave teams themselves, admins
+// can move anyone between teams.
+type TeamService struct {
+	DB          *database.MemoryDB
+	Leaderboard *LeaderboardService
+	Clock       func() time.Time
+	// DefaultTopN is how many members count under the top rule when the query
+	// does not say
+	DefaultTopN int
+
+	// mu keeps a player in one team at most while memberships change
+	mu sync.Mutex
+}
+
+func NewTeamService(db *database.MemoryDB, leaderboard *LeaderboardService) *TeamService {
+	return &TeamService{DB: db, Leaderboard: leaderboard, DefaultTopN: DefaultTeamTopN}
+}
+
+func (s *TeamService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// CreateTeam adds a team without members.
+func (s *TeamService) CreateTeam(payload models.TeamPayload) (*models.Team, error) {
+	logger := utils.GetLogger().Sugar()
+
+	if !teamIDPattern.MatchString(payload.TeamID) || payload.Name == "" {
+		return nil, ErrInvalidTeam
+	}
+	team := models.Team{TeamID: payload.TeamID, Name: payload.Name, Members: []string{}, CreatedAt: s.now()}
+	if err := s.DB.AddTeam(database.Team(team)); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Team created", zap.String("teamID", team.TeamID))
+	return &team, nil
+}
+
+// DeleteTeam removes a team. Its members are left without a team.
+func (s *TeamService) DeleteTeam(teamID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.DB.DeleteTeam(teamID); err != nil {
+		return err
+	}
+	utils.GetLogger().Sugar().Info("Team deleted", zap.String("teamID", teamID))
+	return nil
+}
+
+func (s *TeamService) GetTeam(teamID string) (*models.Team, error) {
+	team, err := s.DB.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	result := models.Team(team)
+	return &result, nil
+}
+
+func (s *TeamService) ListTeams() ([]models.Team, error) {
+	teams := []models.Team{}
+	for _, team := range s.DB.ListTeams() {
+		teams = append(teams, models.Team(team))
+	}
+	return teams, nil
+}
+
+// JoinTeam makes the user a member of the team. Joining the team the user is
+// already in changes nothing, but they must leave another team first.
+func (s *TeamService) JoinTeam(teamID, username string) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, err := s.DB.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if current, found := s.teamOf(username); found {
+		if current.TeamID != teamID {
+			return nil, fmt.Errorf("%w: %s", ErrAlreadyInTeam, current.TeamID)
+		}
+		result := models.Team(team)
+		return &result, nil
+	}
+	return s.addMember(team, username)
+}
+
+// LeaveTeam removes the user from the team.
+func (s *TeamService) LeaveTeam(teamID, username string) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, err := s.DB.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return s.removeMember(team, username)
+}
+
+// AddMember puts an existing user in the team, taking them out of the team
+// they were in.
+func (s *TeamService) AddMember(teamID, username string) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.DB.GetUser(username); err != nil {
+		return nil, err
+	}
+	team, err := s.DB.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	current, found := s.teamOf(username)
+	if found && current.TeamID == teamID {
+		result := models.Team(team)
+		return &result, nil
+	}
+	if found {
+		if _, err := s.removeMember(current, username); err != nil {
+			return nil, err
+		}
+	}
+	return s.addMember(team, username)
+}
+
+// RemoveMember takes the user out of the team.
+func (s *TeamService) RemoveMember(teamID, username string) (*models.Team, error) {
+	return s.LeaveTeam(teamID, username)
+}
+
+// teamOf finds the team of a user. Callers changing memberships hold mu.
+func (s *TeamService) teamOf(username string) (database.Team, bool) {
+	for _, team := range s.DB.ListTeams() {
+		if models.Team(team).HasMember(username) {
+			return team, true
+		}
+	}
+	return database.Team{}, false
+}
+
+func (s *TeamService) addMember(team database.Team, username string) (*models.Team, error) {
+	team.Members = append(slices.Clone(team.Members), username)
+	sort.Strings(team.Members)
+	if err := s.DB.UpdateTeam(team); err != nil {
+		return nil, err
+	}
+
+	utils.GetLogger().Sugar().Info("Team member added", zap.String("teamID", team.TeamID), zap.String("username", username))
+	result := models.Team(team)
+	return &result, nil
+}
+
+func (s *TeamService) removeMember(team database.Team, username string) (*models.Team, error) {
+	i := slices.Index(team.Members, username)
+	if i < 0 {
+		return nil, ErrNotTeamMember
+	}
+	team.Members = slices.Delete(slices.Clone(team.Members), i, i+1)
+	if err := s.DB.UpdateTeam(team); err != nil {
+		return nil, err
+	}
+
+	utils.GetLogger().Sugar().Info("Team member removed", zap.String("teamID", team.TeamID), zap.String("username", username))
+	result := models.Team(team)
+	return &result, nil
+}
+
+// GetTeamLeaderboard returns one page of the team leaderboard. The score of a
+// member is their score on the player leaderboard of the same quiz and period,
+// and the rule of the query adds the member scores up. The team of the
+// calling user is returned as "my team".
+func (s *TeamService) GetTeamLeaderboard(username string, query models.TeamLeaderboardQuery) (*models.TeamLeaderboard, error) {
+	logger := utils.GetLogger().Sugar()
+
+	offset, err := s.Leaderboard.checkQuery(&query.LeaderboardQuery)
+	if err != nil {
+		return nil, err
+	}
+	switch query.Rule {
+	case "":
+		query.Rule = models.TeamRuleSum
+		query.TopN = 0
+	case models.TeamRuleSum, models.TeamRuleAverage:
+		query.TopN = 0
+	case models.TeamRuleTopN:
+		if query.TopN == 0 {
+			query.TopN = s.DefaultTopN
+		}
+		if query.TopN < 1 {
+			return nil, ErrInvalidTopN
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTeamRule, query.Rule)
+	}
+
+	now := s.Leaderboard.now()
+	scores := make(map[string]float64)
+	for _, entry := range s.Leaderboard.entries(query.QuizID, query.Period, now) {
+		scores[entry.Username] = entry.Score
+	}
+
+	var entries []models.TeamLeaderboardEntry
+	for _, team := range s.DB.ListTeams() {
+		entries = append(entries, teamEntry(models.Team(team), scores, query.Rule, query.TopN))
+	}
+	rankTeams(entries)
+
+	leaderboard := &models.TeamLeaderboard{
+		QuizID:   query.QuizID,
+		Period:   query.Period,
+		Rule:     query.Rule,
+		TopN:     query.TopN,
+		Total:    len(entries),
+		Entries:  []models.TeamLeaderboardEntry{},
+		ResetsAt: s.Leaderboard.resetsAt(query.Period, now),
+	}
+	if offset < len(entries) {
+		end := min(offset+query.Limit, len(entries))
+		leaderboard.Entries = entries[offset:end]
+		if end < len(entries) {
+			leaderboard.NextCursor = strconv.Itoa(end)
+		}
+	}
+	if current, found := s.teamOf(username); found {
+		for i := range entries {
+			if entries[i].TeamID == current.TeamID {
+				leaderboard.MyTeam = &entries[i]
+				break
+			}
+		}
+	}
+
+	logger.Info("Team leaderboard served", zap.String("quizID", query.QuizID), zap.String("period", string(query.Period)), zap.String("rule", string(query.Rule)), zap.Int("teams", len(entries)))
+	return leaderboard, nil
+}
+
+// teamEntry adds up the scores of the members of a team by the rule.
+func teamEntry(team models.Team, scores map[string]float64, rule models.TeamRule, topN int) models.TeamLeaderboardEntry {
+	entry := models.TeamLeaderboardEntry{TeamID: team.TeamID, Name: team.Name, Members: len(team.Members)}
+
+	memberScores := make([]float64, 0, len(team.Members))
+	for _, member := range team.Members {
+		score, played := scores[member]
+		if played {
+			entry.Players++
+		}
+		memberScores = append(memberScores, score)
+	}
+	if rule == models.TeamRuleTopN {
+		sort.Sort(sort.Reverse(sort.Float64Slice(memberScores)))
+		memberScores = memberScores[:min(topN, len(memberScores))]
+	}
+	for _, score := range memberScores {
+		entry.Score += score
+	}
+	if rule == models.TeamRuleAverage && len(team.Members) > 0 {
+		entry.Score /= float64(len(team.Members))
+	}
+	return entry
+}
+
+// rankTeams orders the teams by score and gives them competition ranks, like
+// rankEntries does for players.
+func rankTeams(entries []models.TeamLeaderboardEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].TeamID < entries[j].TeamID
+	})
+	for i := range entries {
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+}
+
+var _ ITeamService = &TeamService{}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamMembership(t *testing.T) {
+	clock, now := newTestClock()
+	db := database.NewMemoryDB()
+	addLeaderboardAttempts(db, *now)
+	for _, username := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		db.AddUser(database.User{Username: username})
+	}
+	players := NewLeaderboardService(db, nil)
+	players.Clock = clock
+	s := NewTeamService(db, players)
+	s.Clock = clock
+	for _, payload := range []models.TeamPayload{{TeamID: "sales", Name: "Sales"}, {TeamID: "dev", Name: "Development"}} {
+		_, err := s.CreateTeam(payload)
+		assert.NoError(t, err)
+	}
+
+	_, err := s.CreateTeam(models.TeamPayload{TeamID: "sales", Name: "Sales again"})
+	assert.ErrorIs(t, err, database.ErrTeamExists)
+	_, err = s.CreateTeam(models.TeamPayload{TeamID: "no spaces", Name: "Bad"})
+	assert.ErrorIs(t, err, ErrInvalidTeam)
+
+	team, err := s.JoinTeam("sales", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, team.Members)
+	_, err = s.JoinTeam("sales", "alice")
+	assert.NoError(t, err, "expected joining the own team again to change nothing")
+	_, err = s.JoinTeam("dev", "alice")
+	assert.ErrorIs(t, err, ErrAlreadyInTeam)
+	_, err = s.LeaveTeam("dev", "alice")
+	assert.ErrorIs(t, err, ErrNotTeamMember)
+
+	// Admins move players between teams
+	team, err = s.AddMember("dev", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, team.Members)
+	sales, _ := s.GetTeam("sales")
+	assert.Empty(t, sales.Members, "expected the player to be moved out of the previous team")
+	_, err = s.AddMember("dev", "nobody")
+	assert.ErrorIs(t, err, database.ErrUserNotFound)
+
+	team, err = s.RemoveMember("dev", "alice")
+	assert.NoError(t, err)
+	assert.Empty(t, team.Members)
+
+	assert.NoError(t, s.DeleteTeam("dev"))
+	_, err = s.GetTeam("dev")
+	assert.ErrorIs(t, err, database.ErrTeamNotFound)
+}
+
+func TestTeamLeaderboardRules(t *testing.T) {
+	clock, now := newTestClock()
+	db := database.NewMemoryDB()
+	addLeaderboardAttempts(db, *now)
+	for _, username := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		db.AddUser(database.User{Username: username})
+	}
+	players := NewLeaderboardService(db, nil)
+	players.Clock = clock
+	s := NewTeamService(db, players)
+	s.Clock = clock
+	for _, payload := range []models.TeamPayload{{TeamID: "sales", Name: "Sales"}, {TeamID: "dev", Name: "Development"}} {
+		_, err := s.CreateTeam(payload)
+		assert.NoError(t, err)
+	}
+	// Exam scores: alice 5, bob 5, carol 4, dave 2, erin never finished
+	for _, member := range []string{"alice", "dave", "erin"} {
+		_, err := s.JoinTeam("sales", member)
+		assert.NoError(t, err)
+	}
+	for _, member := range []string{"bob", "carol"} {
+		_, err := s.JoinTeam("dev", member)
+		assert.NoError(t, err)
+	}
+
+	leaderboard, err := s.GetTeamLeaderboard("erin", models.TeamLeaderboardQuery{LeaderboardQuery: models.LeaderboardQuery{QuizID: "exam"}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.TeamRuleSum, leaderboard.Rule)
+	assert.Equal(t, []models.TeamLeaderboardEntry{
+		{Rank: 1, TeamID: "dev", Name: "Development", Score: 9, Members: 2, Players: 2},
+		{Rank: 2, TeamID: "sales", Name: "Sales", Score: 7, Members: 3, Players: 2},
+	}, leaderboard.Entries)
+	if assert.NotNil(t, leaderboard.MyTeam) {
+		assert.Equal(t, "sales", leaderboard.MyTeam.TeamID)
+	}
+
+	leaderboard, err = s.GetTeamLeaderboard("erin", models.TeamLeaderboardQuery{LeaderboardQuery: models.LeaderboardQuery{QuizID: "exam"}, Rule: models.TeamRuleAverage})
+	assert.NoError(t, err)
+	assert.Equal(t, 4.5, leaderboard.Entries[0].Score)
+	assert.InDelta(t, 7.0/3, leaderboard.Entries[1].Score, 0.001, "expected members who did not play to count as zero")
+
+	leaderboard, err = s.GetTeamLeaderboard("bob", models.TeamLeaderboardQuery{LeaderboardQuery: models.LeaderboardQuery{QuizID: "exam"}, Rule: models.TeamRuleTopN, TopN: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, leaderboard.TopN)
+	assert.Equal(t, 1, leaderboard.Entries[0].Rank)
+	assert.Equal(t, 1, leaderboard.Entries[1].Rank, "expected teams with the same best member to share a rank")
+	assert.Equal(t, 5.0, leaderboard.Entries[1].Score)
+
+	_, err = s.GetTeamLeaderboard("bob", models.TeamLeaderboardQuery{Rule: "median"})
+	assert.ErrorIs(t, err, ErrInvalidTeamRule)
+	_, err = s.GetTeamLeaderboard("bob", models.TeamLeaderboardQuery{Rule: models.TeamRuleTopN, TopN: -1})
+	assert.ErrorIs(t, err, ErrInvalidTopN)
+	_, err = s.GetTeamLeaderboard("bob", models.TeamLeaderboardQuery{LeaderboardQuery: models.LeaderboardQuery{Period: "year"}})
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
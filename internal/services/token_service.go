@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, every token of the login is revoked")
+)
+
+// refreshMu serializes the changes to refresh tokens so that a token is
+// rotated once at most and revocations are not lost.
+var refreshMu sync.Mutex
+
+// TokenService issues the bearer tokens of API clients. Access tokens are
+// short-lived signed JWTs checked without a lookup, refresh tokens are random
+// secrets kept hashed in the database and replaced on every use.
+type TokenService struct {
+	DB    *database.MemoryDB
+	Auth  IAuthService
+	Clock func() time.Time
+	// RefreshTTL is how long a refresh token can be used after it is issued
+	RefreshTTL time.Duration
+	// TwoFactor adds the second login step of the users who have it on
+	TwoFactor ITwoFactorService
+}
+
+func NewTokenService(db *database.MemoryDB, auth IAuthService) *TokenService {
+	return &TokenService{DB: db, Auth: auth, RefreshTTL: DefaultRefreshTokenTTL}
+}
+
+func (s *TokenService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// Login checks the credentials of a login from ip, under the same limits as
+// session logins, and starts a new token family. Users with two-factor
+// authentication on get a challenge instead, for CompleteLogin.
+func (s *TokenService) Login(username, password, ip string) (*models.TokenPair, *models.LoginChallenge, error) {
+	if err := s.Auth.Login(username, password, ip); err != nil {
+		return nil, nil, err
+	}
+	if s.TwoFactor != nil {
+		challenge, err := s.TwoFactor.StartLogin(username)
+		if err != nil || challenge != nil {
+			return nil, challenge, err
+		}
+	}
+
+	pair, err := s.start(username)
+	return pair, nil, err
+}
+
+// CompleteLogin finishes a two-factor login and starts a new token family.
+func (s *TokenService) CompleteLogin(challengeToken, code, ip string) (*models.TokenPair, error) {
+	if s.TwoFactor == nil {
+		return nil, ErrInvalidLoginChallenge
+	}
+	username, err := s.TwoFactor.CompleteLogin(challengeToken, code, ip)
+	if err != nil {
+		return nil, err
+	}
+	return s.start(username)
+}
+
+// start issues the first pair of a new token family.
+func (s *TokenService) start(username string) (*models.TokenPair, error) {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+	pair, err := s.issue(username, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+
+	utils.GetLogger().Sugar().Info("Tokens issued", zap.String("username", username))
+	return pair, nil
+}
+
+// Refresh exchanges a refresh token for a new pair. Each refresh token works
+// once; presenting it again means it leaked, so its whole family is revoked.
+func (s *TokenService) Refresh(refreshToken string) (*models.TokenPair, error) {
+	logger := utils.GetLogger().Sugar()
+
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	token, err := s.DB.GetRefreshToken(utils.HashToken(refreshToken))
+	if errors.Is(err, database.ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, err
+	}
+	if token.Revoked || !s.now().Before(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !token.RotatedAt.IsZero() {
+		if err := s.revokeFamily(token.Username, token.FamilyID); err != nil {
+			return nil, err
+		}
+		logger.Warn("Rotated refresh token reused", zap.String("username", token.Username), zap.String("family", token.FamilyID))
+		return nil, ErrRefreshTokenReused
+	}
+	if _, err := s.DB.GetUser(token.Username); err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	token.RotatedAt = s.now()
+	if err := s.DB.UpdateRefreshToken(token); err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	return s.issue(token.Username, token.FamilyID)
+}
+
+// Revoke ends the login of a refresh token, and the access token when one is
+// given.
+func (s *TokenService) Revoke(refreshToken, accessToken string) error {
+	if accessToken != "" {
+		if claims, err := utils.ParseAccessToken(accessToken); err == nil {
+			utils.RevokeAccessToken(claims)
+		}
+	}
+
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	token, err := s.DB.GetRefreshToken(utils.HashToken(refreshToken))
+	if errors.Is(err, database.ErrRefreshTokenNotFound) {
+		return ErrInvalidRefreshToken
+	} else if err != nil {
+		return err
+	}
+	if err := s.revokeFamily(token.Username, token.FamilyID); err != nil {
+		return err
+	}
+
+	utils.GetLogger().Sugar().Info("Refresh token revoked", zap.String("username", token.Username))
+	return nil
+}
+
+// issue signs an access token and stores a new refresh token in the family.
+// The caller holds refreshMu.
+func (s *TokenService) issue(username, familyID string) (*models.TokenPair, error) {
+	accessToken, _, err := utils.IssueAccessToken(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	refreshToken, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := s.now()
+	if err := s.DB.AddRefreshToken(database.RefreshToken{
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyID,
+		Username:  username,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.RefreshTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revokeFamily revokes every refresh token rotated from the same login. The
+// caller holds refreshMu.
+func (s *TokenService) revokeFamily(username, familyID string) error {
+	for _, token := range s.DB.ListRefreshTokens(username) {
+		if token.FamilyID != familyID || token.Revoked {
+			continue
+		}
+		token.Revoked = true
+		if err := s.DB.UpdateRefreshToken(token); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+	return nil
+}
+
+// revokeUserTokens revokes every bearer token of the user and returns how many
+// token families could still be refreshed.
+func revokeUserTokens(db *database.MemoryDB, username string, now time.Time) (int, error) {
+	utils.RevokeUserAccessTokens(username)
+
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	live := map[string]bool{}
+	for _, token := range db.ListRefreshTokens(username) {
+		if token.Revoked {
+			continue
+		}
+		if token.RotatedAt.IsZero() && now.Before(token.ExpiresAt) {
+			live[token.FamilyID] = true
+		}
+		token.Revoked = true
+		if err := db.UpdateRefreshToken(token); err != nil {
+			return 0, fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+	return len(live), nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRotation(t *testing.T) {
+	assert.NoError(t, utils.SetJWTKeys([]utils.JWTKey{{ID: "test", Secret: []byte("test-secret")}}))
+	authService := newTestAuthService(t, "tokenuser")
+	clock, now := newTestClock()
+	authService.Clock = clock
+	s := NewTokenService(authService.DB, authService)
+	s.Clock = clock
+
+	_, _, err := s.Login("tokenuser", "wrong", "")
+	assert.Error(t, err)
+	first, _, err := s.Login("tokenuser", "Password123!", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", first.TokenType)
+	claims, err := utils.ParseAccessToken(first.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "tokenuser", claims.Subject)
+
+	second, err := s.Refresh(first.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// The first token leaked and is played back
+	_, err = s.Refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = s.Refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken, "expected the reuse to revoke the whole login")
+
+	third, _, err := s.Login("tokenuser", "Password123!", "")
+	assert.NoError(t, err)
+	*now = now.Add(s.RefreshTTL)
+	_, err = s.Refresh(third.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken, "expected the refresh token to expire")
+}
+
+func TestTokenRevocation(t *testing.T) {
+	assert.NoError(t, utils.SetJWTKeys([]utils.JWTKey{{ID: "test", Secret: []byte("test-secret")}}))
+	authService := newTestAuthService(t, "tokenuser")
+	clock, _ := newTestClock()
+	authService.Clock = clock
+	s := NewTokenService(authService.DB, authService)
+	s.Clock = clock
+
+	pair, _, err := s.Login("tokenuser", "Password123!", "")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Revoke(pair.RefreshToken, pair.AccessToken))
+	_, err = utils.ParseAccessToken(pair.AccessToken)
+	assert.ErrorIs(t, err, utils.ErrTokenRevoked)
+	_, err = s.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.ErrorIs(t, s.Revoke("unknown", ""), ErrInvalidRefreshToken)
+
+	pair, _, err = s.Login("tokenuser", "Password123!", "")
+	assert.NoError(t, err)
+	revoked, err := authService.RevokeSessions("tokenuser")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, revoked, "expected the token login to count")
+	_, err = utils.ParseAccessToken(pair.AccessToken)
+	assert.ErrorIs(t, err, utils.ErrTokenRevoked)
+	_, err = s.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
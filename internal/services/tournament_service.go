@@ -0,0 +1,596 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultTournamentQuestions = 5
+	tournamentQuizPrefix       = "tournament-"
+)
+
+var (
+	ErrInvalidTournament        = errors.New("a tournament needs a name and a number of rounds that is not negative")
+	ErrInvalidTournamentFormat  = errors.New("tournament format must be single_elimination or swiss")
+	ErrRegistrationClosed       = errors.New("tournament registration is closed")
+	ErrAlreadyRegistered        = errors.New("player is already registered for the tournament")
+	ErrTournamentStarted        = errors.New("tournament has already started")
+	ErrNotEnoughPlayers         = errors.New("a tournament needs at least two players")
+	ErrNoQuestionsForTournament = errors.New("no questions available for the tournament")
+)
+
+// TournamentService runs round-based tournaments. Players register, are
+// seeded by their duel rating when the tournament starts and then play one
+// match per round. A match is a quiz only its two players can start, once
+// each, on the questions drawn for the round, and it is decided like a duel.
+//
+// Rounds are settled as their attempts complete, and deadlines are applied
+// whenever a tournament is read. The next round starts once every match of
+// the current one is decided.
+type TournamentService struct {
+	DB    *database.MemoryDB
+	Quiz  *QuizService
+	Clock func() time.Time
+
+	// RoundWindow is how long the players of a round have to play their match
+	RoundWindow time.Duration
+	// Questions is the number of questions of a match when the tournament
+	// does not say
+	Questions int
+}
+
+func NewTournamentService(quizService *QuizService) *TournamentService {
+	return &TournamentService{
+		DB:          quizService.DB,
+		Quiz:        quizService,
+		RoundWindow: 24 * time.Hour,
+		Questions:   DefaultTournamentQuestions,
+	}
+}
+
+func (s *TournamentService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// CreateTournament opens the registration of a new tournament.
+func (s *TournamentService) CreateTournament(payload models.TournamentPayload) (*models.Tournament, error) {
+	logger := utils.GetLogger().Sugar()
+
+	format := payload.Format
+	if format == "" {
+		format = models.TournamentSingleElimination
+	}
+	if format != models.TournamentSingleElimination && format != models.TournamentSwiss {
+		return nil, ErrInvalidTournamentFormat
+	}
+	if payload.Name == "" || payload.Rounds < 0 {
+		return nil, ErrInvalidTournament
+	}
+	questionCount := payload.Questions
+	if questionCount <= 0 {
+		questionCount = s.Questions
+	}
+
+	tournament := database.Tournament{
+		TournamentID: uuid.NewString(),
+		Name:         payload.Name,
+		Format:       format,
+		Status:       models.TournamentRegistration,
+		Questions:    questionCount,
+		Players:      []models.TournamentPlayer{},
+		Bracket:      []models.TournamentRound{},
+		CreatedAt:    s.now(),
+	}
+	if format == models.TournamentSwiss {
+		tournament.Rounds = payload.Rounds
+	}
+	if err := s.DB.AddTournament(tournament); err != nil {
+		logger.Error("Failed to store tournament", zap.Error(err))
+		return nil, fmt.Errorf("failed to store tournament: %w", err)
+	}
+
+	logger.Info("Tournament created", zap.String("tournamentID", tournament.TournamentID), zap.String("format", string(format)))
+	result := models.Tournament(tournament)
+	return &result, nil
+}
+
+// Register adds the user to a tournament that has not started yet.
+func (s *TournamentService) Register(tournamentID, username string) (*models.Tournament, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	tournament, err := s.load(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if tournament.Status != models.TournamentRegistration {
+		return nil, ErrRegistrationClosed
+	}
+	if tournamentPlayer(&tournament, username) != nil {
+		return nil, ErrAlreadyRegistered
+	}
+	if _, err := s.DB.GetUser(username); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	tournament.Players = append(tournament.Players, models.TournamentPlayer{Username: username})
+	if err := s.DB.UpdateTournament(tournament); err != nil {
+		return nil, fmt.Errorf("failed to update tournament: %w", err)
+	}
+
+	logger.Info("Player registered for tournament", zap.String("tournamentID", tournamentID), zap.String("username", username))
+	result := models.Tournament(tournament)
+	return &result, nil
+}
+
+// Start closes the registration, seeds the players by rating and starts the
+// first round.
+func (s *TournamentService) Start(tournamentID string) (*models.Tournament, error) {
+	logger := utils.GetLogger().Sugar()
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	tournament, err := s.load(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if tournament.Status != models.TournamentRegistration {
+		return nil, ErrTournamentStarted
+	}
+	if len(tournament.Players) < 2 {
+		return nil, ErrNotEnoughPlayers
+	}
+	if len(questions) == 0 {
+		return nil, ErrNoQuestionsForTournament
+	}
+
+	// Players with the same rating keep the order they registered in
+	for i := range tournament.Players {
+		player := &tournament.Players[i]
+		player.Rating = models.DefaultRating
+		if user, err := s.DB.GetUser(player.Username); err == nil {
+			player.Rating = user.Profile.DuelRating()
+		}
+	}
+	sort.SliceStable(tournament.Players, func(i, j int) bool {
+		return tournament.Players[i].Rating > tournament.Players[j].Rating
+	})
+	for i := range tournament.Players {
+		tournament.Players[i].Seed = i + 1
+	}
+
+	// Enough rounds for a single winner: the bracket is the next power of two
+	// and the top seeds get the byes
+	rounds := bits.Len(uint(len(tournament.Players) - 1))
+	if tournament.Format == models.TournamentSingleElimination || tournament.Rounds == 0 {
+		tournament.Rounds = rounds
+	}
+
+	now := s.now()
+	tournament.Status = models.TournamentRunning
+	tournament.StartedAt = now
+	if err := s.startRound(&tournament, now); err != nil {
+		return nil, err
+	}
+	if err := s.DB.UpdateTournament(tournament); err != nil {
+		return nil, fmt.Errorf("failed to update tournament: %w", err)
+	}
+
+	logger.Info("Tournament started", zap.String("tournamentID", tournamentID), zap.Int("players", len(tournament.Players)), zap.Int("rounds", tournament.Rounds))
+	result := models.Tournament(tournament)
+	return &result, nil
+}
+
+// GetTournament returns a tournament with its bracket.
+func (s *TournamentService) GetTournament(tournamentID string) (*models.Tournament, error) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	tournament, err := s.load(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	result := models.Tournament(tournament)
+	return &result, nil
+}
+
+// ListTournaments returns the tournaments, the latest first.
+func (s *TournamentService) ListTournaments() ([]models.Tournament, error) {
+	quizMu.Lock()
+	defer quizMu.Unlock()
+
+	tournaments := []models.Tournament{}
+	for _, stored := range s.DB.ListTournaments() {
+		tournament, err := s.load(stored.TournamentID)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, models.Tournament(tournament))
+	}
+	return tournaments, nil
+}
+
+// AttemptCompleted settles the tournament match of a completed attempt.
+func (s *TournamentService) AttemptCompleted(attempt models.Attempt) {
+	if !strings.HasPrefix(attempt.QuizID, tournamentQuizPrefix) {
+		return
+	}
+	for _, tournament := range s.DB.ListTournaments() {
+		if tournament.Status != models.TournamentRunning || !strings.HasPrefix(attempt.QuizID, tournamentQuizPrefix+tournament.TournamentID+"-") {
+			continue
+		}
+		if _, err := s.load(tournament.TournamentID); err != nil {
+			utils.GetLogger().Sugar().Error("Failed to settle tournament match", zap.String("quizID", attempt.QuizID), zap.Error(err))
+		}
+		return
+	}
+}
+
+// load returns a tournament after applying what happened since it was
+// stored. Callers must hold quizMu.
+func (s *TournamentService) load(tournamentID string) (database.Tournament, error) {
+	tournament, err := s.DB.GetTournament(tournamentID)
+	if err != nil {
+		return database.Tournament{}, err
+	}
+	tournament = cloneTournament(tournament)
+	if !s.settle(&tournament, s.now()) {
+		return tournament, nil
+	}
+	if err := s.DB.UpdateTournament(tournament); err != nil {
+		return database.Tournament{}, fmt.Errorf("failed to update tournament: %w", err)
+	}
+	return tournament, nil
+}
+
+// cloneTournament copies the players and the bracket, so that settling never
+// changes a tournament somebody else is reading.
+func cloneTournament(tournament database.Tournament) database.Tournament {
+	tournament.Players = slices.Clone(tournament.Players)
+	tournament.Bracket = slices.Clone(tournament.Bracket)
+	for i := range tournament.Bracket {
+		tournament.Bracket[i].Matches = slices.Clone(tournament.Bracket[i].Matches)
+	}
+	return tournament
+}
+
+// settle decides the matches of the current round whose players are done or
+// whose deadline passed, then starts the next round or ends the tournament.
+// It reports whether the tournament changed.
+func (s *TournamentService) settle(tournament *database.Tournament, now time.Time) bool {
+	changed := false
+	for tournament.Status == models.TournamentRunning {
+		round := &tournament.Bracket[len(tournament.Bracket)-1]
+		decided := true
+		for i := range round.Matches {
+			if round.Matches[i].Status != models.MatchActive {
+				continue
+			}
+			if s.settleMatch(tournament, &round.Matches[i], round.DeadlineAt, now) {
+				changed = true
+			} else {
+				decided = false
+			}
+		}
+		if !decided {
+			break
+		}
+
+		changed = true
+		if len(tournament.Bracket) >= tournament.Rounds {
+			s.finish(tournament, now)
+			break
+		}
+		if err := s.startRound(tournament, now); err != nil {
+			utils.GetLogger().Sugar().Error("Failed to start tournament round", zap.String("tournamentID", tournament.TournamentID), zap.Error(err))
+			break
+		}
+	}
+	return changed
+}
+
+// settleMatch decides a match once both players are done or the deadline
+// passed, and reports whether it did. A knockout match cannot end in a draw,
+// the better seed goes through instead.
+func (s *TournamentService) settleMatch(tournament *database.Tournament, match *models.TournamentMatch, deadline, now time.Time) bool {
+	a, aDone := matchResult(s.DB, match.QuizID, match.Players[0])
+	b, bDone := matchResult(s.DB, match.QuizID, match.Players[1])
+	if !(aDone && bDone) && now.Before(deadline) {
+		return false
+	}
+
+	playerA, playerB := tournamentPlayer(tournament, a.Username), tournamentPlayer(tournament, b.Username)
+	match.Status = models.MatchCompleted
+	match.Results = []models.DuelResult{a, b}
+	playerA.Score += a.Score
+	playerB.Score += b.Score
+
+	outcome := duelOutcome(a, b)
+	if !a.Finished && !b.Finished {
+		outcome = -1
+	}
+	if tournament.Format == models.TournamentSingleElimination && (outcome == 0.5 || outcome == -1) {
+		outcome = 0
+		if playerA.Seed < playerB.Seed {
+			outcome = 1
+		}
+	}
+	switch outcome {
+	case 1:
+		match.Winner = a.Username
+		playerA.Points++
+		playerB.Eliminated = tournament.Format == models.TournamentSingleElimination
+	case 0:
+		match.Winner = b.Username
+		playerB.Points++
+		playerA.Eliminated = tournament.Format == models.TournamentSingleElimination
+	case 0.5:
+		playerA.Points += 0.5
+		playerB.Points += 0.5
+	}
+
+	utils.GetLogger().Sugar().Info("Tournament match settled", zap.String("quizID", match.QuizID), zap.String("winner", match.Winner))
+	return true
+}
+
+// finish ends the tournament. The winner of the final wins a knockout
+// tournament, the top of the standings a Swiss one.
+func (s *TournamentService) finish(tournament *database.Tournament, now time.Time) {
+	tournament.Status = models.TournamentCompleted
+	tournament.CompletedAt = now
+	if tournament.Format == models.TournamentSingleElimination {
+		final := tournament.Bracket[len(tournament.Bracket)-1]
+		tournament.Winner = final.Matches[0].Winner
+	} else {
+		tournament.Winner = standings(tournament.Players)[0].Username
+	}
+	utils.GetLogger().Sugar().Info("Tournament completed", zap.String("tournamentID", tournament.TournamentID), zap.String("winner", tournament.Winner))
+}
+
+// startRound pairs the players of the next round, draws its questions and
+// opens the quizzes of its matches. Callers must hold quizMu.
+func (s *TournamentService) startRound(tournament *database.Tournament, now time.Time) error {
+	count := min(tournament.Questions, len(questions))
+	if count == 0 {
+		return ErrNoQuestionsForTournament
+	}
+	questionIDs := make([]int, 0, count)
+	for _, i := range rand.Perm(len(questions))[:count] {
+		questionIDs = append(questionIDs, questions[i].QuestionID)
+	}
+
+	var pairs [][]string
+	if tournament.Format == models.TournamentSingleElimination {
+		pairs = eliminationPairs(tournament)
+	} else {
+		pairs = swissPairs(tournament)
+	}
+
+	round := models.TournamentRound{
+		Number:      len(tournament.Bracket) + 1,
+		QuestionIDs: questionIDs,
+		StartedAt:   now,
+		DeadlineAt:  now.Add(s.RoundWindow),
+	}
+	for i, players := range pairs {
+		match := models.TournamentMatch{Number: i + 1, Players: players}
+		if len(players) == 1 {
+			match.Status = models.MatchBye
+			match.Winner = players[0]
+			tournamentPlayer(tournament, players[0]).Points++
+			round.Matches = append(round.Matches, match)
+			continue
+		}
+
+		match.Status = models.MatchActive
+		match.QuizID = fmt.Sprintf("%s%s-r%d-m%d", tournamentQuizPrefix, tournament.TournamentID, round.Number, match.Number)
+		quiz := DefaultQuiz()
+		quiz.QuizID = match.QuizID
+		quiz.Title = fmt.Sprintf("%s, round %d: %s vs %s", tournament.Name, round.Number, players[0], players[1])
+		quiz.MaxAttempts = 1
+		quiz.OpensAt = &round.StartedAt
+		quiz.ClosesAt = &round.DeadlineAt
+		quiz.ScoringPolicy = models.ScoreBest
+		quiz.QuestionIDs = questionIDs
+		quiz.Players = players
+		quiz.Unlisted = true
+		if err := s.Quiz.RegisterQuiz(quiz); err != nil {
+			return err
+		}
+		round.Matches = append(round.Matches, match)
+	}
+	tournament.Bracket = append(tournament.Bracket, round)
+
+	utils.GetLogger().Sugar().Info("Tournament round started", zap.String("tournamentID", tournament.TournamentID), zap.Int("round", round.Number), zap.Int("matches", len(round.Matches)))
+	return nil
+}
+
+// eliminationPairs pairs the first round by seed so that the top seeds meet
+// as late as possible, with byes for the top seeds when the players do not
+// fill the bracket. Later rounds pair the winners of neighbouring matches.
+func eliminationPairs(tournament *database.Tournament) [][]string {
+	var pairs [][]string
+	if len(tournament.Bracket) > 0 {
+		previous := tournament.Bracket[len(tournament.Bracket)-1].Matches
+		for i := 0; i+1 < len(previous); i += 2 {
+			pairs = append(pairs, []string{previous[i].Winner, previous[i+1].Winner})
+		}
+		return pairs
+	}
+
+	bySeed := make(map[int]string)
+	for _, player := range tournament.Players {
+		bySeed[player.Seed] = player.Username
+	}
+	order := seedOrder(1 << tournament.Rounds)
+	for i := 0; i < len(order); i += 2 {
+		var players []string
+		for _, seed := range order[i : i+2] {
+			if username, found := bySeed[seed]; found {
+				players = append(players, username)
+			}
+		}
+		pairs = append(pairs, players)
+	}
+	return pairs
+}
+
+// seedOrder lists the seeds of a bracket of the given size in the order they
+// are paired: 1 meets the last seed, and 1 and 2 can only meet in the final.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// swissPairs pairs every player with the next one in the standings they did
+// not play yet. With an odd number of players the lowest placed player who
+// had no bye yet sits the round out and gets a point.
+func swissPairs(tournament *database.Tournament) [][]string {
+	played := make(map[[2]string]bool)
+	hadBye := make(map[string]bool)
+	for _, round := range tournament.Bracket {
+		for _, match := range round.Matches {
+			if len(match.Players) == 1 {
+				hadBye[match.Players[0]] = true
+				continue
+			}
+			played[[2]string{match.Players[0], match.Players[1]}] = true
+			played[[2]string{match.Players[1], match.Players[0]}] = true
+		}
+	}
+
+	var remaining []string
+	for _, player := range standings(tournament.Players) {
+		remaining = append(remaining, player.Username)
+	}
+	var bye []string
+	if len(remaining)%2 == 1 {
+		i := len(remaining) - 1
+		for j := len(remaining) - 1; j >= 0; j-- {
+			if !hadBye[remaining[j]] {
+				i = j
+				break
+			}
+		}
+		bye = []string{remaining[i]}
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+
+	var pairs [][]string
+	for len(remaining) > 0 {
+		opponent := 1
+		for j := 1; j < len(remaining); j++ {
+			if !played[[2]string{remaining[0], remaining[j]}] {
+				opponent = j
+				break
+			}
+		}
+		pairs = append(pairs, []string{remaining[0], remaining[opponent]})
+		remaining = slices.Delete(remaining, opponent, opponent+1)
+		remaining = remaining[1:]
+	}
+	if bye != nil {
+		pairs = append(pairs, bye)
+	}
+	return pairs
+}
+
+// standings orders the players by points, then by their total score and
+// then by seed.
+func standings(players []models.TournamentPlayer) []models.TournamentPlayer {
+	sorted := slices.Clone(players)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Points != sorted[j].Points {
+			return sorted[i].Points > sorted[j].Points
+		}
+		if sorted[i].Score != sorted[j].Score {
+			return sorted[i].Score > sorted[j].Score
+		}
+		return sorted[i].Seed < sorted[j].Seed
+	})
+	return sorted
+}
+
+func tournamentPlayer(tournament *database.Tournament, username string) *models.TournamentPlayer {
+	for i := range tournament.Players {
+		if tournament.Players[i].Username == username {
+			return &tournament.Players[i]
+		}
+	}
+	return nil
+}
+
+// RenderBracket draws the rounds of a tournament as text, with the standings
+// of a Swiss tournament.
+func RenderBracket(tournament models.Tournament) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s, %s)\n", tournament.Name, tournament.Format, tournament.Status)
+
+	seeds := make(map[string]int)
+	for _, player := range tournament.Players {
+		seeds[player.Username] = player.Seed
+	}
+	for _, round := range tournament.Bracket {
+		fmt.Fprintf(&b, "\nRound %d of %d, until %s\n", round.Number, tournament.Rounds, round.DeadlineAt.Format(time.RFC3339))
+		for _, match := range round.Matches {
+			if match.Status == models.MatchBye {
+				fmt.Fprintf(&b, "  %d. [%d] %s  bye\n", match.Number, seeds[match.Players[0]], match.Players[0])
+				continue
+			}
+			line := fmt.Sprintf("  %d. [%d] %s  vs  [%d] %s", match.Number,
+				seeds[match.Players[0]], match.Players[0], seeds[match.Players[1]], match.Players[1])
+			switch {
+			case match.Status == models.MatchActive:
+				line += "  playing"
+			case len(match.Results) == 2:
+				line += fmt.Sprintf("  %d-%d", match.Results[0].Score, match.Results[1].Score)
+				if match.Winner != "" {
+					line += ", " + match.Winner + " wins"
+				} else {
+					line += ", draw"
+				}
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	if tournament.Format == models.TournamentSwiss && len(tournament.Bracket) > 0 {
+		b.WriteString("\nStandings\n")
+		for i, player := range standings(tournament.Players) {
+			fmt.Fprintf(&b, "  %d. %s  %.1f points, score %d\n", i+1, player.Username, player.Points, player.Score)
+		}
+	}
+	if tournament.Winner != "" {
+		fmt.Fprintf(&b, "\nWinner: %s\n", tournament.Winner)
+	}
+	return b.String()
+}
+
+var (
+	_ ITournamentService = &TournamentService{}
+	_ AttemptListener    = &TournamentService{}
+)
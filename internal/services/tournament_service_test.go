@@ -0,0 +1,126 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func registerAll(t *testing.T, s *TournamentService, tournamentID string) {
+	t.Helper()
+	for _, username := range []string{"carol", "bob", "alice"} {
+		_, err := s.Register(tournamentID, username)
+		assert.NoError(t, err)
+	}
+}
+
+func TestSingleEliminationTournament(t *testing.T) {
+	quizService := newTestQuizService(t, testQuestions, "carol", "bob", "alice")
+	// Added in reverse rating order to check the seeding
+	for username, rating := range map[string]int{"carol": 1100, "bob": 1200, "alice": 1300} {
+		assert.NoError(t, quizService.DB.UpdateUser(database.User{Username: username, Profile: models.PlayerProfile{Rating: rating}}))
+	}
+	clock, now := newTestClock()
+	quizService.Clock = clock
+	s := NewTournamentService(quizService)
+	s.Clock = clock
+	s.Questions = 2
+	quizService.AddAttemptListener(s)
+
+	_, err := s.CreateTournament(models.TournamentPayload{Name: "Cup", Format: "round_robin"})
+	assert.ErrorIs(t, err, ErrInvalidTournamentFormat)
+
+	tournament, err := s.CreateTournament(models.TournamentPayload{Name: "Cup"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.TournamentSingleElimination, tournament.Format)
+	_, err = s.Start(tournament.TournamentID)
+	assert.ErrorIs(t, err, ErrNotEnoughPlayers)
+	registerAll(t, s, tournament.TournamentID)
+	_, err = s.Register(tournament.TournamentID, "bob")
+	assert.ErrorIs(t, err, ErrAlreadyRegistered)
+
+	tournament, err = s.Start(tournament.TournamentID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tournament.Rounds)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, []string{tournament.Players[0].Username, tournament.Players[1].Username, tournament.Players[2].Username}, "expected the players seeded by rating")
+	_, err = s.Register(tournament.TournamentID, "alice")
+	assert.ErrorIs(t, err, ErrRegistrationClosed)
+
+	first := tournament.Bracket[0]
+	assert.Len(t, first.QuestionIDs, 2)
+	assert.Equal(t, models.MatchBye, first.Matches[0].Status, "expected the top seed to get the bye")
+	assert.Equal(t, []string{"bob", "carol"}, first.Matches[1].Players)
+	assert.Error(t, quizService.StartQuiz("alice", first.Matches[1].QuizID), "expected only the match players to start it")
+
+	playDuel(t, quizService, "bob", first.Matches[1].QuizID, true)
+	playDuel(t, quizService, "carol", first.Matches[1].QuizID, false)
+
+	tournament, err = s.GetTournament(tournament.TournamentID)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", tournament.Bracket[0].Matches[1].Winner)
+	assert.Len(t, tournament.Bracket, 2, "expected the next round to start once the round is decided")
+	final := tournament.Bracket[1].Matches[0]
+	assert.Equal(t, []string{"alice", "bob"}, final.Players)
+
+	// Bob never plays the final
+	playDuel(t, quizService, "alice", final.QuizID, false)
+	*now = now.Add(25 * time.Hour)
+	tournament, err = s.GetTournament(tournament.TournamentID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TournamentCompleted, tournament.Status)
+	assert.Equal(t, "alice", tournament.Winner, "expected finishing to beat not playing")
+	player, _ := tournament.Player("carol")
+	assert.True(t, player.Eliminated)
+	assert.Contains(t, RenderBracket(*tournament), "Winner: alice")
+}
+
+func TestSwissTournament(t *testing.T) {
+	quizService := newTestQuizService(t, testQuestions, "carol", "bob", "alice")
+	// Added in reverse rating order to check the seeding
+	for username, rating := range map[string]int{"carol": 1100, "bob": 1200, "alice": 1300} {
+		assert.NoError(t, quizService.DB.UpdateUser(database.User{Username: username, Profile: models.PlayerProfile{Rating: rating}}))
+	}
+	clock, now := newTestClock()
+	quizService.Clock = clock
+	s := NewTournamentService(quizService)
+	s.Clock = clock
+	s.Questions = 2
+	quizService.AddAttemptListener(s)
+
+	tournament, err := s.CreateTournament(models.TournamentPayload{Name: "League", Format: models.TournamentSwiss})
+	assert.NoError(t, err)
+	registerAll(t, s, tournament.TournamentID)
+	tournament, err = s.Start(tournament.TournamentID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tournament.Rounds)
+
+	first := tournament.Bracket[0]
+	assert.Equal(t, []string{"alice", "bob"}, first.Matches[0].Players)
+	assert.Equal(t, []string{"carol"}, first.Matches[1].Players, "expected the lowest placed player to sit out")
+	playDuel(t, quizService, "alice", first.Matches[0].QuizID, true)
+	playDuel(t, quizService, "bob", first.Matches[0].QuizID, false)
+
+	tournament, err = s.GetTournament(tournament.TournamentID)
+	assert.NoError(t, err)
+	second := tournament.Bracket[1]
+	assert.Equal(t, []string{"alice", "carol"}, second.Matches[0].Players, "expected the players with a point to meet")
+	assert.Equal(t, []string{"bob"}, second.Matches[1].Players, "expected a player without a bye to get it")
+
+	// Nobody plays the second round
+	*now = now.Add(25 * time.Hour)
+	tournament, err = s.GetTournament(tournament.TournamentID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TournamentCompleted, tournament.Status)
+	assert.Equal(t, "alice", tournament.Winner, "expected the total score to break the tie on points")
+	for _, player := range tournament.Players {
+		assert.Equal(t, 1.0, player.Points, "expected no points for a match neither player finished")
+	}
+	assert.Contains(t, RenderBracket(*tournament), "Standings")
+}
+
+func TestSeedOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 8, 4, 5, 2, 7, 3, 6}, seedOrder(8))
+}
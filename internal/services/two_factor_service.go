@@ -0,0 +1,379 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultLoginChallengeTTL = 5 * time.Minute
+	DefaultTOTPIssuer        = "Quiz App"
+)
+
+const (
+	recoveryCodeCount = 10
+	// maxChallengeAttempts wrong codes use a login challenge up
+	maxChallengeAttempts = 5
+	// totpSkew also accepts the codes of the neighbouring time steps, for
+	// clocks that drift
+	totpSkew = 1
+)
+
+var (
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication is already on")
+	ErrTwoFactorNotEnabled   = errors.New("two-factor authentication is off")
+	ErrNoTwoFactorEnrollment = errors.New("no two-factor enrollment, start one first")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrInvalidLoginChallenge = errors.New("invalid or expired login challenge")
+	ErrTwoFactorRequired     = errors.New("two-factor authentication is required for this account")
+)
+
+// TwoFactorService manages TOTP two-factor authentication and finishes the
+// logins of the users who have it on.
+type TwoFactorService struct {
+	DB    *database.MemoryDB
+	Clock func() time.Time
+	// Limiter counts wrong codes as failed logins
+	Limiter *LoginLimiter
+	// Issuer names the account in authenticator apps
+	Issuer string
+	// ChallengeTTL is how long the second login step may take
+	ChallengeTTL time.Duration
+	// RequireForAdmins keeps admins from turning two-factor authentication
+	// off; the admin endpoints refuse admins who have not turned it on
+	RequireForAdmins bool
+}
+
+func NewTwoFactorService(db *database.MemoryDB) *TwoFactorService {
+	return &TwoFactorService{DB: db, Issuer: DefaultTOTPIssuer, ChallengeTTL: DefaultLoginChallengeTTL}
+}
+
+func (s *TwoFactorService) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+// Required reports whether the user has to have two-factor authentication on.
+func (s *TwoFactorService) Required(user database.User) bool {
+	return s.RequireForAdmins && user.Role == models.RoleAdmin
+}
+
+func (s *TwoFactorService) Status(username string) (*models.TwoFactorStatus, error) {
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &models.TwoFactorStatus{
+		Enabled:           user.TwoFactor.Enabled,
+		Required:          s.Required(user),
+		RecoveryCodesLeft: len(user.TwoFactor.RecoveryCodes),
+	}, nil
+}
+
+// Enroll gives the user a new TOTP secret to add to an authenticator app.
+// Two-factor authentication stays off until Confirm gets a code of it.
+func (s *TwoFactorService) Enroll(username string) (*models.TwoFactorEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	authMu.Lock()
+	defer authMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if user.TwoFactor.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	user.TwoFactor = models.TwoFactor{Secret: secret}
+	if err := s.DB.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	utils.GetLogger().Sugar().Info("Two-factor enrollment started", zap.String("username", username))
+	return &models.TwoFactorEnrollment{Secret: secret, URI: utils.TOTPURI(s.Issuer, username, secret)}, nil
+}
+
+// Confirm turns two-factor authentication on with a first code from the
+// authenticator app and returns the recovery codes. Every other session and
+// bearer token of the user logged in with the password only, so they are
+// logged out; the session keepSessionToken, which just gave the code, stays.
+func (s *TwoFactorService) Confirm(username, code, keepSessionToken string) (*models.RecoveryCodes, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	authMu.Lock()
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		authMu.Unlock()
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if user.TwoFactor.Enabled {
+		authMu.Unlock()
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TwoFactor.Secret == "" {
+		authMu.Unlock()
+		return nil, ErrNoTwoFactorEnrollment
+	}
+	now := s.now()
+	if !checkTOTP(&user, code, now) {
+		authMu.Unlock()
+		return nil, ErrInvalidTwoFactorCode
+	}
+	user.TwoFactor.Enabled = true
+	user.TwoFactor.EnabledAt = now
+	user.TwoFactor.RecoveryCodes = hashes
+	err = s.DB.UpdateUser(user)
+	authMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if _, err := utils.RevokeOtherSessions(username, keepSessionToken); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if _, err := revokeUserTokens(s.DB, username, now); err != nil {
+		return nil, err
+	}
+
+	s.audit(models.AuditTwoFactorEnabled, username, "", "")
+	utils.GetLogger().Sugar().Info("Two-factor authentication enabled", zap.String("username", username))
+	return &models.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user after a
+// code from the authenticator app.
+func (s *TwoFactorService) RegenerateRecoveryCodes(username, code string) (*models.RecoveryCodes, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	authMu.Lock()
+	defer authMu.Unlock()
+
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if !user.TwoFactor.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if !checkTOTP(&user, code, s.now()) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	user.TwoFactor.RecoveryCodes = hashes
+	if err := s.DB.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	utils.GetLogger().Sugar().Info("Recovery codes regenerated", zap.String("username", username))
+	return &models.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off after a code from the
+// authenticator app or a recovery code.
+func (s *TwoFactorService) Disable(username, code string) error {
+	authMu.Lock()
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		authMu.Unlock()
+		return fmt.Errorf("user not found: %w", err)
+	}
+	if !user.TwoFactor.Enabled {
+		authMu.Unlock()
+		return ErrTwoFactorNotEnabled
+	}
+	if s.Required(user) {
+		authMu.Unlock()
+		return ErrTwoFactorRequired
+	}
+	if _, ok := checkCode(&user, code, s.now()); !ok {
+		authMu.Unlock()
+		return ErrInvalidTwoFactorCode
+	}
+	user.TwoFactor = models.TwoFactor{}
+	err = s.DB.UpdateUser(user)
+	authMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.audit(models.AuditTwoFactorDisabled, username, "", "")
+	utils.GetLogger().Sugar().Info("Two-factor authentication disabled", zap.String("username", username))
+	return nil
+}
+
+// StartLogin is called once the password of a login is correct. It returns
+// the challenge of the second step, or nil when the user has two-factor
+// authentication off and may log in right away.
+func (s *TwoFactorService) StartLogin(username string) (*models.LoginChallenge, error) {
+	user, err := s.DB.GetUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if !user.TwoFactor.Enabled {
+		return nil, nil
+	}
+
+	token, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate login challenge: %w", err)
+	}
+	now := s.now()
+	challenge := database.AccountToken{
+		TokenHash: utils.HashToken(token),
+		Purpose:   models.TokenPurposeLoginChallenge,
+		Username:  username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ChallengeTTL),
+	}
+	accountMu.Lock()
+	err = s.DB.AddAccountToken(challenge)
+	accountMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store login challenge: %w", err)
+	}
+	return &models.LoginChallenge{TwoFactorRequired: true, ChallengeToken: token, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// CompleteLogin checks the code sent with a login challenge and returns the
+// user to log in. A challenge works once and is used up by too many wrong
+// codes, which also count as failed logins.
+func (s *TwoFactorService) CompleteLogin(challengeToken, code, ip string) (string, error) {
+	logger := utils.GetLogger().Sugar()
+
+	accountMu.Lock()
+	defer accountMu.Unlock()
+
+	challenge, err := s.DB.GetAccountToken(utils.HashToken(challengeToken))
+	if errors.Is(err, database.ErrAccountTokenNotFound) {
+		return "", ErrInvalidLoginChallenge
+	} else if err != nil {
+		return "", err
+	}
+	now := s.now()
+	if challenge.Purpose != models.TokenPurposeLoginChallenge || !challenge.UsedAt.IsZero() || !now.Before(challenge.ExpiresAt) {
+		return "", ErrInvalidLoginChallenge
+	}
+	username := challenge.Username
+
+	if s.Limiter != nil {
+		if err := s.Limiter.Allow(username, ip); err != nil {
+			s.audit(models.AuditLoginThrottled, username, ip, err.Error())
+			return "", err
+		}
+	}
+
+	authMu.Lock()
+	user, err := s.DB.GetUser(username)
+	if err != nil || !user.TwoFactor.Enabled {
+		authMu.Unlock()
+		return "", ErrInvalidLoginChallenge
+	}
+	recovery, ok := checkCode(&user, code, now)
+	if ok {
+		err = s.DB.UpdateUser(user)
+	}
+	authMu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if !ok {
+		challenge.Attempts++
+		if challenge.Attempts >= maxChallengeAttempts {
+			challenge.UsedAt = now
+		}
+		if err := s.DB.UpdateAccountToken(challenge); err != nil {
+			return "", fmt.Errorf("failed to update login challenge: %w", err)
+		}
+		logger.Warn("Two-factor code rejected", zap.String("username", username))
+		s.audit(models.AuditTwoFactorFailed, username, ip, "")
+		if s.Limiter != nil {
+			if lockedUntil, locked := s.Limiter.Fail(username); locked {
+				s.audit(models.AuditAccountLocked, username, ip, "locked until "+lockedUntil.Format(time.RFC3339))
+			}
+		}
+		return "", ErrInvalidTwoFactorCode
+	}
+
+	challenge.UsedAt = now
+	if err := s.DB.UpdateAccountToken(challenge); err != nil {
+		return "", fmt.Errorf("failed to use login challenge: %w", err)
+	}
+	if s.Limiter != nil {
+		s.Limiter.Succeed(username)
+	}
+	if recovery {
+		s.audit(models.AuditRecoveryCodeUsed, username, ip, fmt.Sprintf("%d recovery codes left", len(user.TwoFactor.RecoveryCodes)))
+	}
+	s.audit(models.AuditLoginSucceeded, username, ip, "two-factor")
+
+	logger.Info("Two-factor login completed", zap.String("username", username), zap.Bool("recoveryCode", recovery))
+	return username, nil
+}
+
+func (s *TwoFactorService) audit(eventType, username, ip, detail string) {
+	recordAudit(s.DB, s.now(), eventType, username, ip, "", detail)
+}
+
+// checkCode accepts a code from the authenticator app or uses up a recovery
+// code of the user. It reports whether it was a recovery code.
+func checkCode(user *database.User, code string, now time.Time) (recovery bool, ok bool) {
+	if checkTOTP(user, code, now) {
+		return false, true
+	}
+	hash := utils.HashToken(utils.NormalizeRecoveryCode(code))
+	for i, stored := range user.TwoFactor.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			// Cloned, the stored user shares the slice
+			user.TwoFactor.RecoveryCodes = slices.Delete(slices.Clone(user.TwoFactor.RecoveryCodes), i, i+1)
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// checkTOTP accepts a code from the authenticator app of the user, each code
+// once.
+func checkTOTP(user *database.User, code string, now time.Time) bool {
+	step, ok := utils.ValidateTOTP(user.TwoFactor.Secret, strings.TrimSpace(code), now, totpSkew)
+	if !ok || step <= user.TwoFactor.LastStep {
+		return false
+	}
+	user.TwoFactor.LastStep = step
+	return true
+}
+
+// newRecoveryCodes returns a set of recovery codes with their hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+var _ ITwoFactorService = &TwoFactorService{}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/Dzsodie/quiz_app/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// enableTwoFactor turns two-factor authentication on for alice and returns
+// the secret and the recovery codes.
+func enableTwoFactor(t *testing.T, s *TwoFactorService, now *time.Time) (string, []string) {
+	t.Helper()
+	enrollment, err := s.Enroll("alice")
+	assert.NoError(t, err)
+	codes, err := s.Confirm("alice", totpAt(t, enrollment.Secret, *now), "")
+	assert.NoError(t, err)
+	*now = now.Add(utils.TOTPPeriod)
+	return enrollment.Secret, codes.RecoveryCodes
+}
+
+func totpAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(at))
+	assert.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	authService := newTestAuthService(t, "alice")
+	clock, now := newTestClock()
+	authService.Clock = clock
+	s := NewTwoFactorService(authService.DB)
+	s.Clock = clock
+
+	_, err := s.Confirm("alice", "123456", "")
+	assert.ErrorIs(t, err, ErrNoTwoFactorEnrollment)
+
+	enrollment, err := s.Enroll("alice")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Quiz%20App:alice?"))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	_, err = s.Confirm("alice", "000000", "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	status, _ := s.Status("alice")
+	assert.False(t, status.Enabled, "expected two-factor authentication to stay off until confirmed")
+
+	// Logins from before two-factor authentication was on are logged out
+	t.Cleanup(func() { utils.RevokeUserSessions("alice") })
+	assert.NoError(t, utils.SaveSession("alice-enrolling", "alice", "", ""))
+	assert.NoError(t, utils.SaveSession("alice-other", "alice", "", ""))
+	code := totpAt(t, enrollment.Secret, *now)
+	codes, err := s.Confirm("alice", code, "alice-enrolling")
+	assert.NoError(t, err)
+	_, exists := utils.LookupSession("alice-other")
+	assert.False(t, exists, "expected the other session to be logged out")
+	_, exists = utils.LookupSession("alice-enrolling")
+	assert.True(t, exists, "expected the enrolling session to stay")
+	assert.Len(t, codes.RecoveryCodes, recoveryCodeCount)
+	status, _ = s.Status("alice")
+	assert.Equal(t, models.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: recoveryCodeCount}, *status)
+
+	_, err = s.Enroll("alice")
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+
+	// A code works once
+	_, err = s.RegenerateRecoveryCodes("alice", code)
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	*now = now.Add(utils.TOTPPeriod)
+	regenerated, err := s.RegenerateRecoveryCodes("alice", totpAt(t, enrollment.Secret, *now))
+	assert.NoError(t, err)
+	assert.NotEqual(t, codes.RecoveryCodes, regenerated.RecoveryCodes)
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	authService := newTestAuthService(t, "alice")
+	clock, now := newTestClock()
+	authService.Clock = clock
+	s := NewTwoFactorService(authService.DB)
+	s.Clock = clock
+
+	challenge, err := s.StartLogin("alice")
+	assert.NoError(t, err)
+	assert.Nil(t, challenge, "expected no challenge without two-factor authentication")
+
+	secret, recoveryCodes := enableTwoFactor(t, s, now)
+	challenge, err = s.StartLogin("alice")
+	assert.NoError(t, err)
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.Equal(t, now.Add(DefaultLoginChallengeTTL), challenge.ExpiresAt)
+
+	_, err = s.CompleteLogin(challenge.ChallengeToken, "000000", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	username, err := s.CompleteLogin(challenge.ChallengeToken, totpAt(t, secret, *now), "192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", username)
+	_, err = s.CompleteLogin(challenge.ChallengeToken, totpAt(t, secret, *now), "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidLoginChallenge, "expected a challenge to work once")
+
+	// Recovery codes work once, however they are typed
+	challenge, _ = s.StartLogin("alice")
+	_, err = s.CompleteLogin(challenge.ChallengeToken, strings.ToUpper(recoveryCodes[0]), "192.0.2.1")
+	assert.NoError(t, err)
+	challenge, _ = s.StartLogin("alice")
+	_, err = s.CompleteLogin(challenge.ChallengeToken, recoveryCodes[0], "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	status, _ := s.Status("alice")
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesLeft)
+
+	// Challenges expire
+	challenge, _ = s.StartLogin("alice")
+	*now = now.Add(DefaultLoginChallengeTTL)
+	_, err = s.CompleteLogin(challenge.ChallengeToken, totpAt(t, secret, *now), "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidLoginChallenge)
+
+	// and are used up by wrong codes
+	challenge, _ = s.StartLogin("alice")
+	for i := 0; i < maxChallengeAttempts; i++ {
+		_, err = s.CompleteLogin(challenge.ChallengeToken, "000000", "192.0.2.1")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+	_, err = s.CompleteLogin(challenge.ChallengeToken, totpAt(t, secret, *now), "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidLoginChallenge)
+}
+
+func TestTwoFactorLoginLimits(t *testing.T) {
+	authService := newTestAuthService(t, "alice")
+	clock, now := newTestClock()
+	authService.Clock = clock
+	s := NewTwoFactorService(authService.DB)
+	s.Clock = clock
+	limiter, _ := newTestLimiter(LoginLimitConfig{LockoutThreshold: 3, LockoutDuration: 15 * time.Minute})
+	limiter.Clock = s.Clock
+	s.Limiter = limiter
+	authService.Limiter = limiter
+	secret, _ := enableTwoFactor(t, s, now)
+
+	// The password does not forgive the wrong codes before it
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, authService.Login("alice", "Password123!", "192.0.2.1"))
+		challenge, _ := s.StartLogin("alice")
+		_, err := s.CompleteLogin(challenge.ChallengeToken, "000000", "192.0.2.1")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+	assert.NoError(t, authService.Login("alice", "Password123!", "192.0.2.1"))
+	challenge, _ := s.StartLogin("alice")
+	_, err := s.CompleteLogin(challenge.ChallengeToken, "000000", "192.0.2.1")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	_, err = s.CompleteLogin(challenge.ChallengeToken, totpAt(t, secret, *now), "192.0.2.1")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.ErrorIs(t, authService.Login("alice", "Password123!", "192.0.2.1"), ErrAccountLocked)
+
+	var types []string
+	for _, event := range authService.ListAuditEvents("alice", 3) {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{models.AuditLoginThrottled, models.AuditLoginThrottled, models.AuditAccountLocked}, types)
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	authService := newTestAuthService(t, "alice")
+	clock, now := newTestClock()
+	authService.Clock = clock
+	s := NewTwoFactorService(authService.DB)
+	s.Clock = clock
+	_, recoveryCodes := enableTwoFactor(t, s, now)
+
+	assert.ErrorIs(t, s.Disable("alice", "000000"), ErrInvalidTwoFactorCode)
+
+	// Admins may not turn it off when it is required
+	assert.NoError(t, authService.SetRole("alice", models.RoleAdmin))
+	s.RequireForAdmins = true
+	status, _ := s.Status("alice")
+	assert.True(t, status.Required)
+	assert.ErrorIs(t, s.Disable("alice", recoveryCodes[0]), ErrTwoFactorRequired)
+
+	s.RequireForAdmins = false
+	assert.NoError(t, s.Disable("alice", recoveryCodes[0]))
+	status, _ = s.Status("alice")
+	assert.Equal(t, models.TwoFactorStatus{}, *status)
+	assert.ErrorIs(t, s.Disable("alice", recoveryCodes[1]), ErrTwoFactorNotEnabled)
+}
+
+func TestTokenLoginWithTwoFactor(t *testing.T) {
+	assert.NoError(t, utils.SetJWTKeys([]utils.JWTKey{{ID: "test", Secret: []byte("test-secret")}}))
+	authService := newTestAuthService(t, "alice")
+	clock, now := newTestClock()
+	authService.Clock = clock
+	s := NewTwoFactorService(authService.DB)
+	s.Clock = clock
+	tokens := NewTokenService(s.DB, authService)
+	tokens.Clock = s.Clock
+	tokens.TwoFactor = s
+	before, _, err := tokens.Login("alice", "Password123!", "")
+	assert.NoError(t, err)
+	secret, _ := enableTwoFactor(t, s, now)
+	_, err = tokens.Refresh(before.RefreshToken)
+	assert.Error(t, err, "expected tokens from before two-factor authentication to be revoked")
+	_, err = utils.ParseAccessToken(before.AccessToken)
+	assert.ErrorIs(t, err, utils.ErrTokenRevoked)
+
+	pair, challenge, err := tokens.Login("alice", "Password123!", "")
+	assert.NoError(t, err)
+	assert.Nil(t, pair, "expected no tokens before the second step")
+	if assert.NotNil(t, challenge) {
+		pair, err = tokens.CompleteLogin(challenge.ChallengeToken, totpAt(t, secret, *now), "")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, pair.AccessToken)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"go.uber.org/zap"
+)
+
+// ReadAchievements loads achievement definitions from a JSON file.
+func ReadAchievements(filename string) ([]models.Achievement, error) {
+	logger := GetLogger().Sugar()
+
+	logger.Info("Opening achievements file", zap.String("filename", filename))
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		logger.Error("Failed to open file", zap.String("filename", filename), zap.Error(err))
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	var achievements []models.Achievement
+	if err := json.Unmarshal(data, &achievements); err != nil {
+		logger.Error("Failed to parse achievements file", zap.String("filename", filename), zap.Error(err))
+		return nil, fmt.Errorf("failed to parse achievements file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, achievement := range achievements {
+		if achievement.AchievementID == "" {
+			return nil, fmt.Errorf("achievement %d has no achievement_id", i+1)
+		}
+		if seen[achievement.AchievementID] {
+			return nil, fmt.Errorf("achievement %s is defined twice", achievement.AchievementID)
+		}
+		seen[achievement.AchievementID] = true
+
+		switch achievement.Rule {
+		case models.RulePerfectScore:
+		case models.RuleQuizzesCompleted, models.RuleCorrectAnswers, models.RuleDayStreak:
+			if achievement.Count < 1 {
+				return nil, fmt.Errorf("achievement %s needs a positive count", achievement.AchievementID)
+			}
+		case models.RuleTopicMastered:
+			if achievement.Category == "" && achievement.Tag == "" {
+				return nil, fmt.Errorf("achievement %s needs a category or a tag", achievement.AchievementID)
+			}
+		default:
+			return nil, fmt.Errorf("achievement %s has an unknown rule: %s", achievement.AchievementID, achievement.Rule)
+		}
+	}
+
+	logger.Info("Achievements file processed successfully", zap.String("filename", filename), zap.Int("total_achievements", len(achievements)))
+	return achievements, nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+)
+
+func TestReadAchievements(t *testing.T) {
+	t.Run("Valid achievements file", func(t *testing.T) {
+		testFile := "test_achievements.json"
+		createTestFile(t, testFile, `[{"achievement_id":"perfect","title":"Perfect","rule":"perfect_score"},{"achievement_id":"jedi","title":"Jedi","rule":"topic_mastered","tag":"star wars"}]`)
+		defer os.Remove(testFile)
+
+		achievements, err := ReadAchievements(testFile)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(achievements) != 2 || achievements[1].Rule != models.RuleTopicMastered || achievements[1].Tag != "star wars" {
+			t.Errorf("Unexpected achievements: %+v", achievements)
+		}
+	})
+
+	t.Run("Invalid definitions", func(t *testing.T) {
+		tests := map[string]string{
+			`[{"achievement_id":"a","rule":"speedrun"}]`:                                                    "unknown rule",
+			`[{"achievement_id":"a","rule":"day_streak"}]`:                                                  "positive count",
+			`[{"achievement_id":"a","rule":"topic_mastered"}]`:                                              "category or a tag",
+			`[{"achievement_id":"a","rule":"perfect_score"},{"achievement_id":"a","rule":"perfect_score"}]`: "defined twice",
+		}
+		testFile := "test_achievements_invalid.json"
+		defer os.Remove(testFile)
+		for content, expected := range tests {
+			createTestFile(t, testFile, content)
+			_, err := ReadAchievements(testFile)
+			if err == nil || !contains(err.Error(), expected) {
+				t.Errorf("Expected error containing '%s', got: %v", expected, err)
+			}
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/Dzsodie/quiz_app/internal/models"
 	"go.uber.org/zap"
@@ -53,12 +54,39 @@ func ReadCSV(filename string) ([]models.Question, error) {
 			return nil, fmt.Errorf("invalid answer format in record %v: %w", record, err)
 		}
 
-		questions = append(questions, models.Question{
+		question := models.Question{
 			QuestionID: i,
 			Question:   record[1],
 			Options:    record[2:5],
 			Answer:     answer,
-		})
+		}
+		if len(record) > 6 {
+			question.Hint = record[6]
+		}
+		if len(record) > 7 {
+			question.Explanation = record[7]
+		}
+		if len(record) > 8 && record[8] != "" {
+			difficulty, err := strconv.ParseFloat(record[8], 64)
+			if err != nil {
+				logger.Warn("Invalid difficulty in record", zap.String("filename", filename), zap.Int("line", i+1), zap.Any("record", record), zap.Error(err))
+				return nil, fmt.Errorf("invalid difficulty in record %v: %w", record, err)
+			}
+			question.Difficulty = difficulty
+		}
+		if len(record) > 9 {
+			question.Category = strings.TrimSpace(record[9])
+		}
+		if len(record) > 10 && record[10] != "" {
+			// Tags share one column, separated by semicolons
+			for _, tag := range strings.Split(record[10], ";") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					question.Tags = append(question.Tags, tag)
+				}
+			}
+		}
+
+		questions = append(questions, question)
 		logger.Debug("Processed record", zap.String("filename", filename), zap.Int("line", i+1), zap.Any("question", record[1]))
 	}
 
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"reflect"
 	"testing"
 
 	"strings"
@@ -43,6 +44,64 @@ func TestReadCSV(t *testing.T) {
 		}
 	})
 
+	t.Run("CSV file with hints", func(t *testing.T) {
+		testCSV := "test_hints.csv"
+		content := `ID,Question,Option1,Option2,Option3,Answer,Hint
+1,What is 2+2?,1,2,4,3,Two pairs`
+
+		createTestFile(t, testCSV, content)
+		defer os.Remove(testCSV)
+
+		questions, err := ReadCSV(testCSV)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(questions) != 1 || questions[0].Hint != "Two pairs" {
+			t.Errorf("Expected hint to be read, got %+v", questions)
+		}
+	})
+
+	t.Run("CSV file with difficulty", func(t *testing.T) {
+		testCSV := "test_difficulty.csv"
+		content := `ID,Question,Option1,Option2,Option3,Answer,Hint,Explanation,Difficulty
+1,What is 2+2?,1,2,4,3,,,-1.5
+2,What is 2*3?,5,6,7,2,,,abc`
+
+		createTestFile(t, testCSV, content)
+		defer os.Remove(testCSV)
+
+		_, err := ReadCSV(testCSV)
+		if err == nil || !contains(err.Error(), "invalid difficulty") {
+			t.Errorf("Expected error containing 'invalid difficulty', got: %v", err)
+		}
+
+		createTestFile(t, testCSV, content[:strings.LastIndex(content, "\n")])
+		questions, err := ReadCSV(testCSV)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(questions) != 1 || questions[0].Difficulty != -1.5 {
+			t.Errorf("Expected difficulty to be read, got %+v", questions)
+		}
+	})
+
+	t.Run("CSV file with category and tags", func(t *testing.T) {
+		testCSV := "test_topics.csv"
+		content := `ID,Question,Option1,Option2,Option3,Answer,Hint,Explanation,Difficulty,Category,Tags
+1,What is 2+2?,1,2,4,3,,,,math, arithmetic; ;basics`
+
+		createTestFile(t, testCSV, content)
+		defer os.Remove(testCSV)
+
+		questions, err := ReadCSV(testCSV)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if questions[0].Category != "math" || !reflect.DeepEqual(questions[0].Tags, []string{"arithmetic", "basics"}) {
+			t.Errorf("Expected category and tags to be read, got %+v", questions[0])
+		}
+	})
+
 	t.Run("Missing file", func(t *testing.T) {
 		_, err := ReadCSV("nonexistent.csv")
 		if err == nil || !contains(err.Error(), "failed to open file") {
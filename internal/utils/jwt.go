@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+// JWTKey is an HMAC key that signs access tokens. The ID goes in the kid
+// header so that tokens signed before a key rotation can still be checked.
+type JWTKey struct {
+	ID     string
+	Secret []byte
+}
+
+// AccessClaims are the claims of an access token.
+type AccessClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	// Generation is the revocation count of the user when the token was issued
+	Generation int `json:"gen,omitempty"`
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+var (
+	// AccessTokenTTL is how long an access token is valid after it is issued
+	AccessTokenTTL = 15 * time.Minute
+	// JWTIssuer is the iss claim of the access tokens
+	JWTIssuer = "quiz_app"
+
+	jwtMu sync.RWMutex
+	// jwtKeys holds the keys by ID, signingKey is the one new tokens use
+	jwtKeys    = map[string][]byte{}
+	signingKey string
+	// revokedTokens holds the revoked access token IDs until they expire
+	revokedTokens = map[string]time.Time{}
+	// userGenerations counts how often every token of a user was revoked;
+	// tokens from an older generation are revoked
+	userGenerations = map[string]int{}
+)
+
+// ParseJWTKeys reads keys written as kid:secret separated by commas. The
+// first key signs new tokens, the others are only used to check tokens.
+func ParseJWTKeys(spec string) ([]JWTKey, error) {
+	var keys []JWTKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, secret, found := strings.Cut(part, ":")
+		if !found || id == "" || secret == "" {
+			return nil, fmt.Errorf("JWT key %q must be written as kid:secret", part)
+		}
+		keys = append(keys, JWTKey{ID: id, Secret: []byte(secret)})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no JWT keys given")
+	}
+	return keys, nil
+}
+
+// SetJWTKeys replaces the keys. The first key signs new tokens.
+func SetJWTKeys(keys []JWTKey) error {
+	if len(keys) == 0 {
+		return errors.New("no JWT keys given")
+	}
+	byID := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if _, exists := byID[key.ID]; exists {
+			return fmt.Errorf("duplicate JWT key ID %q", key.ID)
+		}
+		byID[key.ID] = key.Secret
+	}
+
+	jwtMu.Lock()
+	defer jwtMu.Unlock()
+	jwtKeys = byID
+	signingKey = keys[0].ID
+	return nil
+}
+
+// IssueAccessToken signs an access token for the user with the current key.
+func IssueAccessToken(username string) (string, AccessClaims, error) {
+	jwtMu.RLock()
+	keyID := signingKey
+	secret := jwtKeys[keyID]
+	generation := userGenerations[username]
+	jwtMu.RUnlock()
+	if keyID == "" {
+		return "", AccessClaims{}, errors.New("JWT keys are not set")
+	}
+
+	now := sessionNow()
+	claims := AccessClaims{
+		Issuer:     JWTIssuer,
+		Subject:    username,
+		ID:         uuid.New().String(),
+		IssuedAt:   now.Unix(),
+		ExpiresAt:  now.Add(AccessTokenTTL).Unix(),
+		Generation: generation,
+	}
+	header, err := json.Marshal(jwtHeader{Algorithm: "HS256", Type: "JWT", KeyID: keyID})
+	if err != nil {
+		return "", AccessClaims{}, err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", AccessClaims{}, err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(secret, unsigned)), claims, nil
+}
+
+// ParseAccessToken checks the signature, the expiry and the revocation of an
+// access token and returns its claims.
+func ParseAccessToken(token string) (AccessClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return AccessClaims{}, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Algorithm != "HS256" {
+		return AccessClaims{}, ErrInvalidToken
+	}
+	jwtMu.RLock()
+	secret, known := jwtKeys[header.KeyID]
+	jwtMu.RUnlock()
+	if !known {
+		return AccessClaims{}, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0]+"."+parts[1])) {
+		return AccessClaims{}, ErrInvalidToken
+	}
+
+	var claims AccessClaims
+	if err := decodeSegment(parts[1], &claims); err != nil || claims.Subject == "" || claims.Issuer != JWTIssuer {
+		return AccessClaims{}, ErrInvalidToken
+	}
+	now := sessionNow()
+	if now.Unix() >= claims.ExpiresAt {
+		return AccessClaims{}, ErrTokenExpired
+	}
+
+	jwtMu.RLock()
+	defer jwtMu.RUnlock()
+	if _, revoked := revokedTokens[claims.ID]; revoked {
+		return AccessClaims{}, ErrTokenRevoked
+	}
+	if claims.Generation < userGenerations[claims.Subject] {
+		return AccessClaims{}, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// RevokeAccessToken stops an access token from working before it expires.
+func RevokeAccessToken(claims AccessClaims) {
+	jwtMu.Lock()
+	defer jwtMu.Unlock()
+
+	now := sessionNow()
+	for id, expiresAt := range revokedTokens {
+		if !now.Before(expiresAt) {
+			delete(revokedTokens, id)
+		}
+	}
+	revokedTokens[claims.ID] = time.Unix(claims.ExpiresAt, 0)
+}
+
+// RevokeUserAccessTokens stops every access token issued to the user so far.
+func RevokeUserAccessTokens(username string) {
+	jwtMu.Lock()
+	defer jwtMu.Unlock()
+	userGenerations[username]++
+}
+
+// BearerToken returns the token of an Authorization: Bearer header.
+func BearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func sign(secret []byte, unsigned string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
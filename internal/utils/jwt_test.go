@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJWTKeys(t *testing.T) {
+	keys, err := ParseJWTKeys("new:secret-2, old:secret-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []JWTKey{{ID: "new", Secret: []byte("secret-2")}, {ID: "old", Secret: []byte("secret-1")}}, keys)
+
+	_, err = ParseJWTKeys("secret-without-id")
+	assert.Error(t, err)
+	_, err = ParseJWTKeys("")
+	assert.Error(t, err)
+}
+
+func TestAccessTokens(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	sessionNow = func() time.Time { return now }
+	t.Cleanup(func() { sessionNow = time.Now })
+	assert.NoError(t, SetJWTKeys([]JWTKey{{ID: "k1", Secret: []byte("first")}}))
+
+	token, issued, err := IssueAccessToken("alice")
+	assert.NoError(t, err)
+	claims, err := ParseAccessToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, issued, claims)
+	assert.Equal(t, "alice", claims.Subject)
+
+	parts := strings.Split(token, ".")
+	_, err = ParseAccessToken(parts[0] + "." + parts[1] + ".c2lnbmF0dXJl")
+	assert.ErrorIs(t, err, ErrInvalidToken, "expected a forged signature to fail")
+
+	// Rotating the keys keeps the tokens of the old key valid while it is listed
+	assert.NoError(t, SetJWTKeys([]JWTKey{{ID: "k2", Secret: []byte("second")}, {ID: "k1", Secret: []byte("first")}}))
+	_, err = ParseAccessToken(token)
+	assert.NoError(t, err)
+	rotated, _, err := IssueAccessToken("alice")
+	assert.NoError(t, err)
+	assert.NotEqual(t, parts[0], strings.Split(rotated, ".")[0], "expected the new key ID in the header")
+	assert.NoError(t, SetJWTKeys([]JWTKey{{ID: "k2", Secret: []byte("second")}}))
+	_, err = ParseAccessToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "expected tokens of a retired key to fail")
+
+	claims, err = ParseAccessToken(rotated)
+	assert.NoError(t, err)
+	RevokeAccessToken(claims)
+	_, err = ParseAccessToken(rotated)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	other, _, _ := IssueAccessToken("alice")
+	RevokeUserAccessTokens("alice")
+	_, err = ParseAccessToken(other)
+	assert.ErrorIs(t, err, ErrTokenRevoked, "expected logging out everywhere to revoke the token")
+	later, _, _ := IssueAccessToken("alice")
+	_, err = ParseAccessToken(later)
+	assert.NoError(t, err, "expected tokens issued after the revocation to work")
+
+	now = now.Add(AccessTokenTTL)
+	_, err = ParseAccessToken(later)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestBearerToken(t *testing.T) {
+	token, ok := BearerToken("Bearer abc.def.ghi")
+	assert.True(t, ok)
+	assert.Equal(t, "abc.def.ghi", token)
+	_, ok = BearerToken("Basic dXNlcjpwYXNz")
+	assert.False(t, ok)
+	_, ok = BearerToken("Bearer ")
+	assert.False(t, ok)
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail", "outbox.log")
+	mailer := &FileMailer{Path: path}
+
+	assert.NoError(t, mailer.Send(Email{To: "alice@example.com", Subject: "Hello", Body: "First"}))
+	assert.NoError(t, mailer.Send(Email{To: "bob@example.com", Subject: "Hello", Body: "Second"}))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "To: alice@example.com")
+	assert.Contains(t, string(data), "Second", "expected the emails to be appended")
+	assert.NoError(t, (&FileMailer{}).Send(Email{To: "alice@example.com"}), "expected a mailer without a path to only log")
+}
+
+func TestNewMailer(t *testing.T) {
+	mailer, err := NewMailer(config.Config{MailBackend: "file", MailFilePath: "mail.log"})
+	assert.NoError(t, err)
+	assert.IsType(t, &FileMailer{}, mailer)
+
+	mailer, err = NewMailer(config.Config{MailBackend: "smtp", SMTPHost: "smtp.example.com", SMTPPort: 587, MailFrom: "quiz@example.com"})
+	assert.NoError(t, err)
+	assert.IsType(t, &SMTPMailer{}, mailer)
+
+	_, err = NewMailer(config.Config{MailBackend: "smtp"})
+	assert.Error(t, err, "expected the SMTP host to be required")
+	_, err = NewMailer(config.Config{MailBackend: "pigeon"})
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"log"
 
 	"go.uber.org/zap"
@@ -38,3 +40,10 @@ func ComparePassword(hashedPassword, plainPassword string) bool {
 	logger.Info("Password comparison succeeded")
 	return true
 }
+
+// HashToken hashes a random secret such as a refresh token for storage. The
+// secrets are long enough that a fast hash is safe, unlike with passwords.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"go.uber.org/zap"
+)
+
+// ReadQuizzes loads quiz definitions from a JSON file.
+func ReadQuizzes(filename string) ([]models.Quiz, error) {
+	logger := GetLogger().Sugar()
+
+	logger.Info("Opening quizzes file", zap.String("filename", filename))
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		logger.Error("Failed to open file", zap.String("filename", filename), zap.Error(err))
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	var quizzes []models.Quiz
+	if err := json.Unmarshal(data, &quizzes); err != nil {
+		logger.Error("Failed to parse quizzes file", zap.String("filename", filename), zap.Error(err))
+		return nil, fmt.Errorf("failed to parse quizzes file: %w", err)
+	}
+
+	for i, quiz := range quizzes {
+		if quiz.QuizID == "" {
+			return nil, fmt.Errorf("quiz %d has no quiz_id", i+1)
+		}
+		switch quiz.Mode {
+		case "", models.QuizModeStandard, models.QuizModePractice, models.QuizModeExam:
+		default:
+			return nil, fmt.Errorf("quiz %s has an unknown mode: %s", quiz.QuizID, quiz.Mode)
+		}
+		switch quiz.ScoringPolicy {
+		case "", models.ScoreBest, models.ScoreLast, models.ScoreAverage:
+		default:
+			return nil, fmt.Errorf("quiz %s has an unknown scoring policy: %s", quiz.QuizID, quiz.ScoringPolicy)
+		}
+		switch quiz.Strategy {
+		case "", models.StrategySequential, models.StrategyAdaptive:
+		default:
+			return nil, fmt.Errorf("quiz %s has an unknown question strategy: %s", quiz.QuizID, quiz.Strategy)
+		}
+		if quiz.OpensAt != nil && quiz.ClosesAt != nil && !quiz.ClosesAt.After(*quiz.OpensAt) {
+			return nil, fmt.Errorf("quiz %s closes before it opens", quiz.QuizID)
+		}
+	}
+
+	logger.Info("Quizzes file processed successfully", zap.String("filename", filename), zap.Int("total_quizzes", len(quizzes)))
+	return quizzes, nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Dzsodie/quiz_app/internal/models"
+)
+
+func TestReadQuizzes(t *testing.T) {
+	t.Run("Valid quizzes file", func(t *testing.T) {
+		testFile := "test_quizzes.json"
+		createTestFile(t, testFile, `[{"quiz_id":"exam","title":"Exam","mode":"exam","max_attempts":2}]`)
+		defer os.Remove(testFile)
+
+		quizzes, err := ReadQuizzes(testFile)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(quizzes) != 1 || quizzes[0].Mode != models.QuizModeExam || quizzes[0].MaxAttempts != 2 {
+			t.Errorf("Unexpected quizzes: %+v", quizzes)
+		}
+	})
+
+	t.Run("Unknown mode", func(t *testing.T) {
+		testFile := "test_quizzes_mode.json"
+		createTestFile(t, testFile, `[{"quiz_id":"quiz","mode":"speedrun"}]`)
+		defer os.Remove(testFile)
+
+		_, err := ReadQuizzes(testFile)
+		if err == nil || !contains(err.Error(), "unknown mode") {
+			t.Errorf("Expected error containing 'unknown mode', got: %v", err)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := ReadQuizzes("nonexistent.json")
+		if err == nil || !contains(err.Error(), "failed to open file") {
+			t.Errorf("Expected error containing 'failed to open file', got: %v", err)
+		}
+	})
+}
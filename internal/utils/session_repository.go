@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository keeps the sessions on the server. Implementations must be
+// safe for concurrent use.
+type SessionRepository interface {
+	Save(session models.Session) error
+	// Get returns ErrSessionNotFound for an unknown token
+	Get(token string) (models.Session, error)
+	// Touch records that the session was used at seenAt
+	Touch(token string, seenAt time.Time) error
+	Delete(token string) error
+	// DeleteUser removes every session of the user and returns how many there were
+	DeleteUser(username string) (int, error)
+	// ListUser returns the sessions of the user, the oldest first
+	ListUser(username string) ([]models.Session, error)
+	// DeleteExpired removes the sessions that expired by now and returns how many there were
+	DeleteExpired(now time.Time) (int, error)
+}
+
+// MemorySessionRepository keeps the sessions in a map guarded by a mutex.
+// Sessions are lost when the process exits.
+type MemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]models.Session
+}
+
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{sessions: make(map[string]models.Session)}
+}
+
+func (r *MemorySessionRepository) Save(session models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.Token] = session
+	return nil
+}
+
+func (r *MemorySessionRepository) Get(token string) (models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, exists := r.sessions[token]
+	if !exists {
+		return models.Session{}, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (r *MemorySessionRepository) Touch(token string, seenAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[token]
+	if !exists {
+		return ErrSessionNotFound
+	}
+	session.LastSeenAt = seenAt
+	r.sessions[token] = session
+	return nil
+}
+
+func (r *MemorySessionRepository) Delete(token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, token)
+	return nil
+}
+
+func (r *MemorySessionRepository) DeleteUser(username string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for token, session := range r.sessions {
+		if session.Username == username {
+			delete(r.sessions, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *MemorySessionRepository) ListUser(username string) ([]models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.Username == username {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (r *MemorySessionRepository) DeleteExpired(now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for token, session := range r.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(r.sessions, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DBSessionRepository keeps the sessions in the database with the users, so
+// that they live as long as the data does.
+type DBSessionRepository struct {
+	DB *database.MemoryDB
+}
+
+func NewDBSessionRepository(db *database.MemoryDB) *DBSessionRepository {
+	return &DBSessionRepository{DB: db}
+}
+
+func (r *DBSessionRepository) Save(session models.Session) error {
+	return r.DB.SaveSession(database.Session(session))
+}
+
+func (r *DBSessionRepository) Get(token string) (models.Session, error) {
+	session, err := r.DB.GetSession(token)
+	if errors.Is(err, database.ErrSessionNotFound) {
+		return models.Session{}, ErrSessionNotFound
+	}
+	return models.Session(session), err
+}
+
+func (r *DBSessionRepository) Touch(token string, seenAt time.Time) error {
+	session, err := r.Get(token)
+	if err != nil {
+		return err
+	}
+	session.LastSeenAt = seenAt
+	// Updating never brings back a session deleted in the meantime
+	if err := r.DB.UpdateSession(database.Session(session)); errors.Is(err, database.ErrSessionNotFound) {
+		return ErrSessionNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *DBSessionRepository) Delete(token string) error {
+	if err := r.DB.DeleteSession(token); err != nil && !errors.Is(err, database.ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (r *DBSessionRepository) DeleteUser(username string) (int, error) {
+	sessions, err := r.ListUser(username)
+	if err != nil {
+		return 0, err
+	}
+	for _, session := range sessions {
+		if err := r.Delete(session.Token); err != nil {
+			return 0, err
+		}
+	}
+	return len(sessions), nil
+}
+
+func (r *DBSessionRepository) ListUser(username string) ([]models.Session, error) {
+	var sessions []models.Session
+	for _, session := range r.DB.ListSessions() {
+		if session.Username == username {
+			sessions = append(sessions, models.Session(session))
+		}
+	}
+	return sessions, nil
+}
+
+func (r *DBSessionRepository) DeleteExpired(now time.Time) (int, error) {
+	deleted := 0
+	for _, session := range r.DB.ListSessions() {
+		if now.Before(session.ExpiresAt) {
+			continue
+		}
+		if err := r.Delete(session.Token); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+var (
+	_ SessionRepository = &MemorySessionRepository{}
+	_ SessionRepository = &DBSessionRepository{}
+)
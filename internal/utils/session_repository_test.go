@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dzsodie/quiz_app/internal/database"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRepositories(t *testing.T) {
+	repositories := map[string]func() SessionRepository{
+		"memory":   func() SessionRepository { return NewMemorySessionRepository() },
+		"database": func() SessionRepository { return NewDBSessionRepository(database.NewMemoryDB()) },
+	}
+	for name, newRepository := range repositories {
+		t.Run(name, func(t *testing.T) {
+			r := newRepository()
+			now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+			for i, token := range []string{"token-2", "token-1", "token-3"} {
+				username := "alice"
+				if token == "token-3" {
+					username = "bob"
+				}
+				created := now.Add(-time.Duration(i) * time.Minute)
+				assert.NoError(t, r.Save(models.Session{Token: token, Username: username, CreatedAt: created, ExpiresAt: created.Add(time.Hour)}))
+			}
+
+			_, err := r.Get("unknown")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+			assert.ErrorIs(t, r.Touch("unknown", now), ErrSessionNotFound, "expected touching not to create a session")
+
+			assert.NoError(t, r.Touch("token-2", now.Add(time.Minute)))
+			session, err := r.Get("token-2")
+			assert.NoError(t, err)
+			assert.Equal(t, now.Add(time.Minute), session.LastSeenAt)
+
+			sessions, err := r.ListUser("alice")
+			assert.NoError(t, err)
+			if assert.Len(t, sessions, 2) {
+				assert.Equal(t, "token-1", sessions[0].Token, "expected the oldest session first")
+			}
+
+			// Only bob's session, created first, has expired by then
+			deleted, err := r.DeleteExpired(now.Add(58 * time.Minute))
+			assert.NoError(t, err)
+			assert.Equal(t, 1, deleted)
+			_, err = r.Get("token-3")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+
+			assert.NoError(t, r.Delete("token-1"))
+			assert.NoError(t, r.Delete("token-1"), "expected deleting twice to be harmless")
+			deleted, err = r.DeleteUser("alice")
+			assert.NoError(t, err)
+			assert.Equal(t, 1, deleted)
+			sessions, err = r.ListUser("alice")
+			assert.NoError(t, err)
+			assert.Empty(t, sessions)
+		})
+	}
+}
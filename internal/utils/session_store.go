@@ -5,21 +5,35 @@ import (
 	"encoding/hex"
 	"errors"
 	"log"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/Dzsodie/quiz_app/config"
+	"github.com/Dzsodie/quiz_app/internal/models"
+	"github.com/google/uuid"
 	"github.com/gorilla/sessions"
+	"go.uber.org/zap"
 )
 
 var (
 	SessionStore     *sessions.CookieStore
 	sessionStoreOnce sync.Once
-	SessionDB        = make(map[string]string) // Store session tokens
+	// SessionTTL is how long a session token is valid after login
+	SessionTTL = time.Hour
+	// Sessions keeps the session tokens on the server. It is swapped for a
+	// database backed repository at startup when configured.
+	Sessions SessionRepository = NewMemorySessionRepository()
+
+	sessionNow = time.Now
 )
 
-func InitializeSessionStore(config.Config) {
+func InitializeSessionStore(cfg config.Config) {
 	sessionStoreOnce.Do(func() {
+		if cfg.SessionTTLMinutes > 0 {
+			SessionTTL = time.Duration(cfg.SessionTTLMinutes) * time.Minute
+		}
 
 		if config.LoadConfig().SessionSecret == "" {
 			log.Fatal("Session secret is not set in the configuration")
@@ -32,7 +46,7 @@ func InitializeSessionStore(config.Config) {
 
 		SessionStore.Options = &sessions.Options{
 			Path:     "/",
-			MaxAge:   3600, // 1 hour
+			MaxAge:   int(SessionTTL.Seconds()),
 			HttpOnly: true,
 			Secure:   false,
 			SameSite: http.SameSiteStrictMode,
@@ -45,11 +59,138 @@ func GenerateSessionToken() (string, error) {
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
-	token := hex.EncodeToString(bytes)
+	return hex.EncodeToString(bytes), nil
+}
+
+// SaveSession makes a session token valid for the user for SessionTTL and
+// records where the login came from.
+func SaveSession(token, username, ip, userAgent string) error {
+	now := sessionNow()
+	return Sessions.Save(models.Session{
+		SessionID:  uuid.New().String(),
+		Token:      token,
+		Username:   username,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(SessionTTL),
+		IP:         ip,
+		UserAgent:  userAgent,
+	})
+}
+
+// LookupSession returns the user of a session token that is still valid and
+// marks the session as seen. Expired tokens are dropped.
+func LookupSession(token string) (string, bool) {
+	logger := GetLogger().Sugar()
+
+	session, err := Sessions.Get(token)
+	if err != nil {
+		if !errors.Is(err, ErrSessionNotFound) {
+			logger.Error("Failed to look up session", zap.Error(err))
+		}
+		return "", false
+	}
+	now := sessionNow()
+	if !now.Before(session.ExpiresAt) {
+		if err := Sessions.Delete(token); err != nil {
+			logger.Warn("Failed to drop expired session", zap.Error(err))
+		}
+		return "", false
+	}
+	if err := Sessions.Touch(token, now); err != nil {
+		// Revoked since the lookup above
+		return "", false
+	}
+	return session.Username, true
+}
 
-	// Save session token in SessionDB
-	SessionDB[token] = ""
-	return token, nil
+// RevokeSession invalidates a session token.
+func RevokeSession(token string) error {
+	return Sessions.Delete(token)
+}
+
+// RevokeUserSessions invalidates every session token of a user and returns
+// how many were still valid.
+func RevokeUserSessions(username string) (int, error) {
+	sessions, err := UserSessions(username)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := Sessions.DeleteUser(username); err != nil {
+		return 0, err
+	}
+	return len(sessions), nil
+}
+
+// RevokeOtherSessions invalidates every session token of a user but keepToken
+// and returns how many were still valid.
+func RevokeOtherSessions(username, keepToken string) (int, error) {
+	sessions, err := UserSessions(username)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if session.Token == keepToken {
+			continue
+		}
+		if err := Sessions.Delete(session.Token); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// UserSessions returns the sessions of a user that are still valid, the
+// oldest first.
+func UserSessions(username string) ([]models.Session, error) {
+	sessions, err := Sessions.ListUser(username)
+	if err != nil {
+		return nil, err
+	}
+	now := sessionNow()
+	valid := sessions[:0]
+	for _, session := range sessions {
+		if now.Before(session.ExpiresAt) {
+			valid = append(valid, session)
+		}
+	}
+	return valid, nil
+}
+
+// StartSessionCleanup removes the expired sessions every interval until the
+// returned function is called.
+func StartSessionCleanup(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				deleted, err := Sessions.DeleteExpired(sessionNow())
+				if err != nil {
+					GetLogger().Sugar().Error("Failed to remove expired sessions", zap.Error(err))
+				} else if deleted > 0 {
+					GetLogger().Sugar().Debugf("Removed %d expired sessions", deleted)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// ClientIP returns the address the request came from without the port.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
 func ValidateSessionStore() error {
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionExpiryAndRevocation(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	sessionNow = func() time.Time { return now }
+	Sessions = NewMemorySessionRepository()
+	t.Cleanup(func() { sessionNow = time.Now })
+
+	assert.NoError(t, SaveSession("token-1", "alice", "10.0.0.1", "Phone"))
+	assert.NoError(t, SaveSession("token-2", "alice", "10.0.0.2", "Laptop"))
+	assert.NoError(t, SaveSession("token-3", "bob", "10.0.0.3", "Tablet"))
+
+	now = now.Add(time.Minute)
+	username, ok := LookupSession("token-1")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", username)
+	session, err := Sessions.Get("token-1")
+	assert.NoError(t, err)
+	assert.Equal(t, now, session.LastSeenAt, "expected the lookup to mark the session as seen")
+	assert.Equal(t, "10.0.0.1", session.IP)
+	assert.NotEmpty(t, session.SessionID)
+
+	assert.NoError(t, RevokeSession("token-1"))
+	_, ok = LookupSession("token-1")
+	assert.False(t, ok, "expected a revoked token to stop working")
+
+	revoked, err := RevokeUserSessions("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+	_, ok = LookupSession("token-2")
+	assert.False(t, ok)
+
+	now = now.Add(SessionTTL)
+	_, ok = LookupSession("token-3")
+	assert.False(t, ok, "expected the token to expire on the server whatever the cookie says")
+	revoked, err = RevokeUserSessions("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, revoked)
+}
+
+func TestSessionCleanup(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	sessionNow = func() time.Time { return now }
+	repository := NewMemorySessionRepository()
+	Sessions = repository
+	t.Cleanup(func() { sessionNow = time.Now })
+
+	assert.NoError(t, SaveSession("token-1", "alice", "", ""))
+	sessionNow = func() time.Time { return now.Add(SessionTTL) }
+
+	stop := StartSessionCleanup(time.Millisecond)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		sessions, _ := repository.ListUser("alice")
+		return len(sessions) == 0
+	}, time.Second, 5*time.Millisecond, "expected the expired session to be removed without a lookup")
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.7:51234"
+	assert.Equal(t, "192.0.2.7", ClientIP(req))
+	req.RemoteAddr = "192.0.2.7"
+	assert.Equal(t, "192.0.2.7", ClientIP(req))
+}
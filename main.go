@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/Dzsodie/quiz_app/cmd"
@@ -13,6 +18,7 @@ import (
 	"github.com/Dzsodie/quiz_app/internal/handlers"
 	"github.com/Dzsodie/quiz_app/internal/health"
 	"github.com/Dzsodie/quiz_app/internal/middleware"
+	"github.com/Dzsodie/quiz_app/internal/models"
 	"github.com/Dzsodie/quiz_app/internal/services"
 	"github.com/Dzsodie/quiz_app/internal/utils"
 	"github.com/google/uuid"
@@ -54,7 +60,7 @@ func (app *QuizApp) Run() {
 			sugar.Fatalf("Failed to create request: %v", err)
 		}
 		rr := httptest.NewRecorder()
-		quizHandler := handlers.NewQuizHandler(&services.QuizService{DB: app.DB})
+		quizHandler := handlers.NewQuizHandler(services.NewQuizService(app.DB))
 		quizHandler.StartQuiz(rr, req)
 		return
 	}
@@ -118,8 +124,110 @@ func initializeMockUsers(memoryDB *database.MemoryDB) {
 }
 
 func setupRESTAPIServer(cfg config.Config, sugar *zap.SugaredLogger, db *database.MemoryDB) {
-	quizService := &services.QuizService{DB: db}
+	switch cfg.SessionBackend {
+	case "memory":
+	case "database":
+		utils.Sessions = utils.NewDBSessionRepository(db)
+	default:
+		sugar.Fatalf("Unknown session backend %q, expected memory or database", cfg.SessionBackend)
+	}
+	jwtKeys := cfg.JWTKeys
+	if jwtKeys == "" {
+		jwtKeys = "default:" + cfg.SessionSecret
+	}
+	keys, err := utils.ParseJWTKeys(jwtKeys)
+	if err != nil {
+		sugar.Fatalf("Invalid JWT_KEYS: %v", err)
+	}
+	if err := utils.SetJWTKeys(keys); err != nil {
+		sugar.Fatalf("Invalid JWT_KEYS: %v", err)
+	}
+	utils.AccessTokenTTL = time.Duration(cfg.JWTAccessTTLMinutes) * time.Minute
+
+	if cfg.SessionCleanupMinutes > 0 {
+		stopSessionCleanup := utils.StartSessionCleanup(time.Duration(cfg.SessionCleanupMinutes) * time.Minute)
+		defer stopSessionCleanup()
+	}
+
+	quizService := services.NewQuizService(db)
+	quizService.Lifelines = services.LifelineConfig{
+		HintsPerAttempt:      cfg.HintLifelines,
+		FiftyFiftyPerAttempt: cfg.FiftyFiftyLifelines,
+		HintPenalty:          cfg.HintPenalty,
+		FiftyFiftyPenalty:    cfg.FiftyFiftyPenalty,
+	}
 	authService := &services.AuthService{DB: db}
+	authService.Limiter = services.NewLoginLimiter(services.LoginLimitConfig{
+		IPAttempts:       cfg.LoginIPAttempts,
+		IPWindow:         time.Duration(cfg.LoginIPWindowSeconds) * time.Second,
+		UserAttempts:     cfg.LoginUserAttempts,
+		UserWindow:       time.Duration(cfg.LoginUserWindowSeconds) * time.Second,
+		DelayAfter:       cfg.LoginDelayAfter,
+		BaseDelay:        time.Duration(cfg.LoginDelayBaseMillis) * time.Millisecond,
+		MaxDelay:         time.Duration(cfg.LoginDelayMaxSeconds) * time.Second,
+		LockoutThreshold: cfg.LoginLockoutThreshold,
+		LockoutDuration:  time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+	})
+	if cfg.AdminUsername != "" {
+		if err := authService.RegisterUser(cfg.AdminUsername, cfg.AdminPassword); err != nil {
+			sugar.Fatalf("Failed to register admin %s: %v", cfg.AdminUsername, err)
+		}
+		if err := authService.SetRole(cfg.AdminUsername, models.RoleAdmin); err != nil {
+			sugar.Fatalf("Failed to make %s an admin: %v", cfg.AdminUsername, err)
+		}
+	}
+	twoFactorService := services.NewTwoFactorService(db)
+	twoFactorService.Limiter = authService.Limiter
+	twoFactorService.Issuer = cfg.TOTPIssuer
+	twoFactorService.RequireForAdmins = cfg.TOTPRequiredForAdmins
+	tokenService := services.NewTokenService(db, authService)
+	tokenService.TwoFactor = twoFactorService
+	tokenService.RefreshTTL = time.Duration(cfg.JWTRefreshTTLHours) * time.Hour
+	mailer, err := utils.NewMailer(cfg)
+	if err != nil {
+		sugar.Fatalf("Failed to set up mail: %v", err)
+	}
+	accountService := services.NewAccountService(db, mailer)
+	accountService.ResetTTL = time.Duration(cfg.PasswordResetMinutes) * time.Minute
+	accountService.VerifyTTL = time.Duration(cfg.EmailVerifyHours) * time.Hour
+	accountService.Limiter = services.NewLoginLimiter(services.LoginLimitConfig{
+		IPAttempts:   cfg.LoginIPAttempts,
+		IPWindow:     time.Duration(cfg.LoginIPWindowSeconds) * time.Second,
+		UserAttempts: cfg.PasswordResetRequests,
+		UserWindow:   time.Duration(cfg.PasswordResetWindowMinutes) * time.Minute,
+	})
+	apiKeyService := services.NewAPIKeyService(db)
+	middleware.APIKeys = apiKeyService
+	studyService := services.NewStudyService(db)
+	leaderboardLocation, err := time.LoadLocation(cfg.LeaderboardTimezone)
+	if err != nil {
+		sugar.Warnf("Unknown leaderboard timezone %s, using UTC: %v", cfg.LeaderboardTimezone, err)
+		leaderboardLocation = time.UTC
+	}
+	quizService.Progression = services.ProgressionConfig{
+		XPPerAttempt:       cfg.XPPerAttempt,
+		XPPerCorrectAnswer: cfg.XPPerCorrectAnswer,
+		LevelBaseXP:        cfg.LevelBaseXP,
+		LevelGrowth:        cfg.LevelGrowth,
+		FreezeTokenEvery:   cfg.FreezeTokenEvery,
+		MaxFreezeTokens:    cfg.MaxFreezeTokens,
+		Location:           leaderboardLocation,
+	}
+	leaderboardService := services.NewLeaderboardService(db, leaderboardLocation)
+	quizService.AddAttemptListener(leaderboardService)
+	analyticsService := services.NewAnalyticsService(db)
+	progressService := services.NewProgressService(db)
+	progressService.Location = leaderboardLocation
+	progressService.Progression = quizService.Progression
+
+	achievements, err := utils.ReadAchievements(cfg.AchievementsFilePath)
+	if err != nil {
+		sugar.Warnf("No achievements loaded: %v", err)
+	}
+	achievementService := services.NewAchievementService(db, achievements)
+	achievementService.Location = leaderboardLocation
+	quizService.AddAttemptListener(achievementService)
+	quizService.AddAnswerListener(achievementService)
 
 	sugar.Info("Loading questions from CSV...")
 	questions, err := utils.ReadCSV(cfg.QuestionsFilePath)
@@ -129,32 +237,203 @@ func setupRESTAPIServer(cfg config.Config, sugar *zap.SugaredLogger, db *databas
 	sugar.Infof("Successfully loaded %d questions", len(questions))
 	quizService.LoadQuestions(questions)
 
-	r := setupRoutes(quizService, authService)
+	defaultQuiz := services.DefaultQuiz()
+	defaultQuiz.TimeLimitSeconds = cfg.QuizTimeLimitSeconds
+	defaultQuiz.PauseTimer = cfg.QuizPauseTimer
+	defaultQuiz.ResumePolicy = models.ResumePolicy(cfg.QuizResumePolicy)
+	if err := quizService.RegisterQuiz(defaultQuiz); err != nil {
+		sugar.Fatalf("Failed to register default quiz: %v", err)
+	}
+
+	quizzes, err := utils.ReadQuizzes(cfg.QuizzesFilePath)
+	if err != nil {
+		sugar.Warnf("No additional quizzes loaded: %v", err)
+	}
+	for _, quiz := range quizzes {
+		if err := quizService.RegisterQuiz(quiz); err != nil {
+			sugar.Fatalf("Failed to register quiz %s: %v", quiz.QuizID, err)
+		}
+	}
+	sugar.Infof("Successfully registered %d quizzes", len(quizService.ListQuizzes()))
 
-	sugar.Infof("Server is running on port %s...", cfg.ServerPort)
-	if err := http.ListenAndServe(cfg.ServerPort, r); err != nil {
-		sugar.Fatalf("Server failed: %v", err)
+	dailyService := services.NewDailyService(quizService)
+	dailyService.Location = leaderboardLocation
+	dailyService.Questions = cfg.DailyChallengeQuestions
+	if generateAt, err := time.Parse("15:04", cfg.DailyChallengeTime); err == nil {
+		dailyService.GenerateAt = time.Duration(generateAt.Hour())*time.Hour + time.Duration(generateAt.Minute())*time.Minute
+	} else {
+		sugar.Warnf("Invalid daily challenge time %s, generating at midnight: %v", cfg.DailyChallengeTime, err)
 	}
+	dailyService.Schedule()
+	defer dailyService.Stop()
+	roomService := services.NewRoomService(quizService)
+	roomService.IdleTTL = time.Duration(cfg.RoomIdleMinutes) * time.Minute
+	duelService := services.NewDuelService(quizService)
+	duelService.InviteTTL = time.Duration(cfg.DuelInviteHours) * time.Hour
+	duelService.SyncWindow = time.Duration(cfg.DuelSyncMinutes) * time.Minute
+	duelService.AsyncWindow = time.Duration(cfg.DuelAsyncHours) * time.Hour
+	duelService.KFactor = cfg.DuelRatingKFactor
+	quizService.AddAttemptListener(duelService)
+	teamService := services.NewTeamService(db, leaderboardService)
+	teamService.DefaultTopN = cfg.TeamTopN
+	tournamentService := services.NewTournamentService(quizService)
+	tournamentService.RoundWindow = time.Duration(cfg.TournamentRoundHours) * time.Hour
+	tournamentService.Questions = cfg.TournamentQuestions
+	quizService.AddAttemptListener(tournamentService)
+
+	r := setupRoutes(quizService, authService, studyService, leaderboardService, analyticsService, progressService, achievementService, dailyService, roomService, duelService, teamService, tournamentService, tokenService, apiKeyService, accountService, twoFactorService)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Addr: cfg.ServerPort, Handler: r}
+	go func() {
+		sugar.Infof("Server is running on port %s...", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			sugar.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	sugar.Info("Shutting down the server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		sugar.Warnf("Server did not shut down cleanly: %v", err)
+	}
+	// Reset emails are sent in the background after the response
+	accountService.Wait()
 }
 
-func setupRoutes(quizService *services.QuizService, authService *services.AuthService) *mux.Router {
+func setupRoutes(quizService *services.QuizService, authService *services.AuthService, studyService *services.StudyService, leaderboardService *services.LeaderboardService, analyticsService *services.AnalyticsService, progressService *services.ProgressService, achievementService *services.AchievementService, dailyService *services.DailyService, roomService *services.RoomService, duelService *services.DuelService, teamService *services.TeamService, tournamentService *services.TournamentService, tokenService *services.TokenService, apiKeyService *services.APIKeyService, accountService *services.AccountService, twoFactorService *services.TwoFactorService) *mux.Router {
 	r := mux.NewRouter()
 
 	quizHandler := handlers.NewQuizHandler(quizService)
+	quizHandler.Achievements = achievementService
 	authHandler := handlers.NewAuthHandler(authService)
+	authHandler.TwoFactor = twoFactorService
+	studyHandler := handlers.NewStudyHandler(studyService)
+	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	progressHandler := handlers.NewProgressHandler(progressService)
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	dailyHandler := handlers.NewDailyHandler(dailyService)
+	roomHandler := handlers.NewRoomHandler(roomService)
+	duelHandler := handlers.NewDuelHandler(duelService)
+	teamHandler := handlers.NewTeamHandler(teamService)
+	tournamentHandler := handlers.NewTournamentHandler(tournamentService)
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
 
 	r.HandleFunc("/register", authHandler.RegisterUser).Methods("POST")
 	r.HandleFunc("/login", authHandler.LoginUser).Methods("POST")
+	r.HandleFunc("/login/2fa", authHandler.CompleteLogin).Methods("POST")
+	r.Handle("/logout", middleware.AuthMiddleware(middleware.RejectAPIKeys(http.HandlerFunc(authHandler.Logout)))).Methods("POST")
+	r.Handle("/logout/all", middleware.AuthMiddleware(middleware.RejectAPIKeys(http.HandlerFunc(authHandler.LogoutAll)))).Methods("POST")
+	r.HandleFunc("/token", tokenHandler.IssueTokens).Methods("POST")
+	r.HandleFunc("/token/2fa", tokenHandler.CompleteTokenLogin).Methods("POST")
+	r.HandleFunc("/token/refresh", tokenHandler.RefreshTokens).Methods("POST")
+	r.HandleFunc("/token/revoke", tokenHandler.RevokeTokens).Methods("POST")
+	r.HandleFunc("/password/forgot", accountHandler.ForgotPassword).Methods("POST")
+	r.HandleFunc("/password/reset", accountHandler.ResetPassword).Methods("POST")
+	r.HandleFunc("/email/verify", accountHandler.VerifyEmail).Methods("POST")
 	r.HandleFunc("/questions", quizHandler.GetQuestions).Methods("GET")
+	r.HandleFunc("/quizzes", quizHandler.ListQuizzes).Methods("GET")
 
 	api := r.PathPrefix("/quiz").Subrouter()
-	api.Use(middleware.AuthMiddleware)
+	api.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
 	api.HandleFunc("/start", quizHandler.StartQuiz).Methods("POST")
 	api.HandleFunc("/next", quizHandler.NextQuestion).Methods("GET")
 	api.HandleFunc("/submit", quizHandler.SubmitAnswer).Methods("POST")
+	api.HandleFunc("/lifeline", quizHandler.UseLifeline).Methods("POST")
+	api.HandleFunc("/pause", quizHandler.PauseQuiz).Methods("POST")
+	api.HandleFunc("/resume", quizHandler.ResumeQuiz).Methods("POST")
 	api.HandleFunc("/results", quizHandler.GetResults).Methods("GET")
 	api.HandleFunc("/stats", quizHandler.GetStats).Methods("GET")
 
+	study := r.PathPrefix("/study").Subrouter()
+	study.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
+	study.HandleFunc("/next", studyHandler.NextCard).Methods("GET")
+	study.HandleFunc("/review", studyHandler.Review).Methods("POST")
+
+	daily := r.PathPrefix("/daily").Subrouter()
+	daily.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
+	daily.HandleFunc("", dailyHandler.GetDaily).Methods("GET")
+	daily.HandleFunc("/history", dailyHandler.GetDailyHistory).Methods("GET")
+
+	rooms := r.PathPrefix("/rooms").Subrouter()
+	rooms.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
+	rooms.HandleFunc("", roomHandler.CreateRoom).Methods("POST")
+	rooms.HandleFunc("/{code}", roomHandler.GetRoom).Methods("GET")
+	rooms.HandleFunc("/{code}/ws", roomHandler.Connect).Methods("GET")
+
+	duels := r.PathPrefix("/duels").Subrouter()
+	duels.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
+	duels.HandleFunc("", duelHandler.Challenge).Methods("POST")
+	duels.HandleFunc("", duelHandler.ListDuels).Methods("GET")
+	duels.HandleFunc("/{id}", duelHandler.GetDuel).Methods("GET")
+	duels.HandleFunc("/{id}/accept", duelHandler.AcceptDuel).Methods("POST")
+	duels.HandleFunc("/{id}/decline", duelHandler.DeclineDuel).Methods("POST")
+
+	teams := r.PathPrefix("/teams").Subrouter()
+	teams.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
+	teams.HandleFunc("", teamHandler.ListTeams).Methods("GET")
+	teams.HandleFunc("/{id}", teamHandler.GetTeam).Methods("GET")
+	teams.HandleFunc("/{id}/join", teamHandler.JoinTeam).Methods("POST")
+	teams.HandleFunc("/{id}/leave", teamHandler.LeaveTeam).Methods("POST")
+
+	tournaments := r.PathPrefix("/tournaments").Subrouter()
+	tournaments.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopePlay))
+	tournaments.HandleFunc("", tournamentHandler.ListTournaments).Methods("GET")
+	tournaments.HandleFunc("/{id}", tournamentHandler.GetTournament).Methods("GET")
+	tournaments.HandleFunc("/{id}/bracket", tournamentHandler.GetBracket).Methods("GET")
+	tournaments.HandleFunc("/{id}/register", tournamentHandler.RegisterPlayer).Methods("POST")
+
+	leaderboards := r.PathPrefix("/leaderboard").Subrouter()
+	leaderboards.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopeRead))
+	leaderboards.HandleFunc("", leaderboardHandler.GetLeaderboard).Methods("GET")
+	leaderboards.HandleFunc("/teams", teamHandler.GetTeamLeaderboard).Methods("GET")
+
+	me := r.PathPrefix("/me").Subrouter()
+	me.Use(middleware.AuthMiddleware, middleware.RequireScope(models.ScopeRead))
+	me.HandleFunc("/progress", progressHandler.GetProgress).Methods("GET")
+	me.HandleFunc("/achievements", achievementHandler.GetAchievements).Methods("GET")
+	me.HandleFunc("/sessions", authHandler.ListSessions).Methods("GET")
+	me.Handle("/password", middleware.RejectAPIKeys(http.HandlerFunc(authHandler.ChangePassword))).Methods("POST")
+	me.Handle("/email", middleware.RejectAPIKeys(http.HandlerFunc(accountHandler.SetEmail))).Methods("PUT")
+	me.Handle("/api-keys", middleware.RejectAPIKeys(http.HandlerFunc(apiKeyHandler.CreateAPIKey))).Methods("POST")
+	me.Handle("/api-keys", middleware.RejectAPIKeys(http.HandlerFunc(apiKeyHandler.ListAPIKeys))).Methods("GET")
+	me.Handle("/api-keys/{id}", middleware.RejectAPIKeys(http.HandlerFunc(apiKeyHandler.RevokeAPIKey))).Methods("DELETE")
+	me.Handle("/2fa", middleware.RejectAPIKeys(http.HandlerFunc(twoFactorHandler.GetTwoFactorStatus))).Methods("GET")
+	me.Handle("/2fa/enroll", middleware.RejectAPIKeys(http.HandlerFunc(twoFactorHandler.EnrollTwoFactor))).Methods("POST")
+	me.Handle("/2fa/verify", middleware.RejectAPIKeys(http.HandlerFunc(twoFactorHandler.VerifyTwoFactor))).Methods("POST")
+	me.Handle("/2fa/recovery-codes", middleware.RejectAPIKeys(http.HandlerFunc(twoFactorHandler.RegenerateRecoveryCodes))).Methods("POST")
+	me.Handle("/2fa/disable", middleware.RejectAPIKeys(http.HandlerFunc(twoFactorHandler.DisableTwoFactor))).Methods("POST")
+
+	// Registered before /admin so that the analytics get their own scope
+	analytics := r.PathPrefix("/admin/analytics").Subrouter()
+	analytics.Use(middleware.AuthMiddleware, middleware.RequireRole(authService.DB, models.RoleAdmin), middleware.RequireScope(models.ScopeAnalytics))
+	if twoFactorService.RequireForAdmins {
+		analytics.Use(middleware.RequireTwoFactor(authService.DB))
+	}
+	analytics.HandleFunc("/questions", analyticsHandler.QuestionAnalytics).Methods("GET")
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AuthMiddleware, middleware.RequireRole(authService.DB, models.RoleAdmin), middleware.RequireScope(models.ScopeAdmin))
+	if twoFactorService.RequireForAdmins {
+		admin.Use(middleware.RequireTwoFactor(authService.DB))
+	}
+	admin.HandleFunc("/users/{username}/sessions", authHandler.RevokeUserSessions).Methods("DELETE")
+	admin.HandleFunc("/users/{username}/lockout", authHandler.UnlockUser).Methods("DELETE")
+	admin.HandleFunc("/audit", authHandler.ListAuditEvents).Methods("GET")
+	admin.HandleFunc("/teams", teamHandler.CreateTeam).Methods("POST")
+	admin.HandleFunc("/teams/{id}", teamHandler.DeleteTeam).Methods("DELETE")
+	admin.HandleFunc("/teams/{id}/members/{username}", teamHandler.AddMember).Methods("PUT")
+	admin.HandleFunc("/teams/{id}/members/{username}", teamHandler.RemoveMember).Methods("DELETE")
+	admin.HandleFunc("/tournaments", tournamentHandler.CreateTournament).Methods("POST")
+	admin.HandleFunc("/tournaments/{id}/start", tournamentHandler.StartTournament).Methods("POST")
+
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	inMemoryDB := make(map[string]string)